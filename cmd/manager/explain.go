@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/openshift/cluster-autoscaler-operator/pkg/operator"
+)
+
+// runExplain writes the cluster-autoscaler arguments rendered for a
+// ClusterAutoscaler manifest to out, with the source of each argument and
+// the fields without effect.
+func runExplain(args []string, out io.Writer) error {
+	config, err := operator.ConfigFromEnvironment()
+	if err != nil {
+		return fmt.Errorf("failed to get config from environment: %v", err)
+	}
+
+	flags := flag.NewFlagSet("explain", flag.ContinueOnError)
+	input := addInputFlags(flags, config)
+	output := flags.String("o", "text", "The output format, text or json.")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	ca, err := input.load(config)
+	if err != nil {
+		return err
+	}
+
+	explanation, err := operator.Explain(config, ca)
+	if err != nil {
+		return err
+	}
+
+	switch *output {
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(explanation)
+	case "text":
+	default:
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(w, "ARGUMENT\tSOURCE")
+	for _, arg := range explanation.Args {
+		fmt.Fprintf(w, "%s\t%s\n", arg.Arg, arg.Source)
+	}
+
+	if len(explanation.Ignored) > 0 {
+		fmt.Fprintln(w, "\nIGNORED FIELD\tREASON")
+		for _, field := range explanation.Ignored {
+			fmt.Fprintf(w, "%s\t%s\n", field.Field, field.Reason)
+		}
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/openshift/cluster-autoscaler-operator/pkg/operator"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// runImport writes the ClusterAutoscaler and MachineAutoscalers
+// corresponding to an existing cluster-autoscaler command line to out.  The
+// arguments are read from a Deployment manifest, or given after the flags.
+// Arguments which could not be imported are listed in a leading comment.
+func runImport(args []string, out io.Writer) error {
+	config, err := operator.ConfigFromEnvironment()
+	if err != nil {
+		return fmt.Errorf("failed to get config from environment: %v", err)
+	}
+
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	filename := flags.String("f", "", "The cluster-autoscaler Deployment manifest to import.")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	autoscalerArgs := flags.Args()
+
+	if *filename != "" {
+		data, err := os.ReadFile(*filename)
+		if err != nil {
+			return err
+		}
+
+		deploymentArgs, err := operator.DeploymentArgs(data)
+		if err != nil {
+			return err
+		}
+
+		autoscalerArgs = append(deploymentArgs, autoscalerArgs...)
+	}
+
+	if len(autoscalerArgs) == 0 {
+		return fmt.Errorf("a Deployment manifest must be given with -f, or arguments after --")
+	}
+
+	imported, err := operator.ImportArgs(config, autoscalerArgs)
+	if err != nil {
+		return err
+	}
+
+	if len(imported.Unrepresented) > 0 {
+		fmt.Fprintln(out, "# The following arguments could not be imported:")
+		for _, arg := range imported.Unrepresented {
+			fmt.Fprintf(out, "#   %s: %s\n", arg.Arg, arg.Reason)
+		}
+	}
+
+	objs := []client.Object{imported.ClusterAutoscaler}
+	for _, ma := range imported.MachineAutoscalers {
+		objs = append(objs, ma)
+	}
+
+	manifests, err := operator.RenderManifests(objs)
+	if err != nil {
+		return err
+	}
+
+	_, err = out.Write(manifests)
+	return err
+}
@@ -3,6 +3,9 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
+	"io"
+	"os"
 	"runtime"
 
 	"github.com/openshift/cluster-autoscaler-operator/pkg/operator"
@@ -13,6 +16,14 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
 )
 
+// subcommands are the commands run instead of the operator, working
+// without a cluster.
+var subcommands = map[string]func(args []string, out io.Writer) error{
+	"render":  runRender,
+	"explain": runExplain,
+	"import":  runImport,
+}
+
 func printVersion() {
 	klog.Infof("Go Version: %s", runtime.Version())
 	klog.Infof("Go OS/Arch: %s/%s", runtime.GOOS, runtime.GOARCH)
@@ -20,6 +31,17 @@ func printVersion() {
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			if err := run(os.Args[2:], os.Stdout); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[1], err)
+				os.Exit(1)
+			}
+
+			return
+		}
+	}
+
 	klog.InitFlags(nil)
 	flag.Set("logtostderr", "true")
 	flag.Set("alsologtostderr", "true")
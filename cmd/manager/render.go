@@ -0,0 +1,106 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	"github.com/openshift/cluster-autoscaler-operator/pkg/operator"
+)
+
+// inputFlags are the flags of the subcommands working on a ClusterAutoscaler
+// manifest without a cluster.
+type inputFlags struct {
+	filename     *string
+	platform     *string
+	featureGates *string
+}
+
+// addInputFlags adds the input flags to the given flag set, with defaults
+// from the given operator configuration.
+func addInputFlags(flags *flag.FlagSet, config *operator.Config) *inputFlags {
+	return &inputFlags{
+		filename:     flags.String("f", "", "The ClusterAutoscaler manifest to read."),
+		platform:     flags.String("platform", config.PlatformType, "The platform type of the cluster, e.g. AWS."),
+		featureGates: flags.String("feature-gates", "", "The enabled feature gates, in the form Name=true,Other=false."),
+	}
+}
+
+// load applies the platform type and feature gates to the given operator
+// configuration, and reads the ClusterAutoscaler manifest.
+func (f *inputFlags) load(config *operator.Config) (*autoscalingv1.ClusterAutoscaler, error) {
+	if *f.filename == "" {
+		return nil, fmt.Errorf("a ClusterAutoscaler manifest must be given with -f")
+	}
+
+	config.PlatformType = *f.platform
+
+	if *f.featureGates != "" {
+		gates, err := operator.ParseFeatureGates(*f.featureGates)
+		if err != nil {
+			return nil, err
+		}
+
+		config.FeatureGates = gates
+	}
+
+	data, err := os.ReadFile(*f.filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return operator.DecodeClusterAutoscaler(data)
+}
+
+// runRender renders the operand manifests for a ClusterAutoscaler manifest
+// without a cluster, and writes them, or a diff against a previous render,
+// to out.
+func runRender(args []string, out io.Writer) error {
+	config, err := operator.ConfigFromEnvironment()
+	if err != nil {
+		return fmt.Errorf("failed to get config from environment: %v", err)
+	}
+
+	flags := flag.NewFlagSet("render", flag.ContinueOnError)
+	input := addInputFlags(flags, config)
+	diff := flags.String("diff", "", "A previous render to diff the manifests against.")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	ca, err := input.load(config)
+	if err != nil {
+		return err
+	}
+
+	objs, err := operator.Render(config, ca)
+	if err != nil {
+		return err
+	}
+
+	manifests, err := operator.RenderManifests(objs)
+	if err != nil {
+		return err
+	}
+
+	if *diff == "" {
+		_, err := out.Write(manifests)
+		return err
+	}
+
+	previous, err := os.ReadFile(*diff)
+	if err != nil {
+		return err
+	}
+
+	d, err := operator.DiffManifests(previous, *diff, manifests)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(out, d)
+	return err
+}
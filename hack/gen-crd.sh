@@ -14,6 +14,28 @@ function annotate_crd() {
   sed -e "${script1}" -e "${script2}" "${input}" > "${output}"
 }
 
+# The ClusterAutoscaler and MachineAutoscaler versions are converted by the
+# operator's webhook server, with the CA bundle injected by the
+# service-ca-operator.
+function add_conversion_webhook() {
+  script1='/^  annotations:/a\
+\ \ \ \ service.beta.openshift.io/inject-cabundle: "true"'
+  script2='/^spec:/a\
+\ \ conversion:\
+\ \ \ \ strategy: Webhook\
+\ \ \ \ webhook:\
+\ \ \ \ \ \ clientConfig:\
+\ \ \ \ \ \ \ \ service:\
+\ \ \ \ \ \ \ \ \ \ name: cluster-autoscaler-operator\
+\ \ \ \ \ \ \ \ \ \ namespace: openshift-machine-api\
+\ \ \ \ \ \ \ \ \ \ path: /convert\
+\ \ \ \ \ \ \ \ \ \ port: 443\
+\ \ \ \ \ \ conversionReviewVersions:\
+\ \ \ \ \ \ - v1'
+  file="${1}"
+  sed -i -e "${script1}" -e "${script2}" "${file}"
+}
+
 # TODO elmiko, remove this function once ProvisioningRequest is no longer behind a feature gate
 function annotate_provreq_crd() {
   script1='/^  annotations:/a\
@@ -34,7 +56,9 @@ go run ./vendor/sigs.k8s.io/controller-tools/cmd/controller-gen crd:crdVersions=
 
 echo "Copying generated CRDs"
 annotate_crd config/crd/autoscaling.openshift.io_clusterautoscalers.yaml install/01_clusterautoscaler.crd.yaml
+add_conversion_webhook install/01_clusterautoscaler.crd.yaml
 annotate_crd config/crd/autoscaling.openshift.io_machineautoscalers.yaml install/02_machineautoscaler.crd.yaml
+add_conversion_webhook install/02_machineautoscaler.crd.yaml
 # TODO elmiko, change this to annotate_crd once ProvisioningRequest is no longer behind a feature gate
 annotate_provreq_crd config/crd/autoscaling.x-k8s.io_provisioningrequests.yaml install/11_provisioningrequest.crd.yaml
 rm -rf ./config/crd
@@ -0,0 +1,413 @@
+package v1
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+
+	autoscalingv2 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// ConversionDataAnnotation holds the spec of a ClusterAutoscaler in the
+// version it was converted from, when it could not be represented as is in
+// the version it was converted to, e.g. a duration of "60s", which is "1m0s"
+// once parsed, or a memory range which is not a whole number of GiB.  Fields
+// which have not been modified since are restored from it when converting
+// back, so that conversions are lossless.
+const ConversionDataAnnotation = "autoscaling.openshift.io/conversion-data"
+
+// gibibyte is the unit of the v1 memory limits.
+const gibibyte = 1 << 30
+
+// ConvertTo converts this ClusterAutoscaler to the hub (v2) version.
+func (src *ClusterAutoscaler) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*autoscalingv2.ClusterAutoscaler)
+
+	restored := &autoscalingv2.ClusterAutoscalerSpec{}
+	getConversionData(src, restored)
+
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+	dst.Spec = convertSpecToHub(src.Spec.DeepCopy(), restored)
+	dst.Status = autoscalingv2.ClusterAutoscalerStatus{
+		Conditions: src.Status.DeepCopy().Conditions,
+	}
+
+	return setConversionData(dst, src.Spec, convertSpecFromHub(dst.Spec.DeepCopy(), &ClusterAutoscalerSpec{}))
+}
+
+// ConvertFrom converts from the hub (v2) version to this version.
+func (dst *ClusterAutoscaler) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*autoscalingv2.ClusterAutoscaler)
+
+	restored := &ClusterAutoscalerSpec{}
+	getConversionData(src, restored)
+
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+	dst.Spec = convertSpecFromHub(src.Spec.DeepCopy(), restored)
+	dst.Status = ClusterAutoscalerStatus{
+		Conditions: src.Status.DeepCopy().Conditions,
+	}
+
+	return setConversionData(dst, src.Spec, convertSpecToHub(dst.Spec.DeepCopy(), &autoscalingv2.ClusterAutoscalerSpec{}))
+}
+
+// OriginalSpec returns the v1 spec the given hub ClusterAutoscaler was
+// converted from, as held by its conversion data annotation, or nil if the
+// conversion was lossless, in which case the hub spec converts back to it.
+func OriginalSpec(hub *autoscalingv2.ClusterAutoscaler) *ClusterAutoscalerSpec {
+	if _, ok := hub.GetAnnotations()[ConversionDataAnnotation]; !ok {
+		return nil
+	}
+
+	spec := &ClusterAutoscalerSpec{}
+	getConversionData(hub, spec)
+
+	return spec
+}
+
+// SetOriginalSpec records the given v1 spec as the one the given hub
+// ClusterAutoscaler was converted from, for a hub version built otherwise,
+// so that its fields are kept as written, e.g. durations, when converting it
+// to v1 or rendering it.  The spec may hold only some of the fields.
+func SetOriginalSpec(hub *autoscalingv2.ClusterAutoscaler, spec *ClusterAutoscalerSpec) error {
+	return setConversionData(hub, *spec, convertSpecFromHub(hub.Spec.DeepCopy(), &ClusterAutoscalerSpec{}))
+}
+
+// getConversionData decodes the spec held by the conversion data annotation
+// of the given object into spec.  A missing or malformed annotation leaves
+// spec empty, in which case nothing is restored.
+func getConversionData(obj metav1.Object, spec interface{}) {
+	if data, ok := obj.GetAnnotations()[ConversionDataAnnotation]; ok {
+		_ = json.Unmarshal([]byte(data), spec)
+	}
+}
+
+// setConversionData sets the conversion data annotation of the given
+// converted object to the original spec, unless the spec converted back
+// from the object is the same.
+func setConversionData(obj metav1.Object, original, roundTripped interface{}) error {
+	annotations := obj.GetAnnotations()
+	delete(annotations, ConversionDataAnnotation)
+
+	if !equality.Semantic.DeepEqual(original, roundTripped) {
+		data, err := json.Marshal(original)
+		if err != nil {
+			return err
+		}
+
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+
+		annotations[ConversionDataAnnotation] = string(data)
+	}
+
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+
+	obj.SetAnnotations(annotations)
+
+	return nil
+}
+
+// restoreOr returns restored if converting it back yields in, i.e. in was
+// itself converted from restored, and otherwise the conversion of in.
+func restoreOr[T, U any](in T, restored U, convert func(T) U, convertBack func(U) T) U {
+	if equality.Semantic.DeepEqual(convertBack(restored), in) {
+		return restored
+	}
+
+	return convert(in)
+}
+
+// convertSpecToHub converts a v1 spec to v2, restoring the fields of the
+// given v2 spec which it was converted from.  The input is not copied.
+func convertSpecToHub(in *ClusterAutoscalerSpec, restored *autoscalingv2.ClusterAutoscalerSpec) autoscalingv2.ClusterAutoscalerSpec {
+	out := autoscalingv2.ClusterAutoscalerSpec{
+		MaxPodGracePeriod:           in.MaxPodGracePeriod,
+		PodPriorityThreshold:        in.PodPriorityThreshold,
+		BalanceSimilarNodeGroups:    in.BalanceSimilarNodeGroups,
+		BalancingIgnoredLabels:      in.BalancingIgnoredLabels,
+		IgnoreDaemonsetsUtilization: in.IgnoreDaemonsetsUtilization,
+		SkipNodesWithLocalStorage:   in.SkipNodesWithLocalStorage,
+		LogVerbosity:                in.LogVerbosity,
+		EnforceNodeGroupMinSize:     (*autoscalingv2.EnforceNodeGroupMinSizeMode)(in.EnforceNodeGroupMinSize),
+		StartupTaints:               in.StartupTaints,
+	}
+
+	out.MaxNodeProvisionTime = restoreOr(in.MaxNodeProvisionTime, restored.MaxNodeProvisionTime,
+		func(s string) *metav1.Duration { return toDuration(&s) },
+		func(d *metav1.Duration) string { return derefString(fromDuration(d)) })
+
+	if in.Expanders != nil {
+		out.Expanders = make([]autoscalingv2.ExpanderString, len(in.Expanders))
+		for i, e := range in.Expanders {
+			out.Expanders[i] = autoscalingv2.ExpanderString(e)
+		}
+	}
+
+	if in.ResourceLimits != nil {
+		rl := in.ResourceLimits
+		restoredRL := restored.ResourceLimits
+		if restoredRL == nil {
+			restoredRL = &autoscalingv2.ResourceLimits{}
+		}
+
+		out.ResourceLimits = &autoscalingv2.ResourceLimits{
+			MaxNodesTotal: rl.MaxNodesTotal,
+			Cores:         restoreOr(rl.Cores, restoredRL.Cores, coresToHub, coresFromHub),
+			Memory:        restoreOr(rl.Memory, restoredRL.Memory, memoryToHub, memoryFromHub),
+		}
+
+		if rl.GPUS != nil {
+			out.ResourceLimits.GPUS = make([]autoscalingv2.GPULimit, len(rl.GPUS))
+			for i, g := range rl.GPUS {
+				out.ResourceLimits.GPUS[i] = autoscalingv2.GPULimit(g)
+			}
+		}
+	}
+
+	if in.ScaleDown != nil {
+		sd := in.ScaleDown
+		restoredSD := restored.ScaleDown
+		if restoredSD == nil {
+			restoredSD = &autoscalingv2.ScaleDownConfig{}
+		}
+
+		out.ScaleDown = &autoscalingv2.ScaleDownConfig{
+			Enabled:                     sd.Enabled,
+			DelayAfterAdd:               restoreOr(sd.DelayAfterAdd, restoredSD.DelayAfterAdd, toDuration, fromDuration),
+			DelayAfterDelete:            restoreOr(sd.DelayAfterDelete, restoredSD.DelayAfterDelete, toDuration, fromDuration),
+			DelayAfterFailure:           restoreOr(sd.DelayAfterFailure, restoredSD.DelayAfterFailure, toDuration, fromDuration),
+			UnneededTime:                restoreOr(sd.UnneededTime, restoredSD.UnneededTime, toDuration, fromDuration),
+			UtilizationThreshold:        restoreOr(sd.UtilizationThreshold, restoredSD.UtilizationThreshold, toQuantity, fromQuantity),
+			CordonNodeBeforeTerminating: (*autoscalingv2.CordonNodeBeforeTerminatingMode)(sd.CordonNodeBeforeTerminating),
+		}
+	}
+
+	if in.ScaleUp != nil {
+		restoredSU := restored.ScaleUp
+		if restoredSU == nil {
+			restoredSU = &autoscalingv2.ScaleUpConfig{}
+		}
+
+		out.ScaleUp = &autoscalingv2.ScaleUpConfig{
+			NewPodScaleUpDelay: restoreOr(in.ScaleUp.NewPodScaleUpDelay, restoredSU.NewPodScaleUpDelay, toDuration, fromDuration),
+		}
+	}
+
+	if in.NetworkPolicy != nil {
+		out.NetworkPolicy = &autoscalingv2.NetworkPolicyConfig{
+			Mode:              autoscalingv2.NetworkPolicyMode(in.NetworkPolicy.Mode),
+			AdditionalEgress:  in.NetworkPolicy.AdditionalEgress,
+			AdditionalIngress: in.NetworkPolicy.AdditionalIngress,
+		}
+	}
+
+	return out
+}
+
+// convertSpecFromHub converts a v2 spec to v1, restoring the fields of the
+// given v1 spec which it was converted from.  The input is not copied.
+func convertSpecFromHub(in *autoscalingv2.ClusterAutoscalerSpec, restored *ClusterAutoscalerSpec) ClusterAutoscalerSpec {
+	out := ClusterAutoscalerSpec{
+		MaxPodGracePeriod:           in.MaxPodGracePeriod,
+		PodPriorityThreshold:        in.PodPriorityThreshold,
+		BalanceSimilarNodeGroups:    in.BalanceSimilarNodeGroups,
+		BalancingIgnoredLabels:      in.BalancingIgnoredLabels,
+		IgnoreDaemonsetsUtilization: in.IgnoreDaemonsetsUtilization,
+		SkipNodesWithLocalStorage:   in.SkipNodesWithLocalStorage,
+		LogVerbosity:                in.LogVerbosity,
+		EnforceNodeGroupMinSize:     (*EnforceNodeGroupMinSizeMode)(in.EnforceNodeGroupMinSize),
+		StartupTaints:               in.StartupTaints,
+	}
+
+	out.MaxNodeProvisionTime = restoreOr(in.MaxNodeProvisionTime, restored.MaxNodeProvisionTime,
+		func(d *metav1.Duration) string { return derefString(fromDuration(d)) },
+		func(s string) *metav1.Duration { return toDuration(&s) })
+
+	if in.Expanders != nil {
+		out.Expanders = make([]ExpanderString, len(in.Expanders))
+		for i, e := range in.Expanders {
+			out.Expanders[i] = ExpanderString(e)
+		}
+	}
+
+	if in.ResourceLimits != nil {
+		rl := in.ResourceLimits
+		restoredRL := restored.ResourceLimits
+		if restoredRL == nil {
+			restoredRL = &ResourceLimits{}
+		}
+
+		out.ResourceLimits = &ResourceLimits{
+			MaxNodesTotal: rl.MaxNodesTotal,
+			Cores:         restoreOr(rl.Cores, restoredRL.Cores, coresFromHub, coresToHub),
+			Memory:        restoreOr(rl.Memory, restoredRL.Memory, memoryFromHub, memoryToHub),
+		}
+
+		if rl.GPUS != nil {
+			out.ResourceLimits.GPUS = make([]GPULimit, len(rl.GPUS))
+			for i, g := range rl.GPUS {
+				out.ResourceLimits.GPUS[i] = GPULimit(g)
+			}
+		}
+	}
+
+	if in.ScaleDown != nil {
+		sd := in.ScaleDown
+		restoredSD := restored.ScaleDown
+		if restoredSD == nil {
+			restoredSD = &ScaleDownConfig{}
+		}
+
+		out.ScaleDown = &ScaleDownConfig{
+			Enabled:                     sd.Enabled,
+			DelayAfterAdd:               restoreOr(sd.DelayAfterAdd, restoredSD.DelayAfterAdd, fromDuration, toDuration),
+			DelayAfterDelete:            restoreOr(sd.DelayAfterDelete, restoredSD.DelayAfterDelete, fromDuration, toDuration),
+			DelayAfterFailure:           restoreOr(sd.DelayAfterFailure, restoredSD.DelayAfterFailure, fromDuration, toDuration),
+			UnneededTime:                restoreOr(sd.UnneededTime, restoredSD.UnneededTime, fromDuration, toDuration),
+			UtilizationThreshold:        restoreOr(sd.UtilizationThreshold, restoredSD.UtilizationThreshold, fromQuantity, toQuantity),
+			CordonNodeBeforeTerminating: (*CordonNodeBeforeTerminatingMode)(sd.CordonNodeBeforeTerminating),
+		}
+	}
+
+	if in.ScaleUp != nil {
+		restoredSU := restored.ScaleUp
+		if restoredSU == nil {
+			restoredSU = &ScaleUpConfig{}
+		}
+
+		out.ScaleUp = &ScaleUpConfig{
+			NewPodScaleUpDelay: restoreOr(in.ScaleUp.NewPodScaleUpDelay, restoredSU.NewPodScaleUpDelay, fromDuration, toDuration),
+		}
+	}
+
+	if in.NetworkPolicy != nil {
+		out.NetworkPolicy = &NetworkPolicyConfig{
+			Mode:              NetworkPolicyMode(in.NetworkPolicy.Mode),
+			AdditionalEgress:  in.NetworkPolicy.AdditionalEgress,
+			AdditionalIngress: in.NetworkPolicy.AdditionalIngress,
+		}
+	}
+
+	return out
+}
+
+// toDuration parses a v1 duration string.  Strings which are not valid
+// durations convert to nil.  These are rejected by the validating webhook
+// and by the CRD validation rules, and are only found in objects stored
+// before they were in place, which the reconciler refuses to render; the
+// original string is kept in the conversion data annotation.
+func toDuration(s *string) *metav1.Duration {
+	if s == nil || *s == "" {
+		return nil
+	}
+
+	d, err := time.ParseDuration(*s)
+	if err != nil {
+		return nil
+	}
+
+	return &metav1.Duration{Duration: d}
+}
+
+func fromDuration(d *metav1.Duration) *string {
+	if d == nil {
+		return nil
+	}
+
+	s := d.Duration.String()
+	return &s
+}
+
+// toQuantity parses a v1 decimal string, e.g. a utilization threshold.
+// Strings which are not valid quantities convert to nil, as for durations.
+func toQuantity(s *string) *resource.Quantity {
+	if s == nil || *s == "" {
+		return nil
+	}
+
+	q, err := resource.ParseQuantity(*s)
+	if err != nil {
+		return nil
+	}
+
+	return &q
+}
+
+func fromQuantity(q *resource.Quantity) *string {
+	if q == nil {
+		return nil
+	}
+
+	s := autoscalingv2.DecimalString(*q)
+	return &s
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
+
+func coresToHub(r *ResourceRange) *autoscalingv2.QuantityRange {
+	return quantityRange(r, 1, resource.DecimalSI)
+}
+
+func coresFromHub(r *autoscalingv2.QuantityRange) *ResourceRange {
+	return resourceRange(r, 1)
+}
+
+func memoryToHub(r *ResourceRange) *autoscalingv2.QuantityRange {
+	return quantityRange(r, gibibyte, resource.BinarySI)
+}
+
+func memoryFromHub(r *autoscalingv2.QuantityRange) *ResourceRange {
+	return resourceRange(r, gibibyte)
+}
+
+// quantityRange converts a v1 range in multiples of unit to quantities.
+func quantityRange(r *ResourceRange, unit int64, format resource.Format) *autoscalingv2.QuantityRange {
+	if r == nil {
+		return nil
+	}
+
+	return &autoscalingv2.QuantityRange{
+		Min: *resource.NewQuantity(int64(r.Min)*unit, format),
+		Max: *resource.NewQuantity(int64(r.Max)*unit, format),
+	}
+}
+
+// resourceRange converts a range of quantities to a v1 range in multiples of
+// unit, rounded to stay within the range.
+func resourceRange(r *autoscalingv2.QuantityRange, unit int64) *ResourceRange {
+	if r == nil {
+		return nil
+	}
+
+	min, max := r.Scaled(unit)
+
+	return &ResourceRange{
+		Min: clampInt32(min),
+		Max: clampInt32(max),
+	}
+}
+
+func clampInt32(n int64) int32 {
+	switch {
+	case n > math.MaxInt32:
+		return math.MaxInt32
+	case n < math.MinInt32:
+		return math.MinInt32
+	}
+
+	return int32(n)
+}
@@ -0,0 +1,210 @@
+package v1
+
+import (
+	"testing"
+	"time"
+
+	autoscalingv2 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func newClusterAutoscaler() *ClusterAutoscaler {
+	return &ClusterAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "default",
+			Labels:      map[string]string{"test": "label"},
+			Annotations: map[string]string{"test": "annotation"},
+		},
+		Spec: ClusterAutoscalerSpec{
+			MaxPodGracePeriod:    ptr.To[int32](60),
+			MaxNodeProvisionTime: "30m",
+			Expanders:            []ExpanderString{PriorityExpander, RandomExpander},
+			ResourceLimits: &ResourceLimits{
+				MaxNodesTotal: ptr.To[int32](24),
+				Cores:         &ResourceRange{Min: 8, Max: 128},
+				Memory:        &ResourceRange{Min: 4, Max: 256},
+				GPUS:          []GPULimit{{Type: "nvidia.com/gpu", Min: 0, Max: 16}},
+			},
+			ScaleDown: &ScaleDownConfig{
+				Enabled:                     true,
+				DelayAfterAdd:               ptr.To("60s"),
+				DelayAfterDelete:            ptr.To("10s"),
+				UnneededTime:                ptr.To("5m"),
+				UtilizationThreshold:        ptr.To("0.40"),
+				CordonNodeBeforeTerminating: ptr.To(CordonNodeBeforeTerminatingModeEnabled),
+			},
+			ScaleUp: &ScaleUpConfig{
+				NewPodScaleUpDelay: ptr.To("10s"),
+			},
+		},
+		Status: ClusterAutoscalerStatus{
+			Conditions: []metav1.Condition{
+				{
+					Type:   MonitoringAvailableCondition,
+					Status: metav1.ConditionTrue,
+					Reason: "AsExpected",
+				},
+			},
+		},
+	}
+}
+
+func TestClusterAutoscalerConvertTo(t *testing.T) {
+	hub := &autoscalingv2.ClusterAutoscaler{}
+	if err := newClusterAutoscaler().ConvertTo(hub); err != nil {
+		t.Fatalf("failed to convert to hub: %v", err)
+	}
+
+	if d := hub.Spec.ScaleDown.DelayAfterAdd; d == nil || d.Duration != time.Minute {
+		t.Errorf("expected delayAfterAdd of 1m, got %v", d)
+	}
+
+	if th := hub.Spec.ScaleDown.UtilizationThreshold; th == nil || !th.Equal(resource.MustParse("400m")) {
+		t.Errorf("expected utilizationThreshold of 0.40, got %v", th)
+	}
+
+	if m := hub.Spec.ResourceLimits.Memory; m == nil || !m.Max.Equal(resource.MustParse("256Gi")) {
+		t.Errorf("expected memory max of 256Gi, got %v", m)
+	}
+
+	if _, ok := hub.Annotations[ConversionDataAnnotation]; !ok {
+		t.Errorf("expected %s annotation for non-canonical values", ConversionDataAnnotation)
+	}
+}
+
+func TestClusterAutoscalerConversionRoundTrip(t *testing.T) {
+	testCases := []struct {
+		label string
+		ca    *ClusterAutoscaler
+	}{
+		{
+			label: "non-canonical values",
+			ca:    newClusterAutoscaler(),
+		},
+		{
+			label: "canonical values",
+			ca: func() *ClusterAutoscaler {
+				ca := newClusterAutoscaler()
+				ca.Spec.MaxNodeProvisionTime = "30m0s"
+				ca.Spec.ScaleDown.DelayAfterAdd = ptr.To("1m0s")
+				ca.Spec.ScaleDown.UnneededTime = ptr.To("5m0s")
+				return ca
+			}(),
+		},
+		{
+			label: "invalid values",
+			ca: func() *ClusterAutoscaler {
+				ca := newClusterAutoscaler()
+				ca.Spec.ScaleDown.DelayAfterAdd = ptr.To("soon")
+				return ca
+			}(),
+		},
+		{
+			label: "empty spec",
+			ca:    &ClusterAutoscaler{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			hub := &autoscalingv2.ClusterAutoscaler{}
+			if err := tc.ca.ConvertTo(hub); err != nil {
+				t.Fatalf("failed to convert to hub: %v", err)
+			}
+
+			spoke := &ClusterAutoscaler{}
+			if err := spoke.ConvertFrom(hub); err != nil {
+				t.Fatalf("failed to convert from hub: %v", err)
+			}
+
+			if !equality.Semantic.DeepEqual(tc.ca, spoke) {
+				t.Errorf("round trip mismatch:\nexpected: %+v\ngot: %+v", tc.ca, spoke)
+			}
+		})
+	}
+}
+
+func TestClusterAutoscalerHubRoundTrip(t *testing.T) {
+	hub := &autoscalingv2.ClusterAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: autoscalingv2.ClusterAutoscalerSpec{
+			MaxNodeProvisionTime: &metav1.Duration{Duration: 90 * time.Second},
+			ResourceLimits: &autoscalingv2.ResourceLimits{
+				Cores: &autoscalingv2.QuantityRange{
+					Min: resource.MustParse("500m"),
+					Max: resource.MustParse("64"),
+				},
+				Memory: &autoscalingv2.QuantityRange{
+					Min: resource.MustParse("1.5Gi"),
+					Max: resource.MustParse("64500Mi"),
+				},
+			},
+			ScaleDown: &autoscalingv2.ScaleDownConfig{
+				Enabled:              true,
+				DelayAfterAdd:        &metav1.Duration{Duration: 1500 * time.Millisecond},
+				UtilizationThreshold: ptr.To(resource.MustParse("550m")),
+			},
+		},
+	}
+
+	spoke := &ClusterAutoscaler{}
+	if err := spoke.ConvertFrom(hub); err != nil {
+		t.Fatalf("failed to convert from hub: %v", err)
+	}
+
+	if m := spoke.Spec.ResourceLimits.Memory; m.Min != 2 || m.Max != 62 {
+		t.Errorf("expected memory range 2:62, got %d:%d", m.Min, m.Max)
+	}
+
+	if c := spoke.Spec.ResourceLimits.Cores; c.Min != 1 || c.Max != 64 {
+		t.Errorf("expected cores range 1:64, got %d:%d", c.Min, c.Max)
+	}
+
+	if th := spoke.Spec.ScaleDown.UtilizationThreshold; th == nil || *th != "0.55" {
+		t.Errorf("expected utilizationThreshold of 0.55, got %v", th)
+	}
+
+	if _, ok := spoke.Annotations[ConversionDataAnnotation]; !ok {
+		t.Fatalf("expected %s annotation for precise quantities", ConversionDataAnnotation)
+	}
+
+	restored := &autoscalingv2.ClusterAutoscaler{}
+	if err := spoke.ConvertTo(restored); err != nil {
+		t.Fatalf("failed to convert to hub: %v", err)
+	}
+
+	if !equality.Semantic.DeepEqual(hub, restored) {
+		t.Errorf("round trip mismatch:\nexpected: %+v\ngot: %+v", hub, restored)
+	}
+}
+
+func TestClusterAutoscalerConversionModifiedFields(t *testing.T) {
+	hub := &autoscalingv2.ClusterAutoscaler{}
+	if err := newClusterAutoscaler().ConvertTo(hub); err != nil {
+		t.Fatalf("failed to convert to hub: %v", err)
+	}
+
+	// Fields modified in the hub version take precedence over the ones
+	// recorded in the annotation, the others are restored as they were.
+	hub.Spec.ScaleDown.UnneededTime = &metav1.Duration{Duration: 2 * time.Minute}
+
+	spoke := &ClusterAutoscaler{}
+	if err := spoke.ConvertFrom(hub); err != nil {
+		t.Fatalf("failed to convert from hub: %v", err)
+	}
+
+	if got := *spoke.Spec.ScaleDown.UnneededTime; got != "2m0s" {
+		t.Errorf("expected modified unneededTime of 2m0s, got %q", got)
+	}
+
+	if got := *spoke.Spec.ScaleDown.DelayAfterAdd; got != "60s" {
+		t.Errorf("expected restored delayAfterAdd of 60s, got %q", got)
+	}
+
+	if _, ok := spoke.Annotations[ConversionDataAnnotation]; ok {
+		t.Errorf("expected stale %s annotation to be removed", ConversionDataAnnotation)
+	}
+}
@@ -1,6 +1,7 @@
 package v1
 
 import (
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -39,6 +40,17 @@ const (
 	EnforceNodeGroupMinSizeModeDisabled EnforceNodeGroupMinSizeMode = "Disabled"
 )
 
+// NetworkPolicyMode represents whether the operator manages the NetworkPolicies
+// of the cluster-autoscaler.
+// +kubebuilder:validation:Enum=Managed;Unmanaged
+type NetworkPolicyMode string
+
+// These constants define the valid values for NetworkPolicyMode
+const (
+	NetworkPolicyModeManaged   NetworkPolicyMode = "Managed"
+	NetworkPolicyModeUnmanaged NetworkPolicyMode = "Unmanaged"
+)
+
 // ClusterAutoscalerSpec defines the desired state of ClusterAutoscaler
 type ClusterAutoscalerSpec struct {
 	// Constraints of autoscaling resources
@@ -106,6 +118,8 @@ type ClusterAutoscalerSpec struct {
 	//
 	// +listType=set
 	// +kubebuilder:validation:MaxItems=3
+	// +kubebuilder:validation:XValidation:rule="self.all(e, self.exists_one(f, f == e))",message="expanders must not contain duplicates"
+	// +kubebuilder:validation:XValidation:rule="self.filter(e, e == 'Priority').size() <= 1",message="the Priority expander can only be listed once"
 	// +optional
 	Expanders []ExpanderString `json:"expanders"`
 	// EnforceNodeGroupMinSize enables/disables the `--enforce-node-group-min-size` cluster-autoscaler feature flag.
@@ -123,18 +137,67 @@ type ClusterAutoscalerSpec struct {
 	// +listType=set
 	// +optional
 	StartupTaints []string `json:"startupTaints,omitempty"`
+
+	// NetworkPolicy configures the NetworkPolicies restricting the traffic of the
+	// cluster-autoscaler pods.
+	// +optional
+	NetworkPolicy *NetworkPolicyConfig `json:"networkPolicy,omitempty"`
+}
+
+type NetworkPolicyConfig struct {
+	// Mode determines whether the operator manages the NetworkPolicies of the
+	// cluster-autoscaler. When Unmanaged, the operator removes the policies it
+	// created and no longer restricts the traffic of the cluster-autoscaler pods.
+	// Defaults to Managed.
+	// +kubebuilder:default=Managed
+	// +optional
+	Mode NetworkPolicyMode `json:"mode,omitempty"`
+
+	// AdditionalEgress lists egress rules allowed in addition to the ones
+	// required by the cluster-autoscaler, e.g. to reach a cloud API through
+	// a peer not known to the operator.
+	// +listType=atomic
+	// +optional
+	AdditionalEgress []networkingv1.NetworkPolicyEgressRule `json:"additionalEgress,omitempty"`
+
+	// AdditionalIngress lists ingress rules allowed in addition to the ones
+	// required by the cluster-autoscaler.
+	// +listType=atomic
+	// +optional
+	AdditionalIngress []networkingv1.NetworkPolicyIngressRule `json:"additionalIngress,omitempty"`
 }
 
 // ClusterAutoscalerStatus defines the observed state of ClusterAutoscaler
 type ClusterAutoscalerStatus struct {
-	// TODO: Add status fields.
+	// Conditions represent the observations of the ClusterAutoscaler's
+	// current state.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+const (
+	// MonitoringAvailableCondition indicates whether the cluster-autoscaler
+	// ServiceMonitor and PrometheusRule are managed.  It is false when the
+	// prometheus-operator types are not served by the cluster.
+	MonitoringAvailableCondition = "MonitoringAvailable"
+
+	// MonitoringReconciledReason is the MonitoringAvailable reason when the
+	// monitoring resources have been created or updated.
+	MonitoringReconciledReason = "MonitoringResourcesReconciled"
+
+	// MonitoringTypesNotInstalledReason is the MonitoringAvailable reason when
+	// the prometheus-operator types are not served by the cluster.
+	MonitoringTypesNotInstalledReason = "MonitoringTypesNotInstalled"
+)
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // ClusterAutoscaler is the Schema for the clusterautoscalers API
 // +k8s:openapi-gen=true
 // +kubebuilder:resource:path=clusterautoscalers,shortName=ca,scope=Cluster
+// +kubebuilder:storageversion
 // +kubebuilder:subresource:status
 // +genclient:nonNamespaced
 type ClusterAutoscaler struct {
@@ -176,6 +239,7 @@ type ResourceLimits struct {
 	GPUS []GPULimit `json:"gpus,omitempty"`
 }
 
+// +kubebuilder:validation:XValidation:rule="self.max >= self.min",message="max must be greater than or equal to min"
 type GPULimit struct {
 	// The type of GPU to associate with the minimum and maximum limits.
 	// This value is used by the Cluster Autoscaler to identify Nodes that will have GPU capacity by searching
@@ -191,12 +255,18 @@ type GPULimit struct {
 	Max int32 `json:"max"`
 }
 
+// +kubebuilder:validation:XValidation:rule="self.max >= self.min",message="max must be greater than or equal to min"
 type ResourceRange struct {
 	// +kubebuilder:validation:Minimum=0
 	Min int32 `json:"min"`
 	Max int32 `json:"max"`
 }
 
+// +kubebuilder:validation:XValidation:rule="!has(self.delayAfterAdd) || duration(self.delayAfterAdd) >= duration('0s')",message="delayAfterAdd must be a non-negative duration"
+// +kubebuilder:validation:XValidation:rule="!has(self.delayAfterDelete) || duration(self.delayAfterDelete) >= duration('0s')",message="delayAfterDelete must be a non-negative duration"
+// +kubebuilder:validation:XValidation:rule="!has(self.delayAfterFailure) || duration(self.delayAfterFailure) >= duration('0s')",message="delayAfterFailure must be a non-negative duration"
+// +kubebuilder:validation:XValidation:rule="!has(self.unneededTime) || duration(self.unneededTime) >= duration('0s')",message="unneededTime must be a non-negative duration"
+// +kubebuilder:validation:XValidation:rule="!has(self.utilizationThreshold) || (double(self.utilizationThreshold) > 0.0 && double(self.utilizationThreshold) < 1.0)",message="utilizationThreshold must be a value between 0 and 1"
 type ScaleDownConfig struct {
 	// Should CA scale down the cluster
 	Enabled bool `json:"enabled"`
@@ -228,5 +298,6 @@ type ScaleDownConfig struct {
 type ScaleUpConfig struct {
 	// Scale up delay for new pods, if omitted defaults to 0 seconds
 	// +kubebuilder:validation:Pattern=([0-9]*(\.[0-9]*)?[a-z]+)+
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('0s')",message="newPodScaleUpDelay must be a non-negative duration"
 	NewPodScaleUpDelay *string `json:"newPodScaleUpDelay,omitempty"`
 }
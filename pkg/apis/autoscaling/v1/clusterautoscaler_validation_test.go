@@ -0,0 +1,212 @@
+package v1
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apiserver/pkg/cel/library"
+	"sigs.k8s.io/yaml"
+)
+
+const clusterAutoscalerCRD = "../../../../install/01_clusterautoscaler.crd.yaml"
+
+// crdSchema returns the schema of the given field path in the given version
+// of the ClusterAutoscaler CRD.
+func crdSchema(t *testing.T, version, path string) apiextensionsv1.JSONSchemaProps {
+	t.Helper()
+
+	data, err := os.ReadFile(clusterAutoscalerCRD)
+	if err != nil {
+		t.Fatalf("failed to read CRD: %v", err)
+	}
+
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := yaml.Unmarshal(data, crd); err != nil {
+		t.Fatalf("failed to decode CRD: %v", err)
+	}
+
+	for _, v := range crd.Spec.Versions {
+		if v.Name != version {
+			continue
+		}
+
+		schema := *v.Schema.OpenAPIV3Schema
+		for _, name := range strings.Split(path, ".") {
+			prop, ok := schema.Properties[name]
+			if !ok {
+				t.Fatalf("no field %q in %s schema of version %s", name, path, version)
+			}
+			schema = prop
+		}
+
+		return schema
+	}
+
+	t.Fatalf("no version %s in CRD", version)
+	return apiextensionsv1.JSONSchemaProps{}
+}
+
+// celErrors evaluates the CEL rules of the given schema against the given
+// value and returns the messages of the rules which do not hold.
+func celErrors(t *testing.T, schema apiextensionsv1.JSONSchemaProps, self interface{}) []string {
+	t.Helper()
+
+	env, err := cel.NewEnv(cel.Variable("self", cel.DynType), library.Lists(), library.Quantity())
+	if err != nil {
+		t.Fatalf("failed to create CEL environment: %v", err)
+	}
+
+	var messages []string
+	for _, rule := range schema.XValidations {
+		ast, issues := env.Compile(rule.Rule)
+		if issues.Err() != nil {
+			t.Fatalf("failed to compile rule %q: %v", rule.Rule, issues.Err())
+		}
+
+		prg, err := env.Program(ast)
+		if err != nil {
+			t.Fatalf("failed to create program for rule %q: %v", rule.Rule, err)
+		}
+
+		out, _, err := prg.Eval(map[string]interface{}{"self": self})
+		if err != nil {
+			t.Fatalf("failed to evaluate rule %q: %v", rule.Rule, err)
+		}
+
+		if out.Value() != true {
+			messages = append(messages, rule.Message)
+		}
+	}
+
+	return messages
+}
+
+func TestExpandersValidation(t *testing.T) {
+	testCases := []struct {
+		name      string
+		expanders []interface{}
+		expected  []string
+	}{
+		{
+			name:      "Single expander",
+			expanders: []interface{}{"Random"},
+		},
+		{
+			name:      "Multiple expanders",
+			expanders: []interface{}{"Priority", "LeastWaste", "Random"},
+		},
+		{
+			name:      "Duplicate expander",
+			expanders: []interface{}{"LeastWaste", "LeastWaste"},
+			expected:  []string{"expanders must not contain duplicates"},
+		},
+		{
+			name:      "Duplicate Priority expander",
+			expanders: []interface{}{"Priority", "Random", "Priority"},
+			expected: []string{
+				"expanders must not contain duplicates",
+				"the Priority expander can only be listed once",
+			},
+		},
+	}
+
+	for _, version := range []string{"v1", "v2"} {
+		schema := crdSchema(t, version, "spec.expanders")
+
+		for _, tc := range testCases {
+			t.Run(version+"/"+tc.name, func(t *testing.T) {
+				got := celErrors(t, schema, tc.expanders)
+				if strings.Join(got, ",") != strings.Join(tc.expected, ",") {
+					t.Errorf("got %v, want %v", got, tc.expected)
+				}
+			})
+		}
+	}
+}
+
+func TestQuantityRangeValidation(t *testing.T) {
+	testCases := []struct {
+		name     string
+		min      interface{}
+		max      interface{}
+		expected []string
+	}{
+		{
+			name: "Integer range",
+			min:  int64(8),
+			max:  int64(128),
+		},
+		{
+			name: "Quantity range",
+			min:  "4Gi",
+			max:  "256Gi",
+		},
+		{
+			name: "Equal bounds in different units",
+			min:  "1Gi",
+			max:  "1024Mi",
+		},
+		{
+			name:     "Max below min",
+			min:      "256Gi",
+			max:      "4Gi",
+			expected: []string{"max must be greater than or equal to min"},
+		},
+		{
+			name:     "Negative min",
+			min:      int64(-1),
+			max:      int64(8),
+			expected: []string{"min and max must not be negative"},
+		},
+		{
+			name: "Negative max",
+			min:  "0",
+			max:  "-500m",
+			expected: []string{
+				"min and max must not be negative",
+				"max must be greater than or equal to min",
+			},
+		},
+	}
+
+	for _, field := range []string{"cores", "memory"} {
+		schema := crdSchema(t, "v2", "spec.resourceLimits."+field)
+
+		for _, tc := range testCases {
+			t.Run(field+"/"+tc.name, func(t *testing.T) {
+				got := celErrors(t, schema, map[string]interface{}{"min": tc.min, "max": tc.max})
+				if strings.Join(got, ",") != strings.Join(tc.expected, ",") {
+					t.Errorf("got %v, want %v", got, tc.expected)
+				}
+			})
+		}
+	}
+}
+
+func TestUtilizationThresholdValidation(t *testing.T) {
+	testCases := []struct {
+		name      string
+		threshold interface{}
+		valid     bool
+	}{
+		{name: "Decimal", threshold: "0.5", valid: true},
+		{name: "Milli", threshold: "500m", valid: true},
+		{name: "Zero", threshold: int64(0), valid: false},
+		{name: "One", threshold: int64(1), valid: false},
+		{name: "Above one", threshold: "1500m", valid: false},
+	}
+
+	schema := crdSchema(t, "v2", "spec.scaleDown")
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := celErrors(t, schema, map[string]interface{}{"enabled": true, "utilizationThreshold": tc.threshold})
+			if (len(got) == 0) != tc.valid {
+				t.Errorf("got %v, want valid %v", got, tc.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func init() {
+	SchemeBuilder.Register(&MachineAutoscaler{}, &MachineAutoscalerList{})
+}
+
+// MachineAutoscalerSpec defines the desired state of MachineAutoscaler
+// +kubebuilder:validation:XValidation:rule="self.maxReplicas >= self.minReplicas",message="maxReplicas must be greater than or equal to minReplicas"
+type MachineAutoscalerSpec struct {
+	// MinReplicas constrains the minimal number of replicas of a scalable resource
+	// +kubebuilder:validation:Minimum=0
+	MinReplicas int32 `json:"minReplicas"`
+
+	// MaxReplicas constrains the maximal number of replicas of a scalable resource
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// ScaleTargetRef holds reference to a scalable resource
+	ScaleTargetRef ScaleTargetReference `json:"scaleTargetRef"`
+}
+
+// MachineAutoscalerStatus defines the observed state of MachineAutoscaler
+type MachineAutoscalerStatus struct {
+	// LastTargetRef holds reference to the recently observed scalable resource
+	LastTargetRef *ScaleTargetReference `json:"lastTargetRef,omitempty"`
+
+	// Conditions represent the observations of the MachineAutoscaler's
+	// current state.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+const (
+	// ReadyCondition indicates whether the most recent reconcile of the
+	// MachineAutoscaler updated its target.  The reason identifies the
+	// failure otherwise.
+	ReadyCondition = "Ready"
+
+	// TargetUpdatedReason is the Ready reason when the target has been
+	// updated.
+	TargetUpdatedReason = "TargetUpdated"
+
+	// InvalidReason is the Ready reason when the MachineAutoscaler failed
+	// validation.
+	InvalidReason = "Invalid"
+
+	// TargetNotFoundReason is the Ready reason when the target does not
+	// exist.
+	TargetNotFoundReason = "TargetNotFound"
+
+	// TargetErrorReason is the Ready reason when the target could not be
+	// fetched or claimed.
+	TargetErrorReason = "TargetError"
+
+	// TargetConflictReason is the Ready reason when the target is owned by
+	// another MachineAutoscaler.
+	TargetConflictReason = "TargetConflict"
+
+	// UpdateFailedReason is the Ready reason when the target could not be
+	// updated.
+	UpdateFailedReason = "UpdateFailed"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MachineAutoscaler is the Schema for the machineautoscalers API
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:path=machineautoscalers,shortName=ma,scope=Namespaced
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ref Kind",type="string",JSONPath=".spec.scaleTargetRef.kind",description="Kind of object scaled"
+// +kubebuilder:printcolumn:name="Ref Name",type="string",JSONPath=".spec.scaleTargetRef.name",description="Name of object scaled"
+// +kubebuilder:printcolumn:name="Min",type="integer",JSONPath=".spec.minReplicas",description="Min number of replicas"
+// +kubebuilder:printcolumn:name="Max",type="integer",JSONPath=".spec.maxReplicas",description="Max number of replicas"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="MachineAutoscaler resource age"
+type MachineAutoscaler struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Specification of constraints of a scalable resource
+	Spec MachineAutoscalerSpec `json:"spec,omitempty"`
+
+	// Most recently observed status of a scalable resource
+	Status MachineAutoscalerStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MachineAutoscalerList contains a list of MachineAutoscaler
+type MachineAutoscalerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MachineAutoscaler `json:"items"`
+}
+
+// ScaleTargetReference identifies the scalable resource targeted by a
+// MachineAutoscaler, e.g. a MachineSet, by API version, kind and name.
+type ScaleTargetReference struct {
+	// APIVersion is the API version of the scalable resource, e.g.
+	// machine.openshift.io/v1beta1.
+	APIVersion string `json:"apiVersion,omitempty"`
+
+	// Kind is the kind of the scalable resource, e.g. MachineSet.
+	// +kubebuilder:validation:MinLength=1
+	Kind string `json:"kind"`
+
+	// Name specifies a name of an object, e.g. worker-us-east-1a.
+	// Scalable resources are expected to exist under a single namespace.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// Hub marks MachineAutoscaler as the conversion hub.  This is also the
+// storage version, other versions are converted to and from it by the
+// operator's conversion webhook.
+func (*MachineAutoscaler) Hub() {}
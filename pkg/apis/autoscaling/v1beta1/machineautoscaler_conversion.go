@@ -0,0 +1,48 @@
+package v1beta1
+
+import (
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// ConvertTo converts this MachineAutoscaler to the hub (v1) version.
+func (src *MachineAutoscaler) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*autoscalingv1.MachineAutoscaler)
+
+	dst.ObjectMeta = src.ObjectMeta
+
+	dst.Spec.MinReplicas = src.Spec.MinReplicas
+	dst.Spec.MaxReplicas = src.Spec.MaxReplicas
+	dst.Spec.ScaleTargetRef = autoscalingv1.ScaleTargetReference(src.Spec.ScaleTargetRef)
+
+	dst.Status.LastTargetRef = nil
+	if src.Status.LastTargetRef != nil {
+		ref := autoscalingv1.ScaleTargetReference(*src.Status.LastTargetRef)
+		dst.Status.LastTargetRef = &ref
+	}
+
+	dst.Status.Conditions = src.Status.DeepCopy().Conditions
+
+	return nil
+}
+
+// ConvertFrom converts from the hub (v1) version to this version.
+func (dst *MachineAutoscaler) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*autoscalingv1.MachineAutoscaler)
+
+	dst.ObjectMeta = src.ObjectMeta
+
+	dst.Spec.MinReplicas = src.Spec.MinReplicas
+	dst.Spec.MaxReplicas = src.Spec.MaxReplicas
+	dst.Spec.ScaleTargetRef = CrossVersionObjectReference(src.Spec.ScaleTargetRef)
+
+	dst.Status.LastTargetRef = nil
+	if src.Status.LastTargetRef != nil {
+		ref := CrossVersionObjectReference(*src.Status.LastTargetRef)
+		dst.Status.LastTargetRef = &ref
+	}
+
+	dst.Status.Conditions = src.Status.DeepCopy().Conditions
+
+	return nil
+}
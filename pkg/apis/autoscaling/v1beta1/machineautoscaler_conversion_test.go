@@ -0,0 +1,167 @@
+package v1beta1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
+)
+
+func newMachineAutoscaler() *MachineAutoscaler {
+	return &MachineAutoscaler{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: SchemeGroupVersion.String(),
+			Kind:       "MachineAutoscaler",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test",
+			Namespace:   "test-namespace",
+			Labels:      map[string]string{"test": "label"},
+			Annotations: map[string]string{"test": "annotation"},
+			Finalizers:  []string{"test-finalizer"},
+		},
+		Spec: MachineAutoscalerSpec{
+			MinReplicas: 1,
+			MaxReplicas: 3,
+			ScaleTargetRef: CrossVersionObjectReference{
+				APIVersion: "machine.openshift.io/v1beta1",
+				Kind:       "MachineSet",
+				Name:       "worker",
+			},
+		},
+		Status: MachineAutoscalerStatus{
+			LastTargetRef: &CrossVersionObjectReference{
+				APIVersion: "machine.openshift.io/v1beta1",
+				Kind:       "MachineSet",
+				Name:       "previous-worker",
+			},
+			Conditions: []metav1.Condition{
+				{
+					Type:               "Ready",
+					Status:             metav1.ConditionTrue,
+					Reason:             "TargetUpdated",
+					LastTransitionTime: metav1.NewTime(time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC)),
+				},
+			},
+		},
+	}
+}
+
+func TestMachineAutoscalerConversionRoundTrip(t *testing.T) {
+	testCases := []struct {
+		label string
+		ma    *MachineAutoscaler
+	}{
+		{
+			label: "with last target",
+			ma:    newMachineAutoscaler(),
+		},
+		{
+			label: "without last target",
+			ma: func() *MachineAutoscaler {
+				ma := newMachineAutoscaler()
+				ma.Status.LastTargetRef = nil
+				return ma
+			}(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			hub := &autoscalingv1.MachineAutoscaler{}
+			if err := tc.ma.ConvertTo(hub); err != nil {
+				t.Fatalf("failed to convert to hub: %v", err)
+			}
+
+			if hub.Spec.ScaleTargetRef.Name != tc.ma.Spec.ScaleTargetRef.Name {
+				t.Errorf("expected target %q, got %q", tc.ma.Spec.ScaleTargetRef.Name, hub.Spec.ScaleTargetRef.Name)
+			}
+
+			spoke := &MachineAutoscaler{TypeMeta: tc.ma.TypeMeta}
+			if err := spoke.ConvertFrom(hub); err != nil {
+				t.Fatalf("failed to convert from hub: %v", err)
+			}
+
+			if !equality.Semantic.DeepEqual(tc.ma, spoke) {
+				t.Errorf("round trip mismatch:\nexpected: %+v\ngot: %+v", tc.ma, spoke)
+			}
+		})
+	}
+}
+
+func TestMachineAutoscalerConversionWebhook(t *testing.T) {
+	scheme := runtime.NewScheme()
+
+	if err := SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := autoscalingv1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := json.Marshal(newMachineAutoscaler())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	review := &apiextensionsv1.ConversionReview{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: apiextensionsv1.SchemeGroupVersion.String(),
+			Kind:       "ConversionReview",
+		},
+		Request: &apiextensionsv1.ConversionRequest{
+			UID:               types.UID("test"),
+			DesiredAPIVersion: autoscalingv1.SchemeGroupVersion.String(),
+			Objects:           []runtime.RawExtension{{Raw: raw}},
+		},
+	}
+
+	body, err := json.Marshal(review)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/convert", bytes.NewReader(body)).WithContext(context.TODO())
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	conversion.NewWebhookHandler(scheme, conversion.NewRegistry()).ServeHTTP(rec, req)
+
+	response := &apiextensionsv1.ConversionReview{}
+	if err := json.Unmarshal(rec.Body.Bytes(), response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if response.Response == nil || response.Response.Result.Status != metav1.StatusSuccess {
+		t.Fatalf("conversion failed: %+v", response.Response)
+	}
+
+	if len(response.Response.ConvertedObjects) != 1 {
+		t.Fatalf("expected 1 converted object, got %d", len(response.Response.ConvertedObjects))
+	}
+
+	converted := &autoscalingv1.MachineAutoscaler{}
+	if err := json.Unmarshal(response.Response.ConvertedObjects[0].Raw, converted); err != nil {
+		t.Fatalf("failed to decode converted object: %v", err)
+	}
+
+	if converted.APIVersion != autoscalingv1.SchemeGroupVersion.String() {
+		t.Errorf("expected apiVersion %q, got %q", autoscalingv1.SchemeGroupVersion.String(), converted.APIVersion)
+	}
+
+	if converted.Spec.MaxReplicas != 3 || converted.Spec.ScaleTargetRef.Kind != "MachineSet" {
+		t.Errorf("unexpected converted spec: %+v", converted.Spec)
+	}
+}
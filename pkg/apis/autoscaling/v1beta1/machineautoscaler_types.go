@@ -9,6 +9,7 @@ func init() {
 }
 
 // MachineAutoscalerSpec defines the desired state of MachineAutoscaler
+// +kubebuilder:validation:XValidation:rule="self.maxReplicas >= self.minReplicas",message="maxReplicas must be greater than or equal to minReplicas"
 type MachineAutoscalerSpec struct {
 	// MinReplicas constrains the minimal number of replicas of a scalable resource
 	// +kubebuilder:validation:Minimum=0
@@ -26,6 +27,13 @@ type MachineAutoscalerSpec struct {
 type MachineAutoscalerStatus struct {
 	// LastTargetRef holds reference to the recently observed scalable resource
 	LastTargetRef *CrossVersionObjectReference `json:"lastTargetRef,omitempty"`
+
+	// Conditions represent the observations of the MachineAutoscaler's
+	// current state.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
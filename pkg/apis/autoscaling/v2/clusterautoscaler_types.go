@@ -0,0 +1,350 @@
+package v2
+
+import (
+	"math/big"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func init() {
+	SchemeBuilder.Register(&ClusterAutoscaler{}, &ClusterAutoscalerList{})
+}
+
+// ExpanderString contains the name of an expander to be used by the cluster autoscaler.
+// +kubebuilder:validation:Enum=LeastWaste;Priority;Random
+type ExpanderString string
+
+// These constants define the valid values for an ExpanderString
+const (
+	LeastWasteExpander ExpanderString = "LeastWaste"
+	PriorityExpander   ExpanderString = "Priority"
+	RandomExpander     ExpanderString = "Random"
+)
+
+// CordonNodeBeforeTerminatingMode represents the mode for cordoning nodes before terminating.
+// +kubebuilder:validation:Enum=Enabled;Disabled
+type CordonNodeBeforeTerminatingMode string
+
+// These constants define the valid values for CordonNodeBeforeTerminatingMode
+const (
+	CordonNodeBeforeTerminatingModeEnabled  CordonNodeBeforeTerminatingMode = "Enabled"
+	CordonNodeBeforeTerminatingModeDisabled CordonNodeBeforeTerminatingMode = "Disabled"
+)
+
+// EnforceNodeGroupMinSizeMode represents the mode for enforcing node group minimum size.
+// +kubebuilder:validation:Enum=Enabled;Disabled
+type EnforceNodeGroupMinSizeMode string
+
+// These constants define the valid values for EnforceNodeGroupMinSizeMode
+const (
+	EnforceNodeGroupMinSizeModeEnabled  EnforceNodeGroupMinSizeMode = "Enabled"
+	EnforceNodeGroupMinSizeModeDisabled EnforceNodeGroupMinSizeMode = "Disabled"
+)
+
+// NetworkPolicyMode represents whether the operator manages the NetworkPolicies
+// of the cluster-autoscaler.
+// +kubebuilder:validation:Enum=Managed;Unmanaged
+type NetworkPolicyMode string
+
+// These constants define the valid values for NetworkPolicyMode
+const (
+	NetworkPolicyModeManaged   NetworkPolicyMode = "Managed"
+	NetworkPolicyModeUnmanaged NetworkPolicyMode = "Unmanaged"
+)
+
+// ClusterAutoscalerSpec defines the desired state of ClusterAutoscaler
+type ClusterAutoscalerSpec struct {
+	// Constraints of autoscaling resources
+	ResourceLimits *ResourceLimits `json:"resourceLimits,omitempty"`
+
+	// Configuration of scale down operation
+	ScaleDown *ScaleDownConfig `json:"scaleDown,omitempty"`
+
+	// Configuration of scale up operation
+	ScaleUp *ScaleUpConfig `json:"scaleUp,omitempty"`
+
+	// Gives pods graceful termination time before scaling down
+	MaxPodGracePeriod *int32 `json:"maxPodGracePeriod,omitempty"`
+
+	// Maximum time CA waits for node to be provisioned
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('0s')",message="maxNodeProvisionTime must be a non-negative duration"
+	MaxNodeProvisionTime *metav1.Duration `json:"maxNodeProvisionTime,omitempty"`
+
+	// To allow users to schedule "best-effort" pods, which shouldn't trigger
+	// Cluster Autoscaler actions, but only run when there are spare resources available,
+	// More info: https://github.com/kubernetes/autoscaler/blob/master/cluster-autoscaler/FAQ.md#how-does-cluster-autoscaler-work-with-pod-priority-and-preemption
+	PodPriorityThreshold *int32 `json:"podPriorityThreshold,omitempty"`
+
+	// BalanceSimilarNodeGroups enables/disables the
+	// `--balance-similar-node-groups` cluster-autoscaler feature.
+	// This feature will automatically identify node groups with
+	// the same instance type and the same set of labels and try
+	// to keep the respective sizes of those node groups balanced.
+	BalanceSimilarNodeGroups *bool `json:"balanceSimilarNodeGroups,omitempty"`
+
+	// BalancingIgnoredLabels sets "--balancing-ignore-label <label name>" flag on cluster-autoscaler for each listed label.
+	// This option specifies labels that cluster autoscaler should ignore when considering node group similarity.
+	// For example, if you have nodes with "topology.ebs.csi.aws.com/zone" label, you can add name of this label here
+	// to prevent cluster autoscaler from spliting nodes into different node groups based on its value.
+	BalancingIgnoredLabels []string `json:"balancingIgnoredLabels,omitempty"`
+
+	// Enables/Disables `--ignore-daemonsets-utilization` CA feature flag. Should CA ignore DaemonSet pods when calculating resource utilization for scaling down. false by default
+	IgnoreDaemonsetsUtilization *bool `json:"ignoreDaemonsetsUtilization,omitempty"`
+
+	// Enables/Disables `--skip-nodes-with-local-storage` CA feature flag. If true cluster autoscaler will never delete nodes with pods with local storage, e.g. EmptyDir or HostPath. true by default at autoscaler
+	SkipNodesWithLocalStorage *bool `json:"skipNodesWithLocalStorage,omitempty"`
+
+	// Sets the autoscaler log level.
+	// Default value is 1, level 4 is recommended for DEBUGGING and level 6 will enable almost everything.
+	//
+	// This option has priority over log level set by the `CLUSTER_AUTOSCALER_VERBOSITY` environment variable.
+	// +kubebuilder:validation:Minimum=0
+	LogVerbosity *int32 `json:"logVerbosity,omitempty"`
+
+	// Sets the type and order of expanders to be used during scale out operations.
+	// This option specifies an ordered list, highest priority first, of expanders that
+	// will be used by the cluster autoscaler to select node groups for expansion
+	// when scaling out.
+	// Expanders instruct the autoscaler on how to choose node groups when scaling out
+	// the cluster. They can be specified in order so that the result from the first expander
+	// is used as the input to the second, and so forth. For example, if set to `[LeastWaste, Random]`
+	// the autoscaler will first evaluate node groups to determine which will have the least
+	// resource waste, if multiple groups are selected the autoscaler will then randomly choose
+	// between those groups to determine the group for scaling.
+	// The following expanders are available:
+	// * LeastWaste - selects the node group that will have the least idle CPU (if tied, unused memory) after scale-up.
+	// * Priority - selects the node group that has the highest priority assigned by the user. For details, please see https://github.com/openshift/kubernetes-autoscaler/blob/master/cluster-autoscaler/expander/priority/readme.md
+	// * Random - selects the node group randomly.
+	// If not specified, the default value is `Random`, available options are: `LeastWaste`, `Priority`, `Random`.
+	//
+	// +listType=set
+	// +kubebuilder:validation:MaxItems=3
+	// +kubebuilder:validation:XValidation:rule="self.all(e, self.exists_one(f, f == e))",message="expanders must not contain duplicates"
+	// +kubebuilder:validation:XValidation:rule="self.filter(e, e == 'Priority').size() <= 1",message="the Priority expander can only be listed once"
+	// +optional
+	Expanders []ExpanderString `json:"expanders"`
+	// EnforceNodeGroupMinSize enables/disables the `--enforce-node-group-min-size` cluster-autoscaler feature flag.
+	// When enabled, the cluster autoscaler will enforce the minimum size of a node group,
+	// ensuring that the node group never scales below the configured minimum size even if
+	// nodes are deemed unneeded. This is useful for maintaining a baseline capacity in node groups.
+	// Defaults to Disabled.
+	// +optional
+	// +kubebuilder:default=Disabled
+	EnforceNodeGroupMinSize *EnforceNodeGroupMinSizeMode `json:"enforceNodeGroupMinSize,omitempty"`
+
+	// StartupTaints contains values that indicate the keys of taints that will be on a node that is still starting up.
+	// The cluster autoscaler treats nodes with these taints as unready during scale-up, expecting them to become ready shortly.
+	// Each taint must contain only the key.
+	// +listType=set
+	// +optional
+	StartupTaints []string `json:"startupTaints,omitempty"`
+
+	// NetworkPolicy configures the NetworkPolicies restricting the traffic of the
+	// cluster-autoscaler pods.
+	// +optional
+	NetworkPolicy *NetworkPolicyConfig `json:"networkPolicy,omitempty"`
+}
+
+type NetworkPolicyConfig struct {
+	// Mode determines whether the operator manages the NetworkPolicies of the
+	// cluster-autoscaler. When Unmanaged, the operator removes the policies it
+	// created and no longer restricts the traffic of the cluster-autoscaler pods.
+	// Defaults to Managed.
+	// +kubebuilder:default=Managed
+	// +optional
+	Mode NetworkPolicyMode `json:"mode,omitempty"`
+
+	// AdditionalEgress lists egress rules allowed in addition to the ones
+	// required by the cluster-autoscaler, e.g. to reach a cloud API through
+	// a peer not known to the operator.
+	// +listType=atomic
+	// +optional
+	AdditionalEgress []networkingv1.NetworkPolicyEgressRule `json:"additionalEgress,omitempty"`
+
+	// AdditionalIngress lists ingress rules allowed in addition to the ones
+	// required by the cluster-autoscaler.
+	// +listType=atomic
+	// +optional
+	AdditionalIngress []networkingv1.NetworkPolicyIngressRule `json:"additionalIngress,omitempty"`
+}
+
+// ClusterAutoscalerStatus defines the observed state of ClusterAutoscaler
+type ClusterAutoscalerStatus struct {
+	// Conditions represent the observations of the ClusterAutoscaler's
+	// current state.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+const (
+	// MonitoringAvailableCondition indicates whether the cluster-autoscaler
+	// ServiceMonitor and PrometheusRule are managed.  It is false when the
+	// prometheus-operator types are not served by the cluster.
+	MonitoringAvailableCondition = "MonitoringAvailable"
+
+	// MonitoringReconciledReason is the MonitoringAvailable reason when the
+	// monitoring resources have been created or updated.
+	MonitoringReconciledReason = "MonitoringResourcesReconciled"
+
+	// MonitoringTypesNotInstalledReason is the MonitoringAvailable reason when
+	// the prometheus-operator types are not served by the cluster.
+	MonitoringTypesNotInstalledReason = "MonitoringTypesNotInstalled"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterAutoscaler is the Schema for the clusterautoscalers API
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:path=clusterautoscalers,shortName=ca,scope=Cluster
+// +kubebuilder:subresource:status
+// +genclient:nonNamespaced
+type ClusterAutoscaler struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Desired state of ClusterAutoscaler resource
+	Spec ClusterAutoscalerSpec `json:"spec,omitempty"`
+
+	// Most recently observed status of ClusterAutoscaler resource
+	Status ClusterAutoscalerStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterAutoscalerList contains a list of ClusterAutoscaler
+type ClusterAutoscalerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterAutoscaler `json:"items"`
+}
+
+type ResourceLimits struct {
+	// Maximum number of nodes in all node groups.
+	// Cluster autoscaler will not grow the cluster beyond this number.
+	// +kubebuilder:validation:Minimum=0
+	MaxNodesTotal *int32 `json:"maxNodesTotal,omitempty"`
+
+	// Minimum and maximum number of cores in cluster.
+	// Cluster autoscaler will not scale the cluster beyond these numbers.
+	// Fractional values are rounded to whole cores within the range.
+	Cores *QuantityRange `json:"cores,omitempty"`
+
+	// Minimum and maximum amount of memory in cluster, e.g. 64Gi.
+	// Cluster autoscaler will not scale the cluster beyond these numbers.
+	// Values are rounded to whole GiB within the range.
+	Memory *QuantityRange `json:"memory,omitempty"`
+
+	// Minimum and maximum number of different GPUs in cluster, in the format <gpu_type>:<min>:<max>.
+	// Cluster autoscaler will not scale the cluster beyond these numbers. Can be passed multiple times.
+	GPUS []GPULimit `json:"gpus,omitempty"`
+}
+
+// +kubebuilder:validation:XValidation:rule="self.max >= self.min",message="max must be greater than or equal to min"
+type GPULimit struct {
+	// The type of GPU to associate with the minimum and maximum limits.
+	// This value is used by the Cluster Autoscaler to identify Nodes that will have GPU capacity by searching
+	// for it as a label value on the Node objects. For example, Nodes that carry the label key
+	// `cluster-api/accelerator` with the label value being the same as the Type field will be counted towards
+	// the resource limits by the Cluster Autoscaler.
+	// +kubebuilder:validation:MinLength=1
+	Type string `json:"type"`
+
+	// +kubebuilder:validation:Minimum=0
+	Min int32 `json:"min"`
+	// +kubebuilder:validation:Minimum=1
+	Max int32 `json:"max"`
+}
+
+// QuantityRange is a range of resource quantities.
+// +kubebuilder:validation:XValidation:rule="!quantity(string(self.min)).isLessThan(quantity('0')) && !quantity(string(self.max)).isLessThan(quantity('0'))",message="min and max must not be negative"
+// +kubebuilder:validation:XValidation:rule="!quantity(string(self.max)).isLessThan(quantity(string(self.min)))",message="max must be greater than or equal to min"
+type QuantityRange struct {
+	Min resource.Quantity `json:"min"`
+	Max resource.Quantity `json:"max"`
+}
+
+// Scaled returns the range in whole multiples of unit, rounding the minimum
+// up and the maximum down so that the result lies within the range.
+func (r QuantityRange) Scaled(unit int64) (min, max int64) {
+	return scaleQuantity(r.Min, unit, true), scaleQuantity(r.Max, unit, false)
+}
+
+// DecimalString returns the given quantity as a plain decimal number without
+// a suffix, e.g. "0.5" for 500m, as the cluster-autoscaler expects fractions.
+func DecimalString(q resource.Quantity) string {
+	s := q.AsDec().String()
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+
+	return s
+}
+
+// scaleQuantity returns the given quantity in multiples of unit, rounded up
+// or down.
+func scaleQuantity(q resource.Quantity, unit int64, roundUp bool) int64 {
+	// The quantity is unscaled * 10^-scale.
+	dec := q.AsDec()
+	scale := int64(dec.Scale())
+
+	num := new(big.Int).Set(dec.UnscaledBig())
+	den := big.NewInt(unit)
+
+	if scale >= 0 {
+		den.Mul(den, new(big.Int).Exp(big.NewInt(10), big.NewInt(scale), nil))
+	} else {
+		num.Mul(num, new(big.Int).Exp(big.NewInt(10), big.NewInt(-scale), nil))
+	}
+
+	quo, mod := new(big.Int).DivMod(num, den, new(big.Int))
+	if roundUp && mod.Sign() != 0 {
+		quo.Add(quo, big.NewInt(1))
+	}
+
+	return quo.Int64()
+}
+
+// +kubebuilder:validation:XValidation:rule="!has(self.delayAfterAdd) || duration(self.delayAfterAdd) >= duration('0s')",message="delayAfterAdd must be a non-negative duration"
+// +kubebuilder:validation:XValidation:rule="!has(self.delayAfterDelete) || duration(self.delayAfterDelete) >= duration('0s')",message="delayAfterDelete must be a non-negative duration"
+// +kubebuilder:validation:XValidation:rule="!has(self.delayAfterFailure) || duration(self.delayAfterFailure) >= duration('0s')",message="delayAfterFailure must be a non-negative duration"
+// +kubebuilder:validation:XValidation:rule="!has(self.unneededTime) || duration(self.unneededTime) >= duration('0s')",message="unneededTime must be a non-negative duration"
+// +kubebuilder:validation:XValidation:rule="!has(self.utilizationThreshold) || (quantity(string(self.utilizationThreshold)).isGreaterThan(quantity('0')) && quantity(string(self.utilizationThreshold)).isLessThan(quantity('1')))",message="utilizationThreshold must be a value between 0 and 1"
+type ScaleDownConfig struct {
+	// Should CA scale down the cluster
+	Enabled bool `json:"enabled"`
+
+	// How long after scale up that scale down evaluation resumes
+	DelayAfterAdd *metav1.Duration `json:"delayAfterAdd,omitempty"`
+
+	// How long after node deletion that scale down evaluation resumes, defaults to scan-interval
+	DelayAfterDelete *metav1.Duration `json:"delayAfterDelete,omitempty"`
+
+	// How long after scale down failure that scale down evaluation resumes
+	DelayAfterFailure *metav1.Duration `json:"delayAfterFailure,omitempty"`
+
+	// How long a node should be unneeded before it is eligible for scale down
+	UnneededTime *metav1.Duration `json:"unneededTime,omitempty"`
+
+	// Node utilization level, defined as sum of requested resources divided by capacity, below which a node can be considered for scale down.
+	// A quantity between 0 and 1, e.g. 0.5 or 500m.
+	UtilizationThreshold *resource.Quantity `json:"utilizationThreshold,omitempty"`
+
+	// CordonNodeBeforeTerminating enables/disables cordoning nodes before terminating during scale down.
+	CordonNodeBeforeTerminating *CordonNodeBeforeTerminatingMode `json:"cordonNodeBeforeTerminating,omitempty"`
+}
+
+type ScaleUpConfig struct {
+	// Scale up delay for new pods, if omitted defaults to 0 seconds
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('0s')",message="newPodScaleUpDelay must be a non-negative duration"
+	NewPodScaleUpDelay *metav1.Duration `json:"newPodScaleUpDelay,omitempty"`
+}
+
+// Hub marks ClusterAutoscaler as the conversion hub.  Other versions are
+// converted to and from it, and the cluster-autoscaler arguments are
+// rendered from it.
+func (*ClusterAutoscaler) Hub() {}
@@ -0,0 +1,4 @@
+// Package v2 contains API Schema definitions for the autoscaling v2 API group
+// +k8s:deepcopy-gen=package,register
+// +groupName=autoscaling.openshift.io
+package v2
@@ -4,10 +4,14 @@ import (
 	"fmt"
 	"slices"
 	"strings"
+	"time"
 
 	configv1 "github.com/openshift/api/config/v1"
 	v1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	v2 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v2"
 	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 )
 
@@ -27,6 +31,9 @@ const (
 	// ProvisioningRequest FeatureGate name
 	provisioningRequestFGName = "ProvisioningRequestAvailable"
 
+	// gibibyte is the unit of the cluster-autoscaler's memory limits.
+	gibibyte = 1 << 30
+
 	// Cluster API Machine Management FeatureGate names
 	clusterapiAWSFGName       = "ClusterAPIMachineManagementAWS"
 	clusterapiAzureFGName     = "ClusterAPIMachineManagementAzure"
@@ -55,12 +62,12 @@ func (a AutoscalerArg) Value(v interface{}) string {
 }
 
 // Range returns the argument with the given numerical range set.
-func (a AutoscalerArg) Range(min, max int) string {
+func (a AutoscalerArg) Range(min, max int64) string {
 	return fmt.Sprintf("%s=%d:%d", a.String(), min, max)
 }
 
 // TypeRange returns the argument with the given type and numerical range set.
-func (a AutoscalerArg) TypeRange(t string, min, max int) string {
+func (a AutoscalerArg) TypeRange(t string, min, max int64) string {
 	return fmt.Sprintf("%s=%s:%d:%d", a.String(), t, min, max)
 }
 
@@ -102,6 +109,7 @@ const (
 	KubeAPIContentType               AutoscalerArg = "--kube-api-content-type"
 	NodeGroupAutoDiscovery           AutoscalerArg = "--node-group-auto-discovery"
 	StartupTaint                     AutoscalerArg = "--startup-taint"
+	NodesArg                         AutoscalerArg = "--nodes"
 )
 
 // Constants for the command line expander flags
@@ -219,10 +227,17 @@ func appendBasicIgnoreLabels(args []string, cfg *Config) []string {
 
 // AutoscalerArgs returns a slice of strings representing command line arguments
 // to the cluster-autoscaler corresponding to the values in the given
-// ClusterAutoscaler resource.
-func AutoscalerArgs(ca *v1.ClusterAutoscaler, cfg *Config) []string {
+// ClusterAutoscaler resource, in the hub (v2) version.
+func AutoscalerArgs(ca *v2.ClusterAutoscaler, cfg *Config) []string {
 	s := &ca.Spec
 
+	// Durations are rendered as originally written in the v1 version, if
+	// the hub version was converted from it.
+	original := v1.OriginalSpec(ca)
+	if original == nil {
+		original = &v1.ClusterAutoscalerSpec{}
+	}
+
 	args := []string{
 		LogToStderrArg.String(),
 		RecordDuplicatedEventsArg.String(),
@@ -242,7 +257,7 @@ func AutoscalerArgs(ca *v1.ClusterAutoscaler, cfg *Config) []string {
 	}
 
 	// if feature gate for ProvisioningRequest is enabled, turn on the flag
-	if isFeatureGateEnabled(cfg.FeatureGateAccessor, provisioningRequestFGName) {
+	if ProvisioningRequestsEnabled(cfg.FeatureGateAccessor) {
 		args = append(args, EnableProvisioningRequestsArg.Value(trueFlag))
 	}
 
@@ -257,8 +272,8 @@ func AutoscalerArgs(ca *v1.ClusterAutoscaler, cfg *Config) []string {
 		args = append(args, v)
 	}
 
-	if ca.Spec.MaxNodeProvisionTime != "" {
-		v := MaxNodeProvisionTimeArg.Value(s.MaxNodeProvisionTime)
+	if ca.Spec.MaxNodeProvisionTime != nil {
+		v := MaxNodeProvisionTimeArg.Value(durationValue(s.MaxNodeProvisionTime, &original.MaxNodeProvisionTime))
 		args = append(args, v)
 	}
 
@@ -271,12 +286,15 @@ func AutoscalerArgs(ca *v1.ClusterAutoscaler, cfg *Config) []string {
 		args = append(args, ResourceArgs(s.ResourceLimits)...)
 	}
 
-	if ca.Spec.ScaleDown != nil {
-		args = append(args, ScaleDownArgs(s.ScaleDown)...)
+	// Fields set to the defaults of the mutating webhook are rendered as if
+	// unset, so defaulting an existing ClusterAutoscaler does not roll out
+	// the cluster-autoscaler.
+	if ca.Spec.ScaleDown != nil && !isDefaultScaleDown(s.ScaleDown) {
+		args = append(args, ScaleDownArgs(s.ScaleDown, original.ScaleDown)...)
 	}
 
 	if ca.Spec.ScaleUp != nil {
-		args = append(args, ScaleUpArgs(s.ScaleUp)...)
+		args = append(args, ScaleUpArgs(s.ScaleUp, original.ScaleUp)...)
 	}
 
 	if ca.Spec.BalanceSimilarNodeGroups != nil {
@@ -306,15 +324,15 @@ func AutoscalerArgs(ca *v1.ClusterAutoscaler, cfg *Config) []string {
 		args = append(args, VerbosityArg.Value(cfg.Verbosity))
 	}
 
-	if len(ca.Spec.Expanders) > 0 {
+	if len(ca.Spec.Expanders) > 0 && !isDefaultExpanders(s.Expanders) {
 		expanders := make([]string, 0)
 		for _, v := range ca.Spec.Expanders {
 			switch v {
-			case v1.LeastWasteExpander:
+			case v2.LeastWasteExpander:
 				expanders = append(expanders, leastWasteFlag)
-			case v1.PriorityExpander:
+			case v2.PriorityExpander:
 				expanders = append(expanders, priorityFlag)
-			case v1.RandomExpander:
+			case v2.RandomExpander:
 				expanders = append(expanders, randomFlag)
 			default:
 				// this shouldn't happen since we have validation on the API types, but just in case
@@ -327,53 +345,77 @@ func AutoscalerArgs(ca *v1.ClusterAutoscaler, cfg *Config) []string {
 
 	if ca.Spec.EnforceNodeGroupMinSize != nil {
 		switch *ca.Spec.EnforceNodeGroupMinSize {
-		case v1.EnforceNodeGroupMinSizeModeEnabled:
+		case v2.EnforceNodeGroupMinSizeModeEnabled:
 			args = append(args, EnforceNodeGroupMinSizeArg.Value(true))
-		case v1.EnforceNodeGroupMinSizeModeDisabled:
-			args = append(args, EnforceNodeGroupMinSizeArg.Value(false))
+		case v2.EnforceNodeGroupMinSizeModeDisabled:
+			// The cluster-autoscaler does not enforce the minimum size
+			// by default.
 		}
 	}
 
 	return args
 }
 
+// isDefaultScaleDown returns true if the given ScaleDownConfig only enables
+// scaling down, which is what the cluster-autoscaler does by default.
+func isDefaultScaleDown(sd *v2.ScaleDownConfig) bool {
+	return sd.Enabled &&
+		sd.DelayAfterAdd == nil &&
+		sd.DelayAfterDelete == nil &&
+		sd.DelayAfterFailure == nil &&
+		sd.UnneededTime == nil &&
+		sd.UtilizationThreshold == nil &&
+		sd.CordonNodeBeforeTerminating == nil
+}
+
+// isDefaultExpanders returns true if the given expanders are only the random
+// expander, which is what the cluster-autoscaler uses by default.
+func isDefaultExpanders(expanders []v2.ExpanderString) bool {
+	return len(expanders) == 1 && expanders[0] == v2.RandomExpander
+}
+
 // ScaleDownArgs returns a slice of strings representing command line arguments
 // to the cluster-autoscaler corresponding to the values in the given
-// ScaleDownConfig object.
-func ScaleDownArgs(sd *v1.ScaleDownConfig) []string {
+// ScaleDownConfig object.  Durations are rendered as given in the original
+// v1 ScaleDownConfig, if any and still of the same value.
+func ScaleDownArgs(sd *v2.ScaleDownConfig, original *v1.ScaleDownConfig) []string {
 	if !sd.Enabled {
 		return []string{ScaleDownEnabledArg.Value(false)}
 	}
 
+	if original == nil {
+		original = &v1.ScaleDownConfig{}
+	}
+
 	args := []string{
 		ScaleDownEnabledArg.Value(true),
 	}
 
 	if sd.DelayAfterAdd != nil {
-		args = append(args, ScaleDownDelayAfterAddArg.Value(*sd.DelayAfterAdd))
+		args = append(args, ScaleDownDelayAfterAddArg.Value(durationValue(sd.DelayAfterAdd, original.DelayAfterAdd)))
 	}
 
 	if sd.DelayAfterDelete != nil {
-		args = append(args, ScaleDownDelayAfterDeleteArg.Value(*sd.DelayAfterDelete))
+		args = append(args, ScaleDownDelayAfterDeleteArg.Value(durationValue(sd.DelayAfterDelete, original.DelayAfterDelete)))
 	}
 
 	if sd.DelayAfterFailure != nil {
-		args = append(args, ScaleDownDelayAfterFailureArg.Value(*sd.DelayAfterFailure))
+		args = append(args, ScaleDownDelayAfterFailureArg.Value(durationValue(sd.DelayAfterFailure, original.DelayAfterFailure)))
 	}
 
 	if sd.UnneededTime != nil {
-		args = append(args, ScaleDownUnneededTimeArg.Value(*sd.UnneededTime))
+		args = append(args, ScaleDownUnneededTimeArg.Value(durationValue(sd.UnneededTime, original.UnneededTime)))
 	}
 
 	if sd.UtilizationThreshold != nil {
-		args = append(args, ScaleDownUtilizationThresholdArg.Value(*sd.UtilizationThreshold))
+		args = append(args, ScaleDownUtilizationThresholdArg.Value(quantityValue(sd.UtilizationThreshold, original.UtilizationThreshold)))
 	}
 
 	if sd.CordonNodeBeforeTerminating != nil {
 		switch *sd.CordonNodeBeforeTerminating {
-		case v1.CordonNodeBeforeTerminatingModeEnabled:
+		case v2.CordonNodeBeforeTerminatingModeEnabled:
 			args = append(args, CordonNodeBeforeTerminatingArg.Value(true))
-		case v1.CordonNodeBeforeTerminatingModeDisabled:
+		case v2.CordonNodeBeforeTerminatingModeDisabled:
 			args = append(args, CordonNodeBeforeTerminatingArg.Value(false))
 		}
 	}
@@ -381,20 +423,53 @@ func ScaleDownArgs(sd *v1.ScaleDownConfig) []string {
 	return args
 }
 
-func ScaleUpArgs(su *v1.ScaleUpConfig) []string {
+// ScaleUpArgs returns a slice of strings representing command line arguments
+// to the cluster-autoscaler corresponding to the values in the given
+// ScaleUpConfig object, with durations rendered as for ScaleDownArgs.
+func ScaleUpArgs(su *v2.ScaleUpConfig, original *v1.ScaleUpConfig) []string {
 	args := []string{}
 
+	if original == nil {
+		original = &v1.ScaleUpConfig{}
+	}
+
 	if su.NewPodScaleUpDelay != nil {
-		args = append(args, NewPodScaleUpDelayArg.Value(*su.NewPodScaleUpDelay))
+		args = append(args, NewPodScaleUpDelayArg.Value(durationValue(su.NewPodScaleUpDelay, original.NewPodScaleUpDelay)))
 	}
 
 	return args
 }
 
+// durationValue returns the value of a duration argument: the original
+// string if it is the same duration, so that e.g. "10m" is not rendered as
+// "10m0s" and the arguments of existing deployments do not change, and
+// otherwise the canonical form of the duration.
+func durationValue(d *metav1.Duration, original *string) string {
+	if original != nil {
+		if o, err := time.ParseDuration(*original); err == nil && o == d.Duration {
+			return *original
+		}
+	}
+
+	return d.Duration.String()
+}
+
+// quantityValue returns the given quantity as a decimal argument value, or
+// as the original string if it is still of the same value.
+func quantityValue(q *resource.Quantity, original *string) string {
+	if original != nil {
+		if o, err := resource.ParseQuantity(*original); err == nil && o.Cmp(*q) == 0 {
+			return *original
+		}
+	}
+
+	return v2.DecimalString(*q)
+}
+
 // ResourceArgs returns a slice of strings representing command line arguments
 // to the cluster-autoscaler corresponding to the values in the given
 // ResourceLimits object.
-func ResourceArgs(rl *v1.ResourceLimits) []string {
+func ResourceArgs(rl *v2.ResourceLimits) []string {
 	args := []string{}
 
 	if rl.MaxNodesTotal != nil {
@@ -402,18 +477,18 @@ func ResourceArgs(rl *v1.ResourceLimits) []string {
 	}
 
 	if rl.Cores != nil {
-		min, max := int(rl.Cores.Min), int(rl.Cores.Max)
+		min, max := rl.Cores.Scaled(1)
 		args = append(args, CoresTotalArg.Range(min, max))
 	}
 
 	if rl.Memory != nil {
-		min, max := int(rl.Memory.Min), int(rl.Memory.Max)
+		// The cluster-autoscaler takes memory limits in GiB.
+		min, max := rl.Memory.Scaled(gibibyte)
 		args = append(args, MemoryTotalArg.Range(min, max))
 	}
 
 	for _, g := range rl.GPUS {
-		min, max := int(g.Min), int(g.Max)
-		args = append(args, GPUTotalArg.TypeRange(g.Type, min, max))
+		args = append(args, GPUTotalArg.TypeRange(g.Type, int64(g.Min), int64(g.Max)))
 	}
 
 	return args
@@ -439,37 +514,53 @@ func isFeatureGateEnabled(accessor featuregates.FeatureGateAccess, name string)
 	return false
 }
 
+// ProvisioningRequestsEnabled returns true if the cluster-autoscaler handles
+// ProvisioningRequests, i.e. if their feature gate is enabled.
+func ProvisioningRequestsEnabled(accessor featuregates.FeatureGateAccess) bool {
+	return isFeatureGateEnabled(accessor, provisioningRequestFGName)
+}
+
 // shouldDisableClusterAPIProviderFor returns true if the Cluster API provider should be disabled
 // on a given platform. Because not all OpenShift clusters will have Cluster API resources
 // available, we need to determine when Cluster API provider creation should be disabled on a platform.
 func shouldDisableClusterAPIProviderFor(cfg Config) bool {
-	var fgName string
+	return !ClusterAPIProviderEnabled(cfg.platformType, cfg.FeatureGateAccessor)
+}
 
-	switch cfg.platformType {
+// ClusterAPIProviderEnabled returns true if the cluster-autoscaler discovers
+// Cluster API node groups on the given platform, i.e. if the Cluster API
+// machine management feature gate of the platform is enabled.
+func ClusterAPIProviderEnabled(platformType configv1.PlatformType, accessor featuregates.FeatureGateAccess) bool {
+	fgName := clusterAPIFeatureGateName(platformType)
+	if fgName == "" {
+		// if we can't determine the platform type, default to disabling Cluster API to be safe
+		return false
+	}
+
+	// if the feature gate is enabled on a given platform, then we know Cluster API is enabled
+	return isFeatureGateEnabled(accessor, fgName)
+}
+
+// clusterAPIFeatureGateName returns the name of the Cluster API machine
+// management feature gate of the given platform, or an empty string if
+// Cluster API is not supported on the platform.
+func clusterAPIFeatureGateName(platformType configv1.PlatformType) string {
+	switch platformType {
 	case configv1.AWSPlatformType:
-		fgName = clusterapiAWSFGName
+		return clusterapiAWSFGName
 	case configv1.AzurePlatformType:
-		fgName = clusterapiAzureFGName
+		return clusterapiAzureFGName
 	case configv1.BareMetalPlatformType:
-		fgName = clusterapiBareMetalFGName
+		return clusterapiBareMetalFGName
 	case configv1.GCPPlatformType:
-		fgName = clusterapiGCPFGName
+		return clusterapiGCPFGName
 	case configv1.OpenStackPlatformType:
-		fgName = clusterapiOpenStackFGName
+		return clusterapiOpenStackFGName
 	case configv1.PowerVSPlatformType:
-		fgName = clusterapiPowerVSFGName
+		return clusterapiPowerVSFGName
 	case configv1.VSpherePlatformType:
-		fgName = clusterapiVSphereFGName
-	default:
-		// if we can't determine the platform type, default to disabling Cluster API to be safe
-		fgName = ""
-	}
-
-	if len(fgName) > 0 {
-		// if the feature gate is enabled on a given platform, then we know Cluster API is enabled
-		return !isFeatureGateEnabled(cfg.FeatureGateAccessor, fgName)
+		return clusterapiVSphereFGName
 	}
 
-	// if we fall through to here it is because we can't determine the platform, the safest thing to do is disable Cluster API
-	return true
+	return ""
 }
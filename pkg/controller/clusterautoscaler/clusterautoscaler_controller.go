@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 	goruntime "runtime"
+	"sync"
 
 	configv1 "github.com/openshift/api/config/v1"
 	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	autoscalingv2 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v2"
+	"github.com/openshift/cluster-autoscaler-operator/pkg/metrics"
 	"github.com/openshift/cluster-autoscaler-operator/pkg/util"
 	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
-	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
@@ -22,6 +24,7 @@ import (
 	"k8s.io/client-go/tools/events"
 	"k8s.io/client-go/tools/reference"
 	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -44,13 +47,23 @@ const (
 
 // NewReconciler returns a new Reconciler.
 func NewReconciler(mgr manager.Manager, config Config) *Reconciler {
-	return &Reconciler{
+	r := &Reconciler{
 		client:    mgr.GetClient(),
 		scheme:    mgr.GetScheme(),
 		recorder:  mgr.GetEventRecorder(controllerName),
 		validator: NewValidator(config.Name, mgr.GetClient(), mgr.GetScheme()),
 		config:    config,
+
+		configEvents: make(chan event.TypedGenericEvent[*autoscalingv1.ClusterAutoscaler], 1),
 	}
+
+	// Cross-resource admission checks read live objects directly rather
+	// than through the cache, which would otherwise have to hold all nodes.
+	r.validator.reader = mgr.GetAPIReader()
+	r.validator.namespace = config.Namespace
+	r.validator.strict = config.StrictValidation
+
+	return r
 }
 
 // Config represents the configuration for a reconciler instance.
@@ -77,10 +90,33 @@ type Config struct {
 	FeatureGateAccessor featuregates.FeatureGateAccess
 	// The port the webhooks service is listening on
 	WebhooksPort int
+	// Whether cross-resource admission checks reject rather than warn.
+	StrictValidation bool
+	// Whether the operator runs without the OpenShift configuration APIs.
+	Standalone bool
+	// The static platform type used in standalone mode.
+	PlatformType configv1.PlatformType
 }
 
 var _ reconcile.Reconciler = &Reconciler{}
 
+// ClusterState is the cluster-wide configuration the objects belonging to a
+// ClusterAutoscaler are built from.  It is observed on each reconcile, and
+// the zero value is used when rendering without a cluster.
+type ClusterState struct {
+	// Proxy is the status of the cluster-wide proxy configuration, or nil
+	// if no proxy is configured.
+	Proxy *configv1.ProxyStatus
+
+	// TrustedCABundleHash is a hash of the injected trusted CA bundle, or
+	// empty if none is injected.
+	TrustedCABundleHash string
+
+	// Infrastructure is the status of the cluster Infrastructure, or nil if
+	// not known.
+	Infrastructure *configv1.InfrastructureStatus
+}
+
 // Reconciler reconciles a ClusterAutoscaler object
 type Reconciler struct {
 	// This client, initialized using mgr.Client() above, is a split client
@@ -90,6 +126,22 @@ type Reconciler struct {
 	config    Config
 	scheme    *runtime.Scheme
 	validator *Validator
+
+	// The controller and cache used to start watching the monitoring types
+	// once they are served by the cluster, and whether this has been done.
+	controller        controller.Controller
+	cache             cache.Cache
+	monitoringWatched bool
+
+	// A configuration set by SetConfig, applied at the start of the next
+	// reconcile so that the configuration does not change while
+	// reconciling.  configMu guards it, as well as updates to config,
+	// which is read outside of reconciles by event handlers.
+	configMu      sync.Mutex
+	pendingConfig *Config
+
+	// Events queuing a reconcile once a new configuration is set.
+	configEvents chan event.TypedGenericEvent[*autoscalingv1.ClusterAutoscaler]
 }
 
 // AddToManager adds a new Controller to mgr with r as the reconcile.Reconciler
@@ -123,6 +175,11 @@ func (r *Reconciler) AddToManager(mgr manager.Manager) error {
 		return err
 	}
 
+	// Reconcile when a new configuration is set
+	if err := c.Watch(source.Channel(r.configEvents, &handler.TypedEnqueueRequestForObject[*autoscalingv1.ClusterAutoscaler]{})); err != nil {
+		return err
+	}
+
 	// Watch for changes to secondary resources owned by a ClusterAutoscaler
 	if err := c.Watch(source.Kind(mgr.GetCache(), &appsv1.Deployment{}, handler.TypedEnqueueRequestForOwner[*appsv1.Deployment](
 		mgr.GetScheme(),
@@ -143,8 +200,8 @@ func (r *Reconciler) AddToManager(mgr manager.Manager) error {
 		return err
 	}
 
-	// Watch for changes to monitoring resources owned by a ClusterAutoscaler
-	if err := c.Watch(source.Kind(mgr.GetCache(), &corev1.Service{}, handler.TypedEnqueueRequestForOwner[*corev1.Service](
+	// Watch for changes to the trusted CA bundle owned by a ClusterAutoscaler
+	if err := c.Watch(source.Kind(mgr.GetCache(), &corev1.ConfigMap{}, handler.TypedEnqueueRequestForOwner[*corev1.ConfigMap](
 		mgr.GetScheme(),
 		mgr.GetRESTMapper(),
 		&autoscalingv1.ClusterAutoscaler{},
@@ -153,7 +210,24 @@ func (r *Reconciler) AddToManager(mgr manager.Manager) error {
 		return err
 	}
 
-	if err := c.Watch(source.Kind(mgr.GetCache(), &monitoringv1.ServiceMonitor{}, handler.TypedEnqueueRequestForOwner[*monitoringv1.ServiceMonitor](
+	// The cluster-wide configuration resources are not served in standalone
+	// mode.
+	if !r.config.Standalone {
+		// Watch for changes to the cluster-wide proxy configuration
+		if err := c.Watch(source.Kind(mgr.GetCache(), &configv1.Proxy{},
+			handler.TypedEnqueueRequestsFromMapFunc[*configv1.Proxy](r.proxyRequest))); err != nil {
+			return err
+		}
+
+		// Watch for changes to the cluster Infrastructure configuration
+		if err := c.Watch(source.Kind(mgr.GetCache(), &configv1.Infrastructure{},
+			handler.TypedEnqueueRequestsFromMapFunc[*configv1.Infrastructure](r.infrastructureRequest))); err != nil {
+			return err
+		}
+	}
+
+	// Watch for changes to monitoring resources owned by a ClusterAutoscaler
+	if err := c.Watch(source.Kind(mgr.GetCache(), &corev1.Service{}, handler.TypedEnqueueRequestForOwner[*corev1.Service](
 		mgr.GetScheme(),
 		mgr.GetRESTMapper(),
 		&autoscalingv1.ClusterAutoscaler{},
@@ -162,12 +236,27 @@ func (r *Reconciler) AddToManager(mgr manager.Manager) error {
 		return err
 	}
 
-	return c.Watch(source.Kind(mgr.GetCache(), &monitoringv1.PrometheusRule{}, handler.TypedEnqueueRequestForOwner[*monitoringv1.PrometheusRule](
-		mgr.GetScheme(),
-		mgr.GetRESTMapper(),
-		&autoscalingv1.ClusterAutoscaler{},
-		handler.OnlyControllerOwner(),
-	)))
+	r.controller = c
+	r.cache = mgr.GetCache()
+
+	// The prometheus-operator types may not be served by the cluster.  If so,
+	// watch for their CRDs being installed and start watching them then.
+	available, err := r.monitoringAvailable()
+	if err != nil {
+		return err
+	}
+
+	if available {
+		return r.ensureMonitoringWatches()
+	}
+
+	klog.Info("Monitoring types are not available, watching for their CRDs")
+
+	crd := &metav1.PartialObjectMetadata{}
+	crd.SetGroupVersionKind(customResourceDefinitionGVK)
+
+	return c.Watch(source.Kind(mgr.GetCache(), crd,
+		handler.TypedEnqueueRequestsFromMapFunc[*metav1.PartialObjectMetadata](r.monitoringCRDRequest)))
 }
 
 // Reconcile reads that state of the cluster for a ClusterAutoscaler
@@ -177,6 +266,8 @@ func (r *Reconciler) Reconcile(_ context.Context, request reconcile.Request) (re
 	// TODO(elmiko) update this function to use the context that is provided
 	klog.Infof("Reconciling ClusterAutoscaler %s\n", request.Name)
 
+	r.applyPendingConfig()
+
 	// Fetch the ClusterAutoscaler instance
 	ca := &autoscalingv1.ClusterAutoscaler{}
 	err := r.client.Get(context.TODO(), request.NamespacedName, ca)
@@ -187,6 +278,7 @@ func (r *Reconciler) Reconcile(_ context.Context, request reconcile.Request) (re
 			// garbage collected. For additional cleanup logic use
 			// finalizers.  Return and don't requeue.
 			klog.Infof("ClusterAutoscaler %s not found, will not reconcile", request.Name)
+			metrics.SetClusterAutoscalerResourceLimits(nil)
 			return reconcile.Result{}, nil
 		}
 
@@ -208,17 +300,29 @@ func (r *Reconciler) Reconcile(_ context.Context, request reconcile.Request) (re
 		return reconcile.Result{}, res.Errors
 	}
 
-	existingDeployment, err := r.GetAutoscaler(ca)
-	if err != nil && !errors.IsNotFound(err) {
-		r.recorder.Eventf(caRef, ca, corev1.EventTypeWarning, "FailedGetDeployment", "GetDeployment", "Error getting cluster-autoscaler deployment: %v", err)
-		klog.Errorf("Error getting cluster-autoscaler deployment: %v", err)
+	// Spec fields the operator did not validate before are only rejected at
+	// admission, existing problems are reported without failing the reconcile.
+	if res := r.validator.ValidateSpecFields(ca, ca); len(res.Warnings) > 0 {
+		klog.Warningf("ClusterAutoscaler validation warnings: %v", res.Warnings)
+	}
 
-		return reconcile.Result{}, err
+	metrics.SetClusterAutoscalerResourceLimits(ca.Spec.ResourceLimits)
+
+	// The deployment lookup error is kept under its own name, as it decides
+	// below whether the deployment is created or updated.
+	existingDeployment, deploymentErr := r.GetAutoscaler(ca)
+	if deploymentErr != nil && !errors.IsNotFound(deploymentErr) {
+		r.recorder.Eventf(caRef, ca, corev1.EventTypeWarning, "FailedGetDeployment", "GetDeployment", "Error getting cluster-autoscaler deployment: %v", deploymentErr)
+		klog.Errorf("Error getting cluster-autoscaler deployment: %v", deploymentErr)
+
+		return reconcile.Result{}, deploymentErr
 	}
 
 	// Make sure not to create a new deployment when the CA is being removed.
 	if ca.GetDeletionTimestamp() != nil {
-		if !errors.IsNotFound(err) {
+		metrics.SetClusterAutoscalerResourceLimits(nil)
+
+		if !errors.IsNotFound(deploymentErr) {
 			// We've already checked for other errors, so this means there was no error, ie the deployment exists.
 			// Remove the deployment if it still exists (GC may have beaten us to this).
 			if err := r.client.Delete(context.TODO(), existingDeployment); err != nil && !errors.IsNotFound(err) {
@@ -228,16 +332,23 @@ func (r *Reconciler) Reconcile(_ context.Context, request reconcile.Request) (re
 		return reconcile.Result{}, nil
 	}
 
-	// Update the cluster provider type.
-	if r.config.platformType == "" {
-		platformType, err := r.getPlatformType()
-		if err != nil {
-			return reconcile.Result{}, err
-		}
-		r.config.platformType = platformType
+	// Observe the infrastructure status and cluster provider type.
+	infrastructure, err := r.ensureInfrastructureStatus()
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	state := ClusterState{Infrastructure: infrastructure}
+
+	if err := r.ensureProxyConfig(ca, &state); err != nil {
+		r.recorder.Eventf(caRef, ca, corev1.EventTypeWarning, "FailedCreate", "EnsureProxyConfig", "Error ensuring ClusterAutoscaler proxy configuration: %v", err)
+		klog.Errorf("Error ensuring ClusterAutoscaler proxy configuration: %v", err)
+
+		return reconcile.Result{}, err
 	}
 
-	if err := r.ensureAutoscalerMonitoring(ca); err != nil {
+	monitoring, err := r.ensureAutoscalerMonitoring(ca)
+	if err != nil {
 		r.recorder.Eventf(caRef, ca, corev1.EventTypeWarning, "FailedCreate", "EnsureMonitoring", "Error ensuring ClusterAutoscaler monitoring: %v", err)
 		klog.Errorf("Error ensuring ClusterAutoscaler monitoring: %v", err)
 
@@ -245,7 +356,13 @@ func (r *Reconciler) Reconcile(_ context.Context, request reconcile.Request) (re
 	}
 	klog.Info("Ensured ClusterAutoscaler monitoring")
 
-	if _, err := r.createOrUpdateAutoscalerNetworkPolicies(ca); err != nil {
+	if err := r.updateMonitoringCondition(ca, monitoring); err != nil {
+		klog.Errorf("Error updating ClusterAutoscaler monitoring status: %v", err)
+
+		return reconcile.Result{}, err
+	}
+
+	if _, err := r.createOrUpdateAutoscalerNetworkPolicies(ca, state); err != nil {
 		r.recorder.Eventf(caRef, ca, corev1.EventTypeWarning, "FailedCreate", "EnsureNetworkPolicies", "Error ensuring ClusterAutoscaler networkpolicies: %v", err)
 		klog.Errorf("Error ensuring ClusterAutoscaler networkpolicies: %v", err)
 
@@ -253,8 +370,8 @@ func (r *Reconciler) Reconcile(_ context.Context, request reconcile.Request) (re
 	}
 	klog.Info("Ensured ClusterAutoscaler networkpolicies")
 
-	if errors.IsNotFound(err) {
-		if err := r.CreateAutoscaler(ca); err != nil {
+	if errors.IsNotFound(deploymentErr) {
+		if err := r.CreateAutoscaler(ca, state); err != nil {
 			r.recorder.Eventf(caRef, ca, corev1.EventTypeWarning, "FailedCreate", "CreateDeployment", "Error creating ClusterAutoscaler deployment: %v", err)
 			klog.Errorf("Error creating ClusterAutoscaler deployment: %v", err)
 
@@ -268,7 +385,7 @@ func (r *Reconciler) Reconcile(_ context.Context, request reconcile.Request) (re
 		return reconcile.Result{}, nil
 	}
 
-	if err := r.UpdateAutoscaler(ca); err != nil {
+	if err := r.UpdateAutoscaler(ca, state); err != nil {
 		r.recorder.Eventf(caRef, ca, corev1.EventTypeWarning, "FailedUpdate", "UpdateDeployment", "Error updating cluster-autoscaler deployment: %v", err)
 		klog.Errorf("Error updating cluster-autoscaler deployment: %v", err)
 
@@ -287,9 +404,57 @@ func (r *Reconciler) Validator() *Validator {
 	return r.validator
 }
 
-// SetConfig sets the given config on the reconciler.
+// Defaulter returns a defaulter for ClusterAutoscaler resources.
+func (r *Reconciler) Defaulter() *Defaulter {
+	return NewDefaulter(r.scheme)
+}
+
+// SetConfig sets the given config on the reconciler.  It is applied at the
+// start of the next reconcile, which is queued for the configured
+// ClusterAutoscaler.
 func (r *Reconciler) SetConfig(cfg Config) {
+	r.configMu.Lock()
+	r.pendingConfig = &cfg
+	r.configMu.Unlock()
+
+	if r.configEvents == nil {
+		return
+	}
+
+	ca := &autoscalingv1.ClusterAutoscaler{}
+	ca.SetName(cfg.Name)
+
+	// A reconcile is already queued if the channel is full.
+	select {
+	case r.configEvents <- event.TypedGenericEvent[*autoscalingv1.ClusterAutoscaler]{Object: ca}:
+	default:
+	}
+}
+
+// applyPendingConfig applies the config set by SetConfig, if any.  The
+// observed platform type is kept.
+func (r *Reconciler) applyPendingConfig() {
+	r.configMu.Lock()
+	defer r.configMu.Unlock()
+
+	if r.pendingConfig == nil {
+		return
+	}
+
+	cfg := *r.pendingConfig
+	cfg.platformType = r.config.platformType
+
 	r.config = cfg
+	r.pendingConfig = nil
+}
+
+// configName returns the configured name of the ClusterAutoscaler, for use
+// outside of reconciles.
+func (r *Reconciler) configName() string {
+	r.configMu.Lock()
+	defer r.configMu.Unlock()
+
+	return r.config.Name
 }
 
 // NamePredicate is used in predicate functions.  It returns true if
@@ -297,7 +462,7 @@ func (r *Reconciler) SetConfig(cfg Config) {
 // ClusterAutoscaler resource.
 func (r *Reconciler) NamePredicate(meta metav1.Object) bool {
 	// Only process events for objects matching the configured resource name.
-	if meta.GetName() != r.config.Name {
+	if meta.GetName() != r.configName() {
 		klog.Warningf("Not processing ClusterAutoscaler %s", meta.GetName())
 		return false
 	}
@@ -307,10 +472,13 @@ func (r *Reconciler) NamePredicate(meta metav1.Object) bool {
 
 // CreateAutoscaler will create the deployment for the given the
 // ClusterAutoscaler custom resource instance.
-func (r *Reconciler) CreateAutoscaler(ca *autoscalingv1.ClusterAutoscaler) error {
+func (r *Reconciler) CreateAutoscaler(ca *autoscalingv1.ClusterAutoscaler, state ClusterState) error {
 	klog.Infof("Creating ClusterAutoscaler deployment: %s\n", r.AutoscalerName(ca))
 
-	deployment := r.AutoscalerDeployment(ca)
+	deployment, err := r.AutoscalerDeployment(ca, state)
+	if err != nil {
+		return err
+	}
 
 	// Set ClusterAutoscaler instance as the owner and controller.
 	if err := controllerutil.SetControllerReference(ca, deployment, r.scheme); err != nil {
@@ -322,17 +490,21 @@ func (r *Reconciler) CreateAutoscaler(ca *autoscalingv1.ClusterAutoscaler) error
 
 // UpdateAutoscaler will retrieve the deployment for the given ClusterAutoscaler
 // custom resource instance and update it to match the expected spec if needed.
-func (r *Reconciler) UpdateAutoscaler(ca *autoscalingv1.ClusterAutoscaler) error {
+func (r *Reconciler) UpdateAutoscaler(ca *autoscalingv1.ClusterAutoscaler, state ClusterState) error {
 	existingDeployment, err := r.GetAutoscaler(ca)
 	if err != nil {
 		return err
 	}
 
 	existingSpec := existingDeployment.Spec.Template.Spec
-	expectedSpec := r.AutoscalerPodSpec(ca)
+	expectedSpec, err := r.AutoscalerPodSpec(ca, state)
+	if err != nil {
+		return err
+	}
 
-	// Only comparing podSpec and release version for now.
+	// Only comparing podSpec, trusted CA bundle and release version for now.
 	if equality.Semantic.DeepEqual(existingSpec, expectedSpec) &&
+		trustedCABundleHashMatches(&existingDeployment.Spec.Template, state.TrustedCABundleHash) &&
 		util.ReleaseVersionMatches(ca, r.config.ReleaseVersion) {
 		return nil
 	}
@@ -341,6 +513,7 @@ func (r *Reconciler) UpdateAutoscaler(ca *autoscalingv1.ClusterAutoscaler) error
 
 	r.UpdateAnnotations(existingDeployment)
 	r.UpdateAnnotations(&existingDeployment.Spec.Template)
+	setTrustedCABundleHash(&existingDeployment.Spec.Template, state.TrustedCABundleHash)
 
 	return r.client.Update(context.TODO(), existingDeployment)
 }
@@ -383,8 +556,8 @@ func (r *Reconciler) UpdateAnnotations(obj metav1.Object) {
 }
 
 // AutoscalerDeployment returns the expected deployment belonging to the given
-// ClusterAutoscaler.
-func (r *Reconciler) AutoscalerDeployment(ca *autoscalingv1.ClusterAutoscaler) *appsv1.Deployment {
+// ClusterAutoscaler, in the given cluster state.
+func (r *Reconciler) AutoscalerDeployment(ca *autoscalingv1.ClusterAutoscaler, state ClusterState) (*appsv1.Deployment, error) {
 	namespacedName := r.AutoscalerName(ca)
 
 	labels := map[string]string{
@@ -398,7 +571,19 @@ func (r *Reconciler) AutoscalerDeployment(ca *autoscalingv1.ClusterAutoscaler) *
 		util.WorkloadManagementAnnotation: util.WorkloadManagementSchedulingPreferred,
 	}
 
-	podSpec := r.AutoscalerPodSpec(ca)
+	podSpec, err := r.AutoscalerPodSpec(ca, state)
+	if err != nil {
+		return nil, err
+	}
+
+	templateAnnotations := map[string]string{}
+	for k, v := range annotations {
+		templateAnnotations[k] = v
+	}
+
+	if state.TrustedCABundleHash != "" {
+		templateAnnotations[TrustedCABundleHashAnnotation] = state.TrustedCABundleHash
+	}
 
 	deployment := &appsv1.Deployment{
 		TypeMeta: metav1.TypeMeta{
@@ -418,20 +603,27 @@ func (r *Reconciler) AutoscalerDeployment(ca *autoscalingv1.ClusterAutoscaler) *
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels:      labels,
-					Annotations: annotations,
+					Annotations: templateAnnotations,
 				},
 				Spec: *podSpec,
 			},
 		},
 	}
 
-	return deployment
+	return deployment, nil
 }
 
 // AutoscalerPodSpec returns the expected podSpec for the deployment belonging
-// to the given ClusterAutoscaler.
-func (r *Reconciler) AutoscalerPodSpec(ca *autoscalingv1.ClusterAutoscaler) *corev1.PodSpec {
-	args := AutoscalerArgs(ca, &r.config)
+// to the given ClusterAutoscaler, in the given cluster state.
+func (r *Reconciler) AutoscalerPodSpec(ca *autoscalingv1.ClusterAutoscaler, state ClusterState) (*corev1.PodSpec, error) {
+	// The arguments are rendered from the hub version, which has typed
+	// durations and quantities.
+	hub := &autoscalingv2.ClusterAutoscaler{}
+	if err := ca.ConvertTo(hub); err != nil {
+		return nil, fmt.Errorf("error converting ClusterAutoscaler %s: %v", ca.Name, err)
+	}
+
+	args := AutoscalerArgs(hub, &r.config)
 
 	if r.config.ExtraArgs != "" {
 		args = append(args, r.config.ExtraArgs)
@@ -495,7 +687,11 @@ func (r *Reconciler) AutoscalerPodSpec(ca *autoscalingv1.ClusterAutoscaler) *cor
 		spec.Containers[0].Env = append(spec.Containers[0].Env, capiDisable)
 	}
 
-	return spec
+	// Honor the cluster-wide proxy and trust its CA bundle.
+	spec.Containers[0].Env = append(spec.Containers[0].Env, proxyEnv(state.Proxy)...)
+	r.addTrustedCABundle(ca, spec, state.TrustedCABundleHash)
+
+	return spec, nil
 }
 
 // objectReference returns a reference to the given object, but will set the
@@ -516,11 +712,38 @@ func (r *Reconciler) objectReference(obj runtime.Object) *corev1.ObjectReference
 	return ref
 }
 
-func (r *Reconciler) getPlatformType() (configv1.PlatformType, error) {
+// ensureInfrastructureStatus returns the cluster Infrastructure status and,
+// if not yet known, observes the cluster provider type.  In standalone mode,
+// the configured platform type is used instead, and no status is returned.
+func (r *Reconciler) ensureInfrastructureStatus() (*configv1.InfrastructureStatus, error) {
+	if r.config.Standalone {
+		r.config.platformType = r.config.PlatformType
+		return nil, nil
+	}
+
 	infrastructure := &configv1.Infrastructure{}
 	if err := r.client.Get(context.TODO(), client.ObjectKey{Name: infrastructureName}, infrastructure); err != nil {
-		return "", fmt.Errorf("unable to get infrastructure object: %w", err)
+		return nil, fmt.Errorf("unable to get infrastructure object: %w", err)
+	}
+
+	if r.config.platformType == "" && infrastructure.Status.PlatformStatus != nil {
+		r.config.platformType = infrastructure.Status.PlatformStatus.Type
+	}
+
+	return &infrastructure.Status, nil
+}
+
+// infrastructureRequest is used with handler.EnqueueRequestsFromMapFunc to
+// enqueue a reconcile request for the singleton ClusterAutoscaler when the
+// cluster Infrastructure configuration changes.
+func (r *Reconciler) infrastructureRequest(_ context.Context, infrastructure *configv1.Infrastructure) []reconcile.Request {
+	if infrastructure.GetName() != infrastructureName {
+		return nil
 	}
 
-	return infrastructure.Status.PlatformStatus.Type, nil
+	name := r.configName()
+
+	klog.V(2).Infof("Queuing reconcile for ClusterAutoscaler %s after infrastructure change.", name)
+
+	return []reconcile.Request{{NamespacedName: client.ObjectKey{Name: name}}}
 }
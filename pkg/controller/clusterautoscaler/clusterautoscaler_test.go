@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	configv1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/cluster-autoscaler-operator/pkg/apis"
 	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	autoscalingv2 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v2"
 	"github.com/openshift/cluster-autoscaler-operator/pkg/util"
 	"github.com/openshift/cluster-autoscaler-operator/test/helpers"
 	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
@@ -18,6 +20,9 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -27,6 +32,7 @@ import (
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -116,6 +122,16 @@ func NewClusterAutoscaler() *autoscalingv1.ClusterAutoscaler {
 	}
 }
 
+// toHub converts the given ClusterAutoscaler to the hub (v2) version.
+func toHub(t *testing.T, ca *autoscalingv1.ClusterAutoscaler) *autoscalingv2.ClusterAutoscaler {
+	hub := &autoscalingv2.ClusterAutoscaler{}
+	if err := ca.ConvertTo(hub); err != nil {
+		t.Fatalf("failed to convert ClusterAutoscaler: %v", err)
+	}
+
+	return hub
+}
+
 func includesStringWithPrefix(list []string, prefix string) bool {
 	for i := range list {
 		if strings.HasPrefix(list[i], prefix) {
@@ -298,7 +314,7 @@ func TestAutoscalerArgsFromSpec(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(tt *testing.T) {
 			ca := tc.caFunc()
-			args := AutoscalerArgs(ca, &Config{CloudProvider: TestCloudProvider, Namespace: TestNamespace})
+			args := AutoscalerArgs(toHub(t, ca), &Config{CloudProvider: TestCloudProvider, Namespace: TestNamespace})
 
 			for _, e := range tc.expected {
 				if !includeString(args, e) {
@@ -315,6 +331,66 @@ func TestAutoscalerArgsFromSpec(t *testing.T) {
 	}
 }
 
+// TestAutoscalerArgsDurations validates that durations are rendered as
+// written in the v1 ClusterAutoscaler, so that upgrades do not roll out the
+// deployment, unless they were modified in the hub version.
+func TestAutoscalerArgsDurations(t *testing.T) {
+	ca := NewClusterAutoscaler()
+	ca.Spec.MaxNodeProvisionTime = "10m"
+	ca.Spec.ScaleDown.DelayAfterDelete = ptr.To("90s")
+
+	hub := toHub(t, ca)
+	args := AutoscalerArgs(hub, &Config{CloudProvider: TestCloudProvider, Namespace: TestNamespace})
+
+	assert.Contains(t, args, "--max-node-provision-time=10m")
+	assert.Contains(t, args, "--scale-down-delay-after-delete=90s")
+
+	hub.Spec.ScaleDown.DelayAfterDelete = &metav1.Duration{Duration: 2 * time.Minute}
+	args = AutoscalerArgs(hub, &Config{CloudProvider: TestCloudProvider, Namespace: TestNamespace})
+
+	assert.Contains(t, args, "--scale-down-delay-after-delete=2m0s")
+}
+
+// TestAutoscalerArgsUtilizationThreshold validates that the utilization
+// threshold is rendered as written in the v1 ClusterAutoscaler, and as a
+// plain decimal number if modified in the hub version.
+func TestAutoscalerArgsUtilizationThreshold(t *testing.T) {
+	ca := NewClusterAutoscaler()
+	ca.Spec.ScaleDown.UtilizationThreshold = ptr.To("0.40")
+
+	hub := toHub(t, ca)
+	args := AutoscalerArgs(hub, &Config{CloudProvider: TestCloudProvider, Namespace: TestNamespace})
+
+	assert.Contains(t, args, "--scale-down-utilization-threshold=0.40")
+
+	hub.Spec.ScaleDown.UtilizationThreshold = ptr.To(resource.MustParse("500m"))
+	args = AutoscalerArgs(hub, &Config{CloudProvider: TestCloudProvider, Namespace: TestNamespace})
+
+	assert.Contains(t, args, "--scale-down-utilization-threshold=0.5")
+}
+
+// TestResourceArgs validates that resource quantities are rounded to stay
+// within the configured limits, in the units the autoscaler expects.
+func TestResourceArgs(t *testing.T) {
+	rl := &autoscalingv2.ResourceLimits{
+		Cores: &autoscalingv2.QuantityRange{
+			Min: resource.MustParse("500m"),
+			Max: resource.MustParse("32"),
+		},
+		Memory: &autoscalingv2.QuantityRange{
+			Min: resource.MustParse("1.5Gi"),
+			Max: resource.MustParse("64500Mi"),
+		},
+	}
+
+	expected := []string{
+		"--cores-total=1:32",
+		"--memory-total=2:62",
+	}
+
+	assert.Equal(t, expected, ResourceArgs(rl))
+}
+
 func TestAutoscalerArgsFeatureGate(t *testing.T) {
 	testCases := []struct {
 		name            string
@@ -335,12 +411,34 @@ func TestAutoscalerArgsFeatureGate(t *testing.T) {
 				"--enable-provisioning-requests=true",
 			},
 		},
+		{
+			name: "set Cluster API node group auto discovery",
+			argsConfig: &Config{CloudProvider: TestCloudProvider, Namespace: TestNamespace, platformType: configv1.AWSPlatformType, FeatureGateAccessor: featuregates.NewHardcodedFeatureGateAccess(
+				[]configv1.FeatureGateName{clusterapiAWSFGName},
+				[]configv1.FeatureGateName{},
+			)},
+			caFunc: NewClusterAutoscaler,
+			expected: []string{
+				"--node-group-auto-discovery=clusterapi:namespace=openshift-cluster-api",
+			},
+		},
+		{
+			name: "no Cluster API node group auto discovery on unsupported platform",
+			argsConfig: &Config{CloudProvider: TestCloudProvider, Namespace: TestNamespace, platformType: configv1.NonePlatformType, FeatureGateAccessor: featuregates.NewHardcodedFeatureGateAccess(
+				[]configv1.FeatureGateName{clusterapiAWSFGName},
+				[]configv1.FeatureGateName{},
+			)},
+			caFunc: NewClusterAutoscaler,
+			expectedMissing: []string{
+				"--node-group-auto-discovery",
+			},
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(tt *testing.T) {
 			ca := tc.caFunc()
-			args := AutoscalerArgs(ca, tc.argsConfig)
+			args := AutoscalerArgs(toHub(t, ca), tc.argsConfig)
 
 			for _, e := range tc.expected {
 				if !includeString(args, e) {
@@ -365,9 +463,20 @@ func TestCanGetca(t *testing.T) {
 
 // newFakeReconciler returns a new reconcile.Reconciler with a fake client
 func newFakeReconciler(initObjects ...runtime.Object) *Reconciler {
+	return newFakeReconcilerWithRESTMapper(nil, initObjects...)
+}
+
+// newFakeReconcilerWithRESTMapper returns a new Reconciler with a fake client
+// using the given RESTMapper, or one for all types in the scheme if nil.
+func newFakeReconcilerWithRESTMapper(mapper meta.RESTMapper, initObjects ...runtime.Object) *Reconciler {
+	if mapper == nil {
+		mapper = testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme)
+	}
+
 	fakeClient := fakeclient.
 		NewClientBuilder().
 		WithScheme(scheme.Scheme).
+		WithRESTMapper(mapper).
 		WithRuntimeObjects(initObjects...).
 		WithStatusSubresource(&autoscalingv1.ClusterAutoscaler{}).
 		Build()
@@ -384,15 +493,13 @@ func newFakeReconciler(initObjects ...runtime.Object) *Reconciler {
 // api; that failure mode is not currently captured in this test.
 func TestReconcile(t *testing.T) {
 	ca := NewClusterAutoscaler()
-	ca.ObjectMeta.Name = "cluster"
 	infrastructure := &configv1.Infrastructure{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Infrastructure",
 			APIVersion: "config.openshift.io/v1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "my-infrastructure",
-			Namespace: TestNamespace,
+			Name: infrastructureName,
 		},
 		Status: configv1.InfrastructureStatus{
 			PlatformStatus: &configv1.PlatformStatus{
@@ -416,56 +523,78 @@ func TestReconcile(t *testing.T) {
 			AvailableReplicas:  1,
 		},
 	}
-	req := reconcile.Request{
-		NamespacedName: types.NamespacedName{
-			Namespace: TestNamespace,
-			Name:      "test",
-		},
-	}
 	cfg1 := Config{
 		ReleaseVersion: "test-1",
 		Name:           "test",
 		Namespace:      TestNamespace,
 	}
-	cfg2 := Config{
-		ReleaseVersion: "test-1",
-		Name:           "test2",
-		Namespace:      TestNamespace,
-	}
 	tCases := []struct {
-		expectedError error
-		expectedRes   reconcile.Result
-		c             Config
-		d             *appsv1.Deployment
+		expectedError      error
+		expectedRes        reconcile.Result
+		expectedDeployment bool
+		c                  Config
+		name               string
+		d                  *appsv1.Deployment
 	}{
-		// Case 0: should pass, returns {}, nil.
+		// Case 0: dep found, should pass, returns {}, nil.
 		{
-			expectedError: nil,
-			expectedRes:   reconcile.Result{},
-			c:             cfg1,
-			d:             &dep1,
+			expectedError:      nil,
+			expectedRes:        reconcile.Result{},
+			expectedDeployment: true,
+			c:                  cfg1,
+			name:               "test",
+			d:                  &dep1,
 		},
 		// Case 1: no ca found, should pass, returns {}, nil.
 		{
-			expectedError: nil,
-			expectedRes:   reconcile.Result{},
-			c:             cfg2,
-			d:             &dep1,
+			expectedError:      nil,
+			expectedRes:        reconcile.Result{},
+			expectedDeployment: true,
+			c:                  cfg1,
+			name:               "test2",
+			d:                  &dep1,
+		},
+		// Case 2: no dep found, should create it, returns {}, nil.
+		{
+			expectedError:      nil,
+			expectedRes:        reconcile.Result{},
+			expectedDeployment: true,
+			c:                  cfg1,
+			name:               "test",
 		},
-		// Case 2: no dep found, should pass, returns {}, nil.
+		// Case 3: neither ca nor dep found, should pass, returns {}, nil.
 		{
-			expectedError: nil,
-			expectedRes:   reconcile.Result{},
-			c:             cfg1,
-			d:             &appsv1.Deployment{},
+			expectedError:      nil,
+			expectedRes:        reconcile.Result{},
+			expectedDeployment: false,
+			c:                  cfg1,
+			name:               "test2",
 		},
 	}
 	for i, tc := range tCases {
-		r := newFakeReconciler(ca, tc.d, infrastructure)
+		objects := []runtime.Object{ca.DeepCopy(), infrastructure}
+		if tc.d != nil {
+			objects = append(objects, tc.d.DeepCopy())
+		}
+
+		r := newFakeReconciler(objects...)
 		r.SetConfig(tc.c)
+		req := reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: TestNamespace,
+				Name:      tc.name,
+			},
+		}
 		res, err := r.Reconcile(context.TODO(), req)
 		assert.Equal(t, tc.expectedRes, res, "case %v: expected res incorrect", i)
 		assert.Equal(t, tc.expectedError, err, "case %v: expected err incorrect", i)
+
+		_, err = r.GetAutoscaler(ca)
+		if tc.expectedDeployment {
+			assert.NoError(t, err, "case %v: expected deployment to exist", i)
+		} else {
+			assert.True(t, apierrors.IsNotFound(err), "case %v: expected deployment not to exist, got %v", i, err)
+		}
 	}
 }
 
@@ -542,6 +671,32 @@ func TestCADeleting(t *testing.T) {
 	}
 }
 
+func TestSetConfig(t *testing.T) {
+	r := newFakeReconciler()
+	r.configEvents = make(chan event.TypedGenericEvent[*autoscalingv1.ClusterAutoscaler], 1)
+	r.config.platformType = configv1.AWSPlatformType
+
+	cfg := TestReconcilerConfig
+	cfg.Image = "test/test:v101"
+
+	r.SetConfig(cfg)
+	r.SetConfig(cfg)
+
+	select {
+	case e := <-r.configEvents:
+		assert.Equal(t, cfg.Name, e.Object.GetName())
+	default:
+		t.Fatal("expected a reconcile to be queued")
+	}
+
+	assert.Equal(t, TestReconcilerConfig.Image, r.config.Image, "config applied before reconciling")
+
+	r.applyPendingConfig()
+
+	assert.Equal(t, cfg.Image, r.config.Image)
+	assert.Equal(t, configv1.AWSPlatformType, r.config.platformType, "platform type not kept")
+}
+
 func TestObjectReference(t *testing.T) {
 	testCases := []struct {
 		label     string
@@ -661,3 +816,28 @@ func TestUpdateAnnotations(t *testing.T) {
 		})
 	}
 }
+
+func TestEnsureInfrastructureStatusStandalone(t *testing.T) {
+	// No Infrastructure object exists in standalone mode.
+	r := newFakeReconciler()
+	r.config.Standalone = true
+	r.config.PlatformType = configv1.AWSPlatformType
+
+	infrastructure, err := r.ensureInfrastructureStatus()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if infrastructure != nil {
+		t.Errorf("expected no infrastructure status in standalone mode, got %v", infrastructure)
+	}
+
+	if r.config.platformType != configv1.AWSPlatformType {
+		t.Errorf("expected platform type %q, got %q", configv1.AWSPlatformType, r.config.platformType)
+	}
+
+	proxy, err := r.getProxyStatus()
+	if err != nil || proxy != nil {
+		t.Errorf("expected no proxy status in standalone mode, got %v, %v", proxy, err)
+	}
+}
@@ -0,0 +1,67 @@
+package clusterautoscaler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// Defaulter sets defaults on ClusterAutoscaler resources.
+type Defaulter struct {
+	decoder admission.Decoder
+}
+
+// NewDefaulter returns a new Defaulter.
+func NewDefaulter(scheme *runtime.Scheme) *Defaulter {
+	return &Defaulter{
+		decoder: admission.NewDecoder(scheme),
+	}
+}
+
+// Default sets defaults on the given ClusterAutoscaler resource so that the
+// stored object reflects the behavior of the cluster-autoscaler when fields
+// are left unset.
+func (d *Defaulter) Default(ca *autoscalingv1.ClusterAutoscaler) {
+	if ca == nil {
+		return
+	}
+
+	// The cluster-autoscaler scales down unless told otherwise.
+	if ca.Spec.ScaleDown == nil {
+		ca.Spec.ScaleDown = &autoscalingv1.ScaleDownConfig{Enabled: true}
+	}
+
+	if len(ca.Spec.Expanders) == 0 {
+		ca.Spec.Expanders = []autoscalingv1.ExpanderString{autoscalingv1.RandomExpander}
+	}
+
+	if ca.Spec.EnforceNodeGroupMinSize == nil {
+		ca.Spec.EnforceNodeGroupMinSize = ptr.To(autoscalingv1.EnforceNodeGroupMinSizeModeDisabled)
+	}
+}
+
+// Handle handles HTTP requests for admission webhook servers.
+func (d *Defaulter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	ca := &autoscalingv1.ClusterAutoscaler{}
+
+	if err := d.decoder.Decode(req, ca); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	klog.V(2).Infof("Defaulting webhook called for ClusterAutoscaler: %s", ca.GetName())
+
+	d.Default(ca)
+
+	marshaled, err := json.Marshal(ca)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
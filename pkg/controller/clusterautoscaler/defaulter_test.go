@@ -0,0 +1,93 @@
+package clusterautoscaler
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestDefault(t *testing.T) {
+	defaulter := NewDefaulter(scheme.Scheme)
+
+	t.Run("unset fields are defaulted", func(t *testing.T) {
+		ca := NewClusterAutoscaler()
+		ca.Spec.ScaleDown = nil
+		ca.Spec.Expanders = nil
+		ca.Spec.EnforceNodeGroupMinSize = nil
+
+		defaulter.Default(ca)
+
+		assert.Equal(t, &autoscalingv1.ScaleDownConfig{Enabled: true}, ca.Spec.ScaleDown)
+		assert.Equal(t, []autoscalingv1.ExpanderString{autoscalingv1.RandomExpander}, ca.Spec.Expanders)
+		assert.Equal(t, ptr.To(autoscalingv1.EnforceNodeGroupMinSizeModeDisabled), ca.Spec.EnforceNodeGroupMinSize)
+	})
+
+	t.Run("set fields are unchanged", func(t *testing.T) {
+		ca := NewClusterAutoscaler()
+		ca.Spec.ScaleDown = &autoscalingv1.ScaleDownConfig{Enabled: false}
+		ca.Spec.Expanders = []autoscalingv1.ExpanderString{autoscalingv1.PriorityExpander, autoscalingv1.LeastWasteExpander}
+		ca.Spec.EnforceNodeGroupMinSize = ptr.To(autoscalingv1.EnforceNodeGroupMinSizeModeEnabled)
+
+		expected := ca.DeepCopy()
+		defaulter.Default(ca)
+
+		assert.Equal(t, expected, ca)
+	})
+}
+
+func TestDefaulterHandle(t *testing.T) {
+	defaulter := NewDefaulter(scheme.Scheme)
+
+	ca := NewClusterAutoscaler()
+	ca.Spec.ScaleDown = nil
+	ca.Spec.Expanders = nil
+	ca.Spec.EnforceNodeGroupMinSize = nil
+
+	raw, err := json.Marshal(ca)
+	assert.NoError(t, err)
+
+	res := defaulter.Handle(context.TODO(), admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	})
+
+	assert.True(t, res.Allowed)
+
+	var paths []string
+	for _, patch := range res.Patches {
+		paths = append(paths, patch.Path)
+	}
+
+	assert.Contains(t, paths, "/spec/scaleDown")
+	assert.Contains(t, paths, "/spec/expanders")
+	assert.Contains(t, paths, "/spec/enforceNodeGroupMinSize")
+}
+
+// TestDefaultAutoscalerArgs validates that defaulting a ClusterAutoscaler
+// does not change the cluster-autoscaler arguments, so that existing
+// deployments are not rolled out when their ClusterAutoscaler is defaulted.
+func TestDefaultAutoscalerArgs(t *testing.T) {
+	defaulter := NewDefaulter(scheme.Scheme)
+	cfg := &Config{CloudProvider: TestCloudProvider, Namespace: TestNamespace}
+
+	ca := NewClusterAutoscaler()
+	ca.Spec.ScaleDown = nil
+	ca.Spec.Expanders = nil
+	ca.Spec.EnforceNodeGroupMinSize = nil
+
+	expected := AutoscalerArgs(toHub(t, ca), cfg)
+
+	defaulter.Default(ca)
+
+	assert.Equal(t, expected, AutoscalerArgs(toHub(t, ca), cfg))
+}
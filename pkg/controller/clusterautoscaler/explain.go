@@ -0,0 +1,253 @@
+package clusterautoscaler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	v2 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v2"
+)
+
+// operatorDefaultSource is the source of the arguments always passed to the
+// cluster-autoscaler by the operator.
+const operatorDefaultSource = "operator default"
+
+// Explanation describes where the cluster-autoscaler arguments rendered for
+// a ClusterAutoscaler come from.
+type Explanation struct {
+	// Args are the rendered arguments, in order.
+	Args []ArgExplanation `json:"args"`
+
+	// Ignored are the ClusterAutoscaler fields which are set, but have no
+	// effect on the arguments.
+	Ignored []IgnoredField `json:"ignored,omitempty"`
+}
+
+// ArgExplanation is a rendered cluster-autoscaler argument and its source:
+// a spec field, a feature gate, a platform rule or the operator
+// configuration.
+type ArgExplanation struct {
+	Arg    string `json:"arg"`
+	Source string `json:"source"`
+}
+
+// IgnoredField is a ClusterAutoscaler field without effect on the arguments,
+// and the reason why.
+type IgnoredField struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// specArgSources maps the arguments rendered from a single spec field to
+// that field.
+var specArgSources = map[AutoscalerArg]string{
+	StartupTaint:                     "spec.startupTaints",
+	MaxGracefulTerminationSecArg:     "spec.maxPodGracePeriod",
+	MaxNodeProvisionTimeArg:          "spec.maxNodeProvisionTime",
+	ExpendablePodsPriorityCutoffArg:  "spec.podPriorityThreshold",
+	MaxNodesTotalArg:                 "spec.resourceLimits.maxNodesTotal",
+	CoresTotalArg:                    "spec.resourceLimits.cores",
+	MemoryTotalArg:                   "spec.resourceLimits.memory",
+	GPUTotalArg:                      "spec.resourceLimits.gpus",
+	ScaleDownEnabledArg:              "spec.scaleDown.enabled",
+	ScaleDownDelayAfterAddArg:        "spec.scaleDown.delayAfterAdd",
+	ScaleDownDelayAfterDeleteArg:     "spec.scaleDown.delayAfterDelete",
+	ScaleDownDelayAfterFailureArg:    "spec.scaleDown.delayAfterFailure",
+	ScaleDownUnneededTimeArg:         "spec.scaleDown.unneededTime",
+	ScaleDownUtilizationThresholdArg: "spec.scaleDown.utilizationThreshold",
+	CordonNodeBeforeTerminatingArg:   "spec.scaleDown.cordonNodeBeforeTerminating",
+	NewPodScaleUpDelayArg:            "spec.scaleUp.newPodScaleUpDelay",
+	BalanceSimilarNodeGroupsArg:      "spec.balanceSimilarNodeGroups",
+	IgnoreDaemonsetsUtilization:      "spec.ignoreDaemonsetsUtilization",
+	SkipNodesWithLocalStorage:        "spec.skipNodesWithLocalStorage",
+	ExpanderArg:                      "spec.expanders",
+	EnforceNodeGroupMinSizeArg:       "spec.enforceNodeGroupMinSize",
+}
+
+// Explain returns the explanation of the cluster-autoscaler arguments the
+// reconciler renders for the given ClusterAutoscaler with the given
+// configuration, including the configured extra arguments.  As in Render,
+// the platform type is taken from the configuration.
+func Explain(ca *autoscalingv1.ClusterAutoscaler, cfg Config) (*Explanation, error) {
+	cfg.platformType = cfg.PlatformType
+
+	hub := &v2.ClusterAutoscaler{}
+	if err := ca.ConvertTo(hub); err != nil {
+		return nil, fmt.Errorf("unable to convert ClusterAutoscaler %s: %v", ca.Name, err)
+	}
+
+	explanation := ExplainAutoscalerArgs(hub, &cfg)
+
+	// The hub version drops the values which do not convert, e.g. invalid
+	// durations, so the ignored fields are found in the given version.
+	explanation.Ignored = ignoredFields(&ca.Spec)
+
+	if cfg.ExtraArgs != "" {
+		explanation.Args = append(explanation.Args, ArgExplanation{
+			Arg:    cfg.ExtraArgs,
+			Source: "operator configuration (CLUSTER_AUTOSCALER_EXTRA_ARGS)",
+		})
+	}
+
+	return explanation, nil
+}
+
+// ExplainAutoscalerArgs returns the explanation of the arguments returned by
+// AutoscalerArgs for the given ClusterAutoscaler, in the hub (v2) version.
+// The ignored fields are not set, see Explain.
+func ExplainAutoscalerArgs(ca *v2.ClusterAutoscaler, cfg *Config) *Explanation {
+	s := &ca.Spec
+	explanation := &Explanation{}
+
+	// The platform ignore labels are rendered before those of the spec, so
+	// the first occurrences of a label listed in both are the platform's.
+	var platformLabels []string
+	if s.BalanceSimilarNodeGroups != nil {
+		for _, arg := range appendBasicIgnoreLabels(nil, cfg) {
+			platformLabels = append(platformLabels, argValue(arg))
+		}
+	}
+
+	for _, arg := range AutoscalerArgs(ca, cfg) {
+		flag, _, _ := strings.Cut(arg, "=")
+		name := AutoscalerArg(flag)
+
+		source, ok := specArgSources[name]
+
+		switch {
+		case ok:
+			// Rendered from a single spec field.
+		case name == CloudProviderArg:
+			source = "operator configuration (CLUSTER_AUTOSCALER_CLOUD_PROVIDER)"
+		case name == NamespaceArg:
+			source = "operator configuration (CLUSTER_AUTOSCALER_NAMESPACE)"
+		case name == NodeGroupAutoDiscovery:
+			source = fmt.Sprintf("feature gate %s, for platform %s", clusterAPIFeatureGateName(cfg.platformType), cfg.platformType)
+		case name == EnableProvisioningRequestsArg:
+			source = fmt.Sprintf("feature gate %s", provisioningRequestFGName)
+		case name == BalancingIgnoreLabelArg:
+			if len(platformLabels) > 0 && platformLabels[0] == argValue(arg) {
+				platformLabels = platformLabels[1:]
+				source = fmt.Sprintf("platform %s ignore labels, with spec.balanceSimilarNodeGroups set", cfg.platformType)
+			} else {
+				source = "spec.balancingIgnoredLabels"
+			}
+		case name == VerbosityArg:
+			if s.LogVerbosity != nil {
+				source = "spec.logVerbosity, overriding CLUSTER_AUTOSCALER_VERBOSITY"
+			} else {
+				source = "operator configuration (CLUSTER_AUTOSCALER_VERBOSITY)"
+			}
+		default:
+			source = operatorDefaultSource
+		}
+
+		explanation.Args = append(explanation.Args, ArgExplanation{Arg: arg, Source: source})
+	}
+
+	return explanation
+}
+
+// ignoredFields returns the fields of the given ClusterAutoscaler spec which
+// are set, but not rendered to arguments.
+func ignoredFields(spec *autoscalingv1.ClusterAutoscalerSpec) []IgnoredField {
+	var ignored []IgnoredField
+
+	if sd := spec.ScaleDown; sd != nil {
+		fields := []struct {
+			name     string
+			set      bool
+			duration *string
+		}{
+			{"delayAfterAdd", sd.DelayAfterAdd != nil, sd.DelayAfterAdd},
+			{"delayAfterDelete", sd.DelayAfterDelete != nil, sd.DelayAfterDelete},
+			{"delayAfterFailure", sd.DelayAfterFailure != nil, sd.DelayAfterFailure},
+			{"unneededTime", sd.UnneededTime != nil, sd.UnneededTime},
+			{"utilizationThreshold", sd.UtilizationThreshold != nil, nil},
+			{"cordonNodeBeforeTerminating", sd.CordonNodeBeforeTerminating != nil, nil},
+		}
+
+		for _, f := range fields {
+			if !f.set {
+				continue
+			}
+
+			if !sd.Enabled {
+				ignored = append(ignored, IgnoredField{
+					Field:  "spec.scaleDown." + f.name,
+					Reason: "scale down is disabled",
+				})
+			} else if reason := invalidDuration(f.duration); reason != "" {
+				ignored = append(ignored, IgnoredField{
+					Field:  "spec.scaleDown." + f.name,
+					Reason: reason,
+				})
+			}
+		}
+
+		if sd.Enabled && sd.CordonNodeBeforeTerminating != nil {
+			switch *sd.CordonNodeBeforeTerminating {
+			case autoscalingv1.CordonNodeBeforeTerminatingModeEnabled, autoscalingv1.CordonNodeBeforeTerminatingModeDisabled:
+			default:
+				ignored = append(ignored, IgnoredField{
+					Field:  "spec.scaleDown.cordonNodeBeforeTerminating",
+					Reason: fmt.Sprintf("unknown mode %q", *sd.CordonNodeBeforeTerminating),
+				})
+			}
+		}
+	}
+
+	if su := spec.ScaleUp; su != nil {
+		if reason := invalidDuration(su.NewPodScaleUpDelay); reason != "" {
+			ignored = append(ignored, IgnoredField{
+				Field:  "spec.scaleUp.newPodScaleUpDelay",
+				Reason: reason,
+			})
+		}
+	}
+
+	for i, expander := range spec.Expanders {
+		switch expander {
+		case autoscalingv1.LeastWasteExpander, autoscalingv1.PriorityExpander, autoscalingv1.RandomExpander:
+		default:
+			ignored = append(ignored, IgnoredField{
+				Field:  fmt.Sprintf("spec.expanders[%d]", i),
+				Reason: fmt.Sprintf("unknown expander %q", expander),
+			})
+		}
+	}
+
+	if mode := spec.EnforceNodeGroupMinSize; mode != nil {
+		switch *mode {
+		case autoscalingv1.EnforceNodeGroupMinSizeModeEnabled, autoscalingv1.EnforceNodeGroupMinSizeModeDisabled:
+		default:
+			ignored = append(ignored, IgnoredField{
+				Field:  "spec.enforceNodeGroupMinSize",
+				Reason: fmt.Sprintf("unknown mode %q", *mode),
+			})
+		}
+	}
+
+	return ignored
+}
+
+// invalidDuration returns why the given duration string is not rendered if
+// it does not parse, or an empty string.  Empty strings are not set.
+func invalidDuration(s *string) string {
+	if s == nil || *s == "" {
+		return ""
+	}
+
+	if _, err := time.ParseDuration(*s); err != nil {
+		return fmt.Sprintf("invalid duration: %v", err)
+	}
+
+	return ""
+}
+
+// argValue returns the value of an argument of the form "--name=value".
+func argValue(arg string) string {
+	_, value, _ := strings.Cut(arg, "=")
+	return value
+}
@@ -0,0 +1,161 @@
+package clusterautoscaler
+
+import (
+	"strings"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/utils/ptr"
+)
+
+// explainedSource returns the source explained for the first argument with
+// the given prefix.
+func explainedSource(explanation *Explanation, prefix string) (string, bool) {
+	for _, arg := range explanation.Args {
+		if strings.HasPrefix(arg.Arg, prefix) {
+			return arg.Source, true
+		}
+	}
+
+	return "", false
+}
+
+func TestExplain(t *testing.T) {
+	ca := NewClusterAutoscaler()
+	ca.Spec.BalanceSimilarNodeGroups = ptr.To(true)
+	ca.Spec.BalancingIgnoredLabels = []string{"test/label", AwsIgnoredLabelLifecycle}
+	ca.Spec.LogVerbosity = ptr.To[int32](4)
+
+	cfg := TestReconcilerConfig
+	cfg.PlatformType = configv1.AWSPlatformType
+	cfg.ExtraArgs = "--test-extra-arg"
+	cfg.FeatureGateAccessor = featuregates.NewHardcodedFeatureGateAccess(
+		[]configv1.FeatureGateName{provisioningRequestFGName, clusterapiAWSFGName},
+		[]configv1.FeatureGateName{},
+	)
+
+	explanation, err := Explain(ca, cfg)
+	if err != nil {
+		t.Fatalf("error explaining arguments: %v", err)
+	}
+
+	expected := map[string]string{
+		LogToStderrArg.String():                                  operatorDefaultSource,
+		NamespaceArg.String():                                    "operator configuration (CLUSTER_AUTOSCALER_NAMESPACE)",
+		NodeGroupAutoDiscovery.String():                          "feature gate ClusterAPIMachineManagementAWS, for platform AWS",
+		EnableProvisioningRequestsArg.String():                   "feature gate ProvisioningRequestAvailable",
+		CoresTotalArg.String():                                   "spec.resourceLimits.cores",
+		ScaleDownDelayAfterAddArg.String():                       "spec.scaleDown.delayAfterAdd",
+		NewPodScaleUpDelayArg.String():                           "spec.scaleUp.newPodScaleUpDelay",
+		BalancingIgnoreLabelArg.Value(AwsIgnoredLabelEbsCsiZone): "platform AWS ignore labels, with spec.balanceSimilarNodeGroups set",
+		BalancingIgnoreLabelArg.Value("test/label"):              "spec.balancingIgnoredLabels",
+		VerbosityArg.String():                                    "spec.logVerbosity, overriding CLUSTER_AUTOSCALER_VERBOSITY",
+		"--test-extra-arg":                                       "operator configuration (CLUSTER_AUTOSCALER_EXTRA_ARGS)",
+	}
+
+	for prefix, source := range expected {
+		got, ok := explainedSource(explanation, prefix)
+		if !ok {
+			t.Errorf("expected argument %s to be explained", prefix)
+			continue
+		}
+
+		if got != source {
+			t.Errorf("expected argument %s to come from %q, got %q", prefix, source, got)
+		}
+	}
+
+	// A label listed by both the platform and the spec is rendered twice.
+	var lifecycleSources []string
+	for _, arg := range explanation.Args {
+		if arg.Arg == BalancingIgnoreLabelArg.Value(AwsIgnoredLabelLifecycle) {
+			lifecycleSources = append(lifecycleSources, arg.Source)
+		}
+	}
+
+	if len(lifecycleSources) != 2 || lifecycleSources[1] != "spec.balancingIgnoredLabels" {
+		t.Errorf("unexpected sources for the lifecycle ignore label: %v", lifecycleSources)
+	}
+
+	// Every argument rendered from the spec is explained by a spec field.
+	for _, arg := range AutoscalerArgs(toHub(t, ca), &cfg) {
+		flag, _, _ := strings.Cut(arg, "=")
+		if _, ok := specArgSources[AutoscalerArg(flag)]; !ok {
+			continue
+		}
+
+		if source, _ := explainedSource(explanation, flag); !strings.HasPrefix(source, "spec.") {
+			t.Errorf("expected argument %s to come from a spec field, got %q", arg, source)
+		}
+	}
+
+	if len(explanation.Ignored) != 0 {
+		t.Errorf("expected no ignored fields, got %+v", explanation.Ignored)
+	}
+}
+
+func TestExplainIgnoredFields(t *testing.T) {
+	ca := NewClusterAutoscaler()
+	ca.Spec.ScaleDown = &autoscalingv1.ScaleDownConfig{
+		Enabled:       false,
+		DelayAfterAdd: &ScaleDownDelayAfterAdd,
+		UnneededTime:  &ScaleDownUnneededTime,
+	}
+
+	explanation, err := Explain(ca, TestReconcilerConfig)
+	if err != nil {
+		t.Fatalf("error explaining arguments: %v", err)
+	}
+
+	expected := []IgnoredField{
+		{Field: "spec.scaleDown.delayAfterAdd", Reason: "scale down is disabled"},
+		{Field: "spec.scaleDown.unneededTime", Reason: "scale down is disabled"},
+	}
+
+	if !equality.Semantic.DeepEqual(explanation.Ignored, expected) {
+		t.Errorf("expected ignored fields %+v, got %+v", expected, explanation.Ignored)
+	}
+
+	if source, _ := explainedSource(explanation, VerbosityArg.String()); source != "operator configuration (CLUSTER_AUTOSCALER_VERBOSITY)" {
+		t.Errorf("expected verbosity from the operator configuration, got %q", source)
+	}
+}
+
+func TestExplainInvalidDurations(t *testing.T) {
+	invalid := "1d"
+
+	ca := NewClusterAutoscaler()
+	ca.Spec.ScaleDown = &autoscalingv1.ScaleDownConfig{
+		Enabled:       true,
+		DelayAfterAdd: &invalid,
+		UnneededTime:  &ScaleDownUnneededTime,
+	}
+	ca.Spec.ScaleUp = &autoscalingv1.ScaleUpConfig{
+		NewPodScaleUpDelay: &invalid,
+	}
+
+	explanation, err := Explain(ca, TestReconcilerConfig)
+	if err != nil {
+		t.Fatalf("error explaining arguments: %v", err)
+	}
+
+	expected := []IgnoredField{
+		{Field: "spec.scaleDown.delayAfterAdd", Reason: `invalid duration: time: unknown unit "d" in duration "1d"`},
+		{Field: "spec.scaleUp.newPodScaleUpDelay", Reason: `invalid duration: time: unknown unit "d" in duration "1d"`},
+	}
+
+	if !equality.Semantic.DeepEqual(explanation.Ignored, expected) {
+		t.Errorf("expected ignored fields %+v, got %+v", expected, explanation.Ignored)
+	}
+
+	if _, ok := explainedSource(explanation, ScaleDownDelayAfterAddArg.String()); ok {
+		t.Errorf("expected no %s argument", ScaleDownDelayAfterAddArg)
+	}
+
+	if source, _ := explainedSource(explanation, ScaleDownUnneededTimeArg.String()); source != "spec.scaleDown.unneededTime" {
+		t.Errorf("expected unneeded time from the spec, got %q", source)
+	}
+}
@@ -0,0 +1,388 @@
+package clusterautoscaler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	v2 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+var (
+	// errOperatorManaged is the reason an argument set by the operator
+	// is not imported.
+	errOperatorManaged = errors.New("set by the operator")
+
+	// errUnsupported is the reason an argument without ClusterAutoscaler
+	// field is not imported.
+	errUnsupported = errors.New("not supported by the ClusterAutoscaler API")
+)
+
+// operatorManagedArgs are the arguments the operator sets itself, from its
+// configuration or the feature gates.
+var operatorManagedArgs = map[AutoscalerArg]bool{
+	LogToStderrArg:                true,
+	RecordDuplicatedEventsArg:     true,
+	CloudProviderArg:              true,
+	NamespaceArg:                  true,
+	LeaderElectLeaseDurationArg:   true,
+	LeaderElectRenewDeadlineArg:   true,
+	LeaderElectRetryPeriodArg:     true,
+	MaxBulkSoftTaintCountArg:      true,
+	KubeAPIContentType:            true,
+	NodeGroupAutoDiscovery:        true,
+	EnableProvisioningRequestsArg: true,
+}
+
+// boolArgs are the boolean arguments, which may be given without value.
+var boolArgs = map[AutoscalerArg]bool{
+	LogToStderrArg:                 true,
+	RecordDuplicatedEventsArg:      true,
+	ScaleDownEnabledArg:            true,
+	CordonNodeBeforeTerminatingArg: true,
+	EnforceNodeGroupMinSizeArg:     true,
+	BalanceSimilarNodeGroupsArg:    true,
+	IgnoreDaemonsetsUtilization:    true,
+	SkipNodesWithLocalStorage:      true,
+	EnableProvisioningRequestsArg:  true,
+}
+
+// ImportedArgs is the result of importing a cluster-autoscaler command line.
+type ImportedArgs struct {
+	// ClusterAutoscaler holds the spec corresponding to the arguments, in
+	// the hub (v2) version.
+	ClusterAutoscaler *v2.ClusterAutoscaler
+
+	// NodeGroups are the node groups given with --nodes.
+	NodeGroups []NodeGroup
+
+	// Unrepresented are the arguments which could not be imported.
+	Unrepresented []UnrepresentedArg
+
+	// durations holds the duration arguments as given, so that they are
+	// kept as written once converted to v1 or rendered back.
+	durations v1.ClusterAutoscalerSpec
+}
+
+// NodeGroup is a node group given to the cluster-autoscaler with
+// --nodes=min:max:name.
+type NodeGroup struct {
+	Name    string
+	MinSize int32
+	MaxSize int32
+}
+
+// UnrepresentedArg is a cluster-autoscaler argument which could not be
+// imported, and the reason why.
+type UnrepresentedArg struct {
+	Arg    string `json:"arg"`
+	Reason string `json:"reason"`
+}
+
+// ImportAutoscalerArgs returns the ClusterAutoscaler and node groups
+// corresponding to the given cluster-autoscaler arguments.  This is the
+// inverse of AutoscalerArgs: arguments set by the operator, or without
+// corresponding field, are reported as unrepresented.
+func ImportAutoscalerArgs(args []string) *ImportedArgs {
+	imported := &ImportedArgs{
+		ClusterAutoscaler: &v2.ClusterAutoscaler{},
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		// The cluster-autoscaler also accepts single dash flags.
+		name, value, hasValue := strings.Cut(arg, "=")
+		flag := AutoscalerArg("--" + strings.TrimLeft(name, "-"))
+
+		if !hasValue {
+			if boolArgs[flag] {
+				value = trueFlag
+			} else if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+				// The value is given as the next argument.
+				i++
+				value = args[i]
+				arg = fmt.Sprintf("%s %s", arg, value)
+			}
+		}
+
+		if err := imported.importArg(flag, value); err != nil {
+			imported.Unrepresented = append(imported.Unrepresented, UnrepresentedArg{
+				Arg:    arg,
+				Reason: err.Error(),
+			})
+		}
+	}
+
+	if err := v1.SetOriginalSpec(imported.ClusterAutoscaler, &imported.durations); err != nil {
+		klog.Errorf("Error recording the imported durations: %v", err)
+	}
+
+	return imported
+}
+
+// importArg sets the ClusterAutoscaler field or node group corresponding to
+// the given argument.
+func (imported *ImportedArgs) importArg(flag AutoscalerArg, value string) error {
+	s := &imported.ClusterAutoscaler.Spec
+
+	if operatorManagedArgs[flag] {
+		return errOperatorManaged
+	}
+
+	var err error
+
+	switch flag {
+	case VerbosityArg:
+		s.LogVerbosity, err = parseInt32(value)
+	case StartupTaint:
+		s.StartupTaints = append(s.StartupTaints, value)
+	case MaxGracefulTerminationSecArg:
+		s.MaxPodGracePeriod, err = parseInt32(value)
+	case MaxNodeProvisionTimeArg:
+		if s.MaxNodeProvisionTime, err = parseDuration(value); err == nil {
+			imported.durations.MaxNodeProvisionTime = value
+		}
+	case ExpendablePodsPriorityCutoffArg:
+		s.PodPriorityThreshold, err = parseInt32(value)
+	case MaxNodesTotalArg:
+		resourceLimits(s).MaxNodesTotal, err = parseInt32(value)
+	case CoresTotalArg:
+		resourceLimits(s).Cores, err = parseQuantityRange(value, 1, resource.DecimalSI)
+	case MemoryTotalArg:
+		// The cluster-autoscaler takes memory limits in GiB.
+		resourceLimits(s).Memory, err = parseQuantityRange(value, gibibyte, resource.BinarySI)
+	case GPUTotalArg:
+		var gpu *v2.GPULimit
+		if gpu, err = parseGPULimit(value); err == nil {
+			resourceLimits(s).GPUS = append(resourceLimits(s).GPUS, *gpu)
+		}
+	case ScaleDownEnabledArg:
+		var enabled bool
+		if enabled, err = strconv.ParseBool(value); err == nil {
+			scaleDown(s).Enabled = enabled
+		}
+	case ScaleDownDelayAfterAddArg:
+		if scaleDown(s).DelayAfterAdd, err = parseDuration(value); err == nil {
+			imported.originalScaleDown().DelayAfterAdd = &value
+		}
+	case ScaleDownDelayAfterDeleteArg:
+		if scaleDown(s).DelayAfterDelete, err = parseDuration(value); err == nil {
+			imported.originalScaleDown().DelayAfterDelete = &value
+		}
+	case ScaleDownDelayAfterFailureArg:
+		if scaleDown(s).DelayAfterFailure, err = parseDuration(value); err == nil {
+			imported.originalScaleDown().DelayAfterFailure = &value
+		}
+	case ScaleDownUnneededTimeArg:
+		if scaleDown(s).UnneededTime, err = parseDuration(value); err == nil {
+			imported.originalScaleDown().UnneededTime = &value
+		}
+	case ScaleDownUtilizationThresholdArg:
+		var threshold resource.Quantity
+		if threshold, err = resource.ParseQuantity(value); err == nil {
+			scaleDown(s).UtilizationThreshold = &threshold
+			imported.originalScaleDown().UtilizationThreshold = &value
+		}
+	case CordonNodeBeforeTerminatingArg:
+		var enabled bool
+		if enabled, err = strconv.ParseBool(value); err == nil {
+			mode := v2.CordonNodeBeforeTerminatingModeDisabled
+			if enabled {
+				mode = v2.CordonNodeBeforeTerminatingModeEnabled
+			}
+			scaleDown(s).CordonNodeBeforeTerminating = &mode
+		}
+	case NewPodScaleUpDelayArg:
+		if s.ScaleUp == nil {
+			s.ScaleUp = &v2.ScaleUpConfig{}
+		}
+		if s.ScaleUp.NewPodScaleUpDelay, err = parseDuration(value); err == nil {
+			imported.durations.ScaleUp = &v1.ScaleUpConfig{NewPodScaleUpDelay: &value}
+		}
+	case BalanceSimilarNodeGroupsArg:
+		s.BalanceSimilarNodeGroups, err = parseBool(value)
+	case BalancingIgnoreLabelArg:
+		s.BalancingIgnoredLabels = append(s.BalancingIgnoredLabels, value)
+	case IgnoreDaemonsetsUtilization:
+		s.IgnoreDaemonsetsUtilization, err = parseBool(value)
+	case SkipNodesWithLocalStorage:
+		s.SkipNodesWithLocalStorage, err = parseBool(value)
+	case ExpanderArg:
+		err = importExpanders(s, value)
+	case EnforceNodeGroupMinSizeArg:
+		var enabled bool
+		if enabled, err = strconv.ParseBool(value); err == nil {
+			mode := v2.EnforceNodeGroupMinSizeModeDisabled
+			if enabled {
+				mode = v2.EnforceNodeGroupMinSizeModeEnabled
+			}
+			s.EnforceNodeGroupMinSize = &mode
+		}
+	case NodesArg:
+		var group *NodeGroup
+		if group, err = parseNodeGroup(value); err == nil {
+			imported.NodeGroups = append(imported.NodeGroups, *group)
+		}
+	default:
+		return errUnsupported
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid value %q: %v", value, err)
+	}
+
+	return nil
+}
+
+// importExpanders sets the expanders of the given spec from a comma
+// separated list of cluster-autoscaler expanders.  Expanders without
+// ClusterAutoscaler equivalent are skipped and reported.
+func importExpanders(s *v2.ClusterAutoscalerSpec, value string) error {
+	var unsupported []string
+
+	for _, expander := range strings.Split(value, ",") {
+		switch expander {
+		case leastWasteFlag:
+			s.Expanders = append(s.Expanders, v2.LeastWasteExpander)
+		case priorityFlag:
+			s.Expanders = append(s.Expanders, v2.PriorityExpander)
+		case randomFlag:
+			s.Expanders = append(s.Expanders, v2.RandomExpander)
+		default:
+			unsupported = append(unsupported, expander)
+		}
+	}
+
+	if len(unsupported) > 0 {
+		return fmt.Errorf("unsupported expanders %s", strings.Join(unsupported, ","))
+	}
+
+	return nil
+}
+
+// resourceLimits returns the resource limits of the given spec, creating
+// them if needed.
+func resourceLimits(s *v2.ClusterAutoscalerSpec) *v2.ResourceLimits {
+	if s.ResourceLimits == nil {
+		s.ResourceLimits = &v2.ResourceLimits{}
+	}
+
+	return s.ResourceLimits
+}
+
+// scaleDown returns the scale down configuration of the given spec, creating
+// it if needed.  Scale down is enabled by default in the cluster-autoscaler.
+func scaleDown(s *v2.ClusterAutoscalerSpec) *v2.ScaleDownConfig {
+	if s.ScaleDown == nil {
+		s.ScaleDown = &v2.ScaleDownConfig{Enabled: true}
+	}
+
+	return s.ScaleDown
+}
+
+// originalScaleDown returns the scale down configuration of the imported
+// durations, creating it if needed.
+func (imported *ImportedArgs) originalScaleDown() *v1.ScaleDownConfig {
+	if imported.durations.ScaleDown == nil {
+		imported.durations.ScaleDown = &v1.ScaleDownConfig{}
+	}
+
+	return imported.durations.ScaleDown
+}
+
+func parseInt32(value string) (*int32, error) {
+	i, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	v := int32(i)
+	return &v, nil
+}
+
+func parseBool(value string) (*bool, error) {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &b, nil
+}
+
+func parseDuration(value string) (*metav1.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &metav1.Duration{Duration: d}, nil
+}
+
+// parseRange parses a numerical range of the form "min:max".
+func parseRange(value string) (min, max int64, err error) {
+	minValue, maxValue, found := strings.Cut(value, ":")
+	if !found {
+		return 0, 0, fmt.Errorf("expected min:max")
+	}
+
+	if min, err = strconv.ParseInt(minValue, 10, 64); err != nil {
+		return 0, 0, err
+	}
+
+	if max, err = strconv.ParseInt(maxValue, 10, 64); err != nil {
+		return 0, 0, err
+	}
+
+	return min, max, nil
+}
+
+// parseQuantityRange parses a range of the form "min:max" in multiples of
+// unit.
+func parseQuantityRange(value string, unit int64, format resource.Format) (*v2.QuantityRange, error) {
+	min, max, err := parseRange(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v2.QuantityRange{
+		Min: *resource.NewQuantity(min*unit, format),
+		Max: *resource.NewQuantity(max*unit, format),
+	}, nil
+}
+
+// parseGPULimit parses a GPU limit of the form "type:min:max".
+func parseGPULimit(value string) (*v2.GPULimit, error) {
+	i := strings.Index(value, ":")
+	if i < 0 {
+		return nil, fmt.Errorf("expected type:min:max")
+	}
+
+	min, max, err := parseRange(value[i+1:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &v2.GPULimit{Type: value[:i], Min: int32(min), Max: int32(max)}, nil
+}
+
+// parseNodeGroup parses a node group of the form "min:max:name".
+func parseNodeGroup(value string) (*NodeGroup, error) {
+	parts := strings.SplitN(value, ":", 3)
+	if len(parts) != 3 || parts[2] == "" {
+		return nil, fmt.Errorf("expected min:max:name")
+	}
+
+	min, max, err := parseRange(parts[0] + ":" + parts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return &NodeGroup{Name: parts[2], MinSize: int32(min), MaxSize: int32(max)}, nil
+}
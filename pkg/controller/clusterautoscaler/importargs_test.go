@@ -0,0 +1,102 @@
+package clusterautoscaler
+
+import (
+	"testing"
+	"time"
+
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/utils/ptr"
+)
+
+func TestImportAutoscalerArgsRoundTrip(t *testing.T) {
+	ca := NewClusterAutoscaler()
+	ca.Spec.MaxNodeProvisionTime = MaxNodeProvisionTime
+	ca.Spec.BalanceSimilarNodeGroups = ptr.To(true)
+	ca.Spec.BalancingIgnoredLabels = []string{"test/label"}
+	ca.Spec.IgnoreDaemonsetsUtilization = ptr.To(false)
+	ca.Spec.SkipNodesWithLocalStorage = ptr.To(true)
+	ca.Spec.LogVerbosity = ptr.To[int32](4)
+	ca.Spec.Expanders = []autoscalingv1.ExpanderString{autoscalingv1.PriorityExpander, autoscalingv1.LeastWasteExpander}
+	ca.Spec.StartupTaints = []string{"test/taint"}
+	ca.Spec.EnforceNodeGroupMinSize = ptr.To(autoscalingv1.EnforceNodeGroupMinSizeModeEnabled)
+	ca.Spec.ScaleDown.CordonNodeBeforeTerminating = ptr.To(autoscalingv1.CordonNodeBeforeTerminatingModeDisabled)
+
+	cfg := TestReconcilerConfig
+	args := AutoscalerArgs(toHub(t, ca), &cfg)
+
+	imported := ImportAutoscalerArgs(args)
+
+	if got := AutoscalerArgs(imported.ClusterAutoscaler, &cfg); !equality.Semantic.DeepEqual(got, args) {
+		t.Errorf("expected imported arguments to render to\n%v\ngot\n%v", args, got)
+	}
+
+	for _, arg := range imported.Unrepresented {
+		if arg.Reason != errOperatorManaged.Error() {
+			t.Errorf("unexpected unrepresented argument %+v", arg)
+		}
+	}
+}
+
+func TestImportAutoscalerArgs(t *testing.T) {
+	imported := ImportAutoscalerArgs([]string{
+		"--nodes=1:10:worker-a",
+		"--nodes", "0:5:namespace/worker-b",
+		"-v", "3",
+		"--scale-down-enabled",
+		"--scale-down-unneeded-time=5m",
+		"--expander=random,grpc",
+		"--max-nodes-total=ten",
+		"--skip-nodes-with-system-pods=false",
+		"--cloud-provider=aws",
+	})
+
+	expectedGroups := []NodeGroup{
+		{Name: "worker-a", MinSize: 1, MaxSize: 10},
+		{Name: "namespace/worker-b", MinSize: 0, MaxSize: 5},
+	}
+
+	if !equality.Semantic.DeepEqual(imported.NodeGroups, expectedGroups) {
+		t.Errorf("expected node groups %+v, got %+v", expectedGroups, imported.NodeGroups)
+	}
+
+	s := imported.ClusterAutoscaler.Spec
+
+	if s.LogVerbosity == nil || *s.LogVerbosity != 3 {
+		t.Errorf("expected log verbosity 3, got %v", s.LogVerbosity)
+	}
+
+	if s.ScaleDown == nil || !s.ScaleDown.Enabled || s.ScaleDown.UnneededTime == nil || s.ScaleDown.UnneededTime.Duration != 5*time.Minute {
+		t.Errorf("unexpected scale down configuration %+v", s.ScaleDown)
+	}
+
+	ca := &autoscalingv1.ClusterAutoscaler{}
+	if err := ca.ConvertFrom(imported.ClusterAutoscaler); err != nil {
+		t.Fatalf("failed to convert imported ClusterAutoscaler: %v", err)
+	}
+
+	if got := ca.Spec.ScaleDown.UnneededTime; got == nil || *got != "5m" {
+		t.Errorf("expected unneeded time to be kept as 5m, got %v", got)
+	}
+
+	if len(s.Expanders) != 1 || s.Expanders[0] != "Random" {
+		t.Errorf("expected the random expander only, got %v", s.Expanders)
+	}
+
+	expectedUnrepresented := []string{
+		"--expander=random,grpc",
+		"--max-nodes-total=ten",
+		"--skip-nodes-with-system-pods=false",
+		"--cloud-provider=aws",
+	}
+
+	if len(imported.Unrepresented) != len(expectedUnrepresented) {
+		t.Fatalf("expected unrepresented arguments %v, got %+v", expectedUnrepresented, imported.Unrepresented)
+	}
+
+	for i, arg := range expectedUnrepresented {
+		if imported.Unrepresented[i].Arg != arg {
+			t.Errorf("expected unrepresented argument %s, got %+v", arg, imported.Unrepresented[i])
+		}
+	}
+}
@@ -10,10 +10,37 @@ import (
 	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var (
+	// monitoringGVKs are the prometheus-operator types of the monitoring
+	// resources managed for ClusterAutoscalers.
+	monitoringGVKs = []schema.GroupVersionKind{
+		monitoringv1.SchemeGroupVersion.WithKind(monitoringv1.ServiceMonitorsKind),
+		monitoringv1.SchemeGroupVersion.WithKind(monitoringv1.PrometheusRuleKind),
+	}
+
+	// monitoringCRDNames are the names of the CRDs defining monitoringGVKs.
+	monitoringCRDNames = sets.New(
+		monitoringv1.ServiceMonitorName+"."+monitoringv1.SchemeGroupVersion.Group,
+		monitoringv1.PrometheusRuleName+"."+monitoringv1.SchemeGroupVersion.Group,
+	)
+
+	customResourceDefinitionGVK = schema.GroupVersionKind{
+		Group:   "apiextensions.k8s.io",
+		Version: "v1",
+		Kind:    "CustomResourceDefinition",
+	}
 )
 
 // createOrUpdateObjectForCA will ensure an object is created or updated according to the passed f mutate function
@@ -69,22 +96,125 @@ func (r *Reconciler) createOrUpdateAutoscalerPrometheusRule(ca *autoscalingv1.Cl
 	})
 }
 
-func (r *Reconciler) ensureAutoscalerMonitoring(ca *autoscalingv1.ClusterAutoscaler) error {
+// ensureAutoscalerMonitoring ensures the monitoring resources exist for the
+// given ClusterAutoscaler.  The ServiceMonitor and PrometheusRule are skipped
+// when the prometheus-operator types are not served by the cluster, in which
+// case false is returned.
+func (r *Reconciler) ensureAutoscalerMonitoring(ca *autoscalingv1.ClusterAutoscaler) (bool, error) {
 	if _, err := r.createOrUpdateAutoscalerService(ca); err != nil {
-		return fmt.Errorf("error ensuring cluster autoscaler service: %v", err)
+		return false, fmt.Errorf("error ensuring cluster autoscaler service: %v", err)
+	}
+
+	available, err := r.monitoringAvailable()
+	if err != nil {
+		return false, fmt.Errorf("error checking for monitoring types: %v", err)
+	}
+
+	if !available {
+		klog.V(2).Infof("Monitoring types are not available, skipping ServiceMonitor and PrometheusRule")
+		return false, nil
+	}
+
+	if err := r.ensureMonitoringWatches(); err != nil {
+		return false, fmt.Errorf("error watching monitoring types: %v", err)
 	}
 
 	if _, err := r.createOrUpdateAutoscalerServiceMonitor(ca); err != nil {
-		return fmt.Errorf("error ensuring cluster autoscaler serviceMonitor: %v", err)
+		return false, fmt.Errorf("error ensuring cluster autoscaler serviceMonitor: %v", err)
 	}
 
 	if _, err := r.createOrUpdateAutoscalerPrometheusRule(ca); err != nil {
-		return fmt.Errorf("error ensuring cluster autoscaler prometheusRule: %v", err)
+		return false, fmt.Errorf("error ensuring cluster autoscaler prometheusRule: %v", err)
 	}
 
+	return true, nil
+}
+
+// monitoringAvailable returns true if the prometheus-operator types are
+// served by the cluster.
+func (r *Reconciler) monitoringAvailable() (bool, error) {
+	for _, gvk := range monitoringGVKs {
+		if _, err := r.client.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+			if meta.IsNoMatchError(err) {
+				return false, nil
+			}
+
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// ensureMonitoringWatches starts watching the monitoring resources owned by
+// ClusterAutoscalers, unless already done.  This must only be called once the
+// monitoring types are served, as the watches otherwise fail to sync.
+func (r *Reconciler) ensureMonitoringWatches() error {
+	if r.monitoringWatched || r.controller == nil {
+		return nil
+	}
+
+	if err := r.controller.Watch(source.Kind(r.cache, &monitoringv1.ServiceMonitor{}, handler.TypedEnqueueRequestForOwner[*monitoringv1.ServiceMonitor](
+		r.scheme,
+		r.client.RESTMapper(),
+		&autoscalingv1.ClusterAutoscaler{},
+		handler.OnlyControllerOwner(),
+	))); err != nil {
+		return err
+	}
+
+	if err := r.controller.Watch(source.Kind(r.cache, &monitoringv1.PrometheusRule{}, handler.TypedEnqueueRequestForOwner[*monitoringv1.PrometheusRule](
+		r.scheme,
+		r.client.RESTMapper(),
+		&autoscalingv1.ClusterAutoscaler{},
+		handler.OnlyControllerOwner(),
+	))); err != nil {
+		return err
+	}
+
+	r.monitoringWatched = true
+	klog.Info("Started watching monitoring types")
+
 	return nil
 }
 
+// monitoringCRDRequest is used with handler.EnqueueRequestsFromMapFunc to
+// enqueue a reconcile request for the singleton ClusterAutoscaler when one of
+// the monitoring CRDs changes, e.g. is installed or becomes established.
+func (r *Reconciler) monitoringCRDRequest(_ context.Context, crd *metav1.PartialObjectMetadata) []reconcile.Request {
+	if !monitoringCRDNames.Has(crd.GetName()) {
+		return nil
+	}
+
+	klog.V(2).Infof("Queuing reconcile for ClusterAutoscaler %s after monitoring CRD %s change.", r.config.Name, crd.GetName())
+
+	return []reconcile.Request{{NamespacedName: client.ObjectKey{Name: r.config.Name}}}
+}
+
+// updateMonitoringCondition sets the MonitoringAvailable condition on the
+// given ClusterAutoscaler's status, updating it if changed.
+func (r *Reconciler) updateMonitoringCondition(ca *autoscalingv1.ClusterAutoscaler, available bool) error {
+	condition := metav1.Condition{
+		Type:               autoscalingv1.MonitoringAvailableCondition,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: ca.GetGeneration(),
+		Reason:             autoscalingv1.MonitoringReconciledReason,
+		Message:            "ServiceMonitor and PrometheusRule are up to date",
+	}
+
+	if !available {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = autoscalingv1.MonitoringTypesNotInstalledReason
+		condition.Message = "The prometheus-operator ServiceMonitor and PrometheusRule types are not served by the cluster"
+	}
+
+	if !meta.SetStatusCondition(&ca.Status.Conditions, condition) {
+		return nil
+	}
+
+	return r.client.Status().Update(context.TODO(), ca)
+}
+
 func (r *Reconciler) AutoscalerService(ca *autoscalingv1.ClusterAutoscaler) *corev1.Service {
 	namespacedName := r.AutoscalerName(ca)
 	return &corev1.Service{
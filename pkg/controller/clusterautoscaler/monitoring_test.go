@@ -4,9 +4,13 @@ import (
 	"context"
 	"testing"
 
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
@@ -186,3 +190,95 @@ func TestCreateOrUpdateAutoscalerPrometheusRule(t *testing.T) {
 		}
 	}
 }
+
+func TestEnsureAutoscalerMonitoring(t *testing.T) {
+	ca := NewClusterAutoscaler()
+
+	// A RESTMapper without the prometheus-operator types, as on clusters
+	// where they are not installed.
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Service"), meta.RESTScopeNamespace)
+	mapper.Add(autoscalingv1.SchemeGroupVersion.WithKind("ClusterAutoscaler"), meta.RESTScopeRoot)
+
+	testCases := []struct {
+		label             string
+		reconciler        *Reconciler
+		expectedAvailable bool
+		expectedStatus    metav1.ConditionStatus
+		expectedReason    string
+	}{
+		{
+			label:             "monitoring types available",
+			reconciler:        newFakeReconciler(ca.DeepCopy()),
+			expectedAvailable: true,
+			expectedStatus:    metav1.ConditionTrue,
+			expectedReason:    autoscalingv1.MonitoringReconciledReason,
+		},
+		{
+			label:             "monitoring types not installed",
+			reconciler:        newFakeReconcilerWithRESTMapper(mapper, ca.DeepCopy()),
+			expectedAvailable: false,
+			expectedStatus:    metav1.ConditionFalse,
+			expectedReason:    autoscalingv1.MonitoringTypesNotInstalledReason,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			r := tc.reconciler
+
+			current := &autoscalingv1.ClusterAutoscaler{}
+			if err := r.client.Get(context.TODO(), client.ObjectKeyFromObject(ca), current); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			available, err := r.ensureAutoscalerMonitoring(current)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if available != tc.expectedAvailable {
+				t.Errorf("expected available: %v, got: %v", tc.expectedAvailable, available)
+			}
+
+			// The Service is created regardless.
+			if err := r.client.Get(context.TODO(), r.AutoscalerName(current), &corev1.Service{}); err != nil {
+				t.Errorf("expected service to be created: %v", err)
+			}
+
+			if err := r.updateMonitoringCondition(current, available); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			fresh := &autoscalingv1.ClusterAutoscaler{}
+			if err := r.client.Get(context.TODO(), client.ObjectKeyFromObject(ca), fresh); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			cond := meta.FindStatusCondition(fresh.Status.Conditions, autoscalingv1.MonitoringAvailableCondition)
+			if cond == nil {
+				t.Fatalf("expected %s condition", autoscalingv1.MonitoringAvailableCondition)
+			}
+
+			if cond.Status != tc.expectedStatus || cond.Reason != tc.expectedReason {
+				t.Errorf("expected %s/%s, got %s/%s", tc.expectedStatus, tc.expectedReason, cond.Status, cond.Reason)
+			}
+		})
+	}
+}
+
+func TestMonitoringCRDRequest(t *testing.T) {
+	r := newFakeReconciler()
+
+	crd := &metav1.PartialObjectMetadata{}
+
+	crd.SetName("servicemonitors.monitoring.coreos.com")
+	if reqs := r.monitoringCRDRequest(context.TODO(), crd); len(reqs) != 1 || reqs[0].Name != r.config.Name {
+		t.Errorf("expected a request for %s, got: %v", r.config.Name, reqs)
+	}
+
+	crd.SetName("machinesets.machine.openshift.io")
+	if reqs := r.monitoringCRDRequest(context.TODO(), crd); len(reqs) != 0 {
+		t.Errorf("expected no requests, got: %v", reqs)
+	}
+}
@@ -1,27 +1,53 @@
 package clusterautoscaler
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net"
+	"net/url"
+	"strconv"
 
+	"k8s.io/klog/v2"
 	"k8s.io/utils/ptr"
 
+	configv1 "github.com/openshift/api/config/v1"
 	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
-// createOrUpdateAutoscalerServiceMonitor will create or update a serviceMonitor
-// for the given ClusterAutoscaler custom resource instance.
-func (r *Reconciler) createOrUpdateAutoscalerNetworkPolicies(ca *autoscalingv1.ClusterAutoscaler) (result []controllerutil.OperationResult, err error) {
+const (
+	// defaultAPIServerPort is the API server port allowed when the port can
+	// not be determined from the Infrastructure status.
+	defaultAPIServerPort = 6443
+
+	// defaultCloudEndpointPort is the port allowed for cloud service endpoints
+	// which do not specify a port.
+	defaultCloudEndpointPort = 443
+)
+
+// createOrUpdateAutoscalerNetworkPolicies will create or update the
+// networkpolicies for the given ClusterAutoscaler custom resource instance,
+// in the given cluster state.
+// Policies previously created for the ClusterAutoscaler which are no longer
+// expected, e.g. because the policies are unmanaged, are removed.
+func (r *Reconciler) createOrUpdateAutoscalerNetworkPolicies(ca *autoscalingv1.ClusterAutoscaler, state ClusterState) (result []controllerutil.OperationResult, err error) {
+	defer func() {
+		err = errors.Join(err, r.pruneAutoscalerNetworkPolicies(ca, state))
+	}()
+
 	// If the policies objects don't exist yet on the API server, they will be used to create the objects. But if they do exist, they'll be
 	// overwritten with the API server's version of the object by controllerutil.CreateOrUpdate() (which is called by createOrUpdateObjectForCA()).
-	policies := r.AutoscalerNetworkPolicies(ca)
+	policies := r.AutoscalerNetworkPolicies(ca, state)
 	// This version is going to stay untouched
-	desired := r.AutoscalerNetworkPolicies(ca)
+	desired := r.AutoscalerNetworkPolicies(ca, state)
 	for i, policy := range policies {
 		r, e := r.createOrUpdateObjectForCA(ca, &policy, func() error {
 			// This mutate function only gets called if the object already exists on the API server
@@ -36,6 +62,209 @@ func (r *Reconciler) createOrUpdateAutoscalerNetworkPolicies(ca *autoscalingv1.C
 	return
 }
 
+// pruneAutoscalerNetworkPolicies deletes the networkpolicies controlled by the
+// given ClusterAutoscaler which are not expected any longer.
+func (r *Reconciler) pruneAutoscalerNetworkPolicies(ca *autoscalingv1.ClusterAutoscaler, state ClusterState) error {
+	expected := sets.New[string]()
+	for _, policy := range r.AutoscalerNetworkPolicies(ca, state) {
+		expected.Insert(policy.Name)
+	}
+
+	policies := &networkingv1.NetworkPolicyList{}
+	if err := r.client.List(context.TODO(), policies, client.InNamespace(r.config.Namespace)); err != nil {
+		return fmt.Errorf("unable to list networkpolicies: %w", err)
+	}
+
+	var errs error
+	for i := range policies.Items {
+		policy := &policies.Items[i]
+		if expected.Has(policy.Name) || !metav1.IsControlledBy(policy, ca) {
+			continue
+		}
+
+		if err := r.client.Delete(context.TODO(), policy); err != nil && !apierrors.IsNotFound(err) {
+			errs = errors.Join(errs, err)
+			continue
+		}
+
+		klog.Infof("Deleted networkpolicy %s/%s", policy.Namespace, policy.Name)
+	}
+
+	return errs
+}
+
+// networkPoliciesManaged indicates whether the networkpolicies of the given
+// ClusterAutoscaler are managed by the operator.
+func networkPoliciesManaged(ca *autoscalingv1.ClusterAutoscaler) bool {
+	return ca.Spec.NetworkPolicy == nil || ca.Spec.NetworkPolicy.Mode != autoscalingv1.NetworkPolicyModeUnmanaged
+}
+
+// parseEndpoint returns the host and port of the endpoint with the given URL.
+// When the URL does not specify a port, the default port of its scheme is
+// returned, or zero if the scheme has no well-known port.
+func parseEndpoint(rawURL string) (string, int32, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if u.Hostname() == "" {
+		return "", 0, fmt.Errorf("missing host in URL %q", rawURL)
+	}
+
+	if u.Port() != "" {
+		port, err := strconv.ParseUint(u.Port(), 10, 16)
+		if err != nil {
+			return "", 0, fmt.Errorf("invalid port in URL %q: %w", rawURL, err)
+		}
+
+		return u.Hostname(), int32(port), nil
+	}
+
+	switch u.Scheme {
+	case "https":
+		return u.Hostname(), 443, nil
+	case "http":
+		return u.Hostname(), 80, nil
+	}
+
+	return u.Hostname(), 0, nil
+}
+
+// endpointEgressRule returns an egress rule allowing TCP traffic to the given
+// host and port.  If the host is an IPv4 or IPv6 address, traffic is only
+// allowed to that address.  Hostnames may resolve to addresses of either
+// family on dual-stack clusters, so traffic to the port is allowed to any
+// destination in that case.
+func endpointEgressRule(host string, port int32) networkingv1.NetworkPolicyEgressRule {
+	protocolTCP := corev1.ProtocolTCP
+
+	rule := networkingv1.NetworkPolicyEgressRule{
+		Ports: []networkingv1.NetworkPolicyPort{
+			makePort(&protocolTCP, intstr.FromInt32(port), 0),
+		},
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		cidr := ip.String() + "/128"
+		if ip.To4() != nil {
+			cidr = ip.To4().String() + "/32"
+		}
+
+		rule.To = []networkingv1.NetworkPolicyPeer{
+			{IPBlock: &networkingv1.IPBlock{CIDR: cidr}},
+		}
+	}
+
+	return rule
+}
+
+// endpointEgressRules returns the egress rules allowing traffic to the
+// endpoints with the given URLs.  Endpoints without a port use the given
+// default port.  Duplicate endpoints are only allowed once, and endpoints
+// which can not be parsed are skipped.
+func endpointEgressRules(urls []string, defaultPort int32) []networkingv1.NetworkPolicyEgressRule {
+	var rules []networkingv1.NetworkPolicyEgressRule
+	seen := sets.New[string]()
+
+	for _, rawURL := range urls {
+		if rawURL == "" {
+			continue
+		}
+
+		host, port, err := parseEndpoint(rawURL)
+		if err != nil {
+			klog.Warningf("Ignoring endpoint in networkpolicies: %v", err)
+			continue
+		}
+
+		if port == 0 {
+			port = defaultPort
+		}
+
+		key := net.JoinHostPort(host, strconv.Itoa(int(port)))
+		if seen.Has(key) {
+			continue
+		}
+		seen.Insert(key)
+
+		rules = append(rules, endpointEgressRule(host, port))
+	}
+
+	return rules
+}
+
+// apiServerEgressRule returns the egress rule allowing traffic to the API
+// server port, as advertised by the internal API server URL of the given
+// Infrastructure status.  Traffic is allowed to any destination, as it is
+// sent to the kubernetes service address and translated to the API server
+// endpoints before the networkpolicies are evaluated, so neither the host of
+// the URL nor the service address match the evaluated destination.
+func apiServerEgressRule(infrastructure *configv1.InfrastructureStatus) networkingv1.NetworkPolicyEgressRule {
+	protocolTCP := corev1.ProtocolTCP
+	port := int32(defaultAPIServerPort)
+
+	if infrastructure != nil && infrastructure.APIServerInternalURL != "" {
+		_, urlPort, err := parseEndpoint(infrastructure.APIServerInternalURL)
+		switch {
+		case err != nil:
+			klog.Warningf("Unable to parse internal API server URL, allowing default port %d: %v", defaultAPIServerPort, err)
+		case urlPort != 0:
+			port = urlPort
+		}
+	}
+
+	return networkingv1.NetworkPolicyEgressRule{
+		Ports: []networkingv1.NetworkPolicyPort{
+			makePort(&protocolTCP, intstr.FromInt32(port), 0),
+		},
+	}
+}
+
+// proxyEgressRules returns the egress rules allowing traffic to the given
+// cluster-wide proxy.
+func proxyEgressRules(proxy *configv1.ProxyStatus) []networkingv1.NetworkPolicyEgressRule {
+	if proxy == nil {
+		return nil
+	}
+
+	return endpointEgressRules([]string{proxy.HTTPProxy, proxy.HTTPSProxy}, 0)
+}
+
+// cloudEndpointEgressRules returns the egress rules allowing traffic to the
+// custom cloud service endpoints of the given Infrastructure status.
+func cloudEndpointEgressRules(infrastructure *configv1.InfrastructureStatus) []networkingv1.NetworkPolicyEgressRule {
+	if infrastructure == nil || infrastructure.PlatformStatus == nil {
+		return nil
+	}
+
+	var urls []string
+	status := infrastructure.PlatformStatus
+
+	switch status.Type {
+	case configv1.AWSPlatformType:
+		if status.AWS != nil {
+			for _, endpoint := range status.AWS.ServiceEndpoints {
+				urls = append(urls, endpoint.URL)
+			}
+		}
+	case configv1.IBMCloudPlatformType:
+		if status.IBMCloud != nil {
+			for _, endpoint := range status.IBMCloud.ServiceEndpoints {
+				urls = append(urls, endpoint.URL)
+			}
+		}
+	case configv1.PowerVSPlatformType:
+		if status.PowerVS != nil {
+			for _, endpoint := range status.PowerVS.ServiceEndpoints {
+				urls = append(urls, endpoint.URL)
+			}
+		}
+	}
+
+	return endpointEgressRules(urls, defaultCloudEndpointPort)
+}
+
 // makePort is a helper funciton to create a NetworkPolicyPort
 func makePort(proto *corev1.Protocol,
 	port intstr.IntOrString,
@@ -55,8 +284,13 @@ func makePort(proto *corev1.Protocol,
 }
 
 // AutoscalerNetworkPolicies returns the expected networkpolicies belonging
-// to the given ClusterAutoscaler.
-func (r *Reconciler) AutoscalerNetworkPolicies(ca *autoscalingv1.ClusterAutoscaler) []networkingv1.NetworkPolicy {
+// to the given ClusterAutoscaler, in the given cluster state.
+// No networkpolicies are expected if they are not managed by the operator.
+func (r *Reconciler) AutoscalerNetworkPolicies(ca *autoscalingv1.ClusterAutoscaler, state ClusterState) []networkingv1.NetworkPolicy {
+	if !networkPoliciesManaged(ca) {
+		return nil
+	}
+
 	protocolTCP := corev1.ProtocolTCP
 	protocolUDP := corev1.ProtocolUDP
 	var policies []networkingv1.NetworkPolicy
@@ -146,11 +380,7 @@ func (r *Reconciler) AutoscalerNetworkPolicies(ca *autoscalingv1.ClusterAutoscal
 				},
 			},
 			Egress: []networkingv1.NetworkPolicyEgressRule{
-				{
-					Ports: []networkingv1.NetworkPolicyPort{
-						makePort(&protocolTCP, intstr.FromInt32(6443), 0),
-					},
-				},
+				apiServerEgressRule(state.Infrastructure),
 			},
 			PolicyTypes: []networkingv1.PolicyType{
 				networkingv1.PolicyTypeEgress,
@@ -215,5 +445,89 @@ func (r *Reconciler) AutoscalerNetworkPolicies(ca *autoscalingv1.ClusterAutoscal
 			},
 		},
 	})
+	// Cluster Autoscaler should be able to reach the cluster-wide proxy
+	if rules := proxyEgressRules(state.Proxy); len(rules) > 0 {
+		policies = append(policies, r.autoscalerEgressPolicy(ca, "allow-egress-to-proxy", rules))
+	}
+	// Cluster Autoscaler should be able to reach custom cloud service endpoints
+	if rules := cloudEndpointEgressRules(state.Infrastructure); len(rules) > 0 {
+		policies = append(policies, r.autoscalerEgressPolicy(ca, "allow-egress-to-cloud-endpoints", rules))
+	}
+	// Additional traffic allowed by the user
+	if policy := r.autoscalerAdditionalPolicy(ca); policy != nil {
+		policies = append(policies, *policy)
+	}
 	return policies
 }
+
+// autoscalerPolicySelector returns the selector matching the pods of the given
+// ClusterAutoscaler.
+func autoscalerPolicySelector(ca *autoscalingv1.ClusterAutoscaler) metav1.LabelSelector {
+	return metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			"cluster-autoscaler": ca.Name,
+			"k8s-app":            "cluster-autoscaler",
+		},
+	}
+}
+
+// autoscalerEgressPolicy returns a networkpolicy with the given name suffix
+// allowing egress traffic matching the given rules.
+func (r *Reconciler) autoscalerEgressPolicy(ca *autoscalingv1.ClusterAutoscaler, suffix string, rules []networkingv1.NetworkPolicyEgressRule) networkingv1.NetworkPolicy {
+	namespacedName := r.AutoscalerName(ca)
+
+	return networkingv1.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: networkingv1.SchemeGroupVersion.String(),
+			Kind:       "NetworkPolicy",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s", namespacedName.Name, suffix),
+			Namespace: namespacedName.Namespace,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: autoscalerPolicySelector(ca),
+			Egress:      rules,
+			PolicyTypes: []networkingv1.PolicyType{
+				networkingv1.PolicyTypeEgress,
+			},
+		},
+	}
+}
+
+// autoscalerAdditionalPolicy returns the networkpolicy allowing the additional
+// traffic configured on the given ClusterAutoscaler, or nil if none is.
+func (r *Reconciler) autoscalerAdditionalPolicy(ca *autoscalingv1.ClusterAutoscaler) *networkingv1.NetworkPolicy {
+	config := ca.Spec.NetworkPolicy
+	if config == nil || (len(config.AdditionalEgress) == 0 && len(config.AdditionalIngress) == 0) {
+		return nil
+	}
+
+	namespacedName := r.AutoscalerName(ca)
+
+	policy := &networkingv1.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: networkingv1.SchemeGroupVersion.String(),
+			Kind:       "NetworkPolicy",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-allow-additional", namespacedName.Name),
+			Namespace: namespacedName.Namespace,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: autoscalerPolicySelector(ca),
+		},
+	}
+
+	if len(config.AdditionalEgress) > 0 {
+		policy.Spec.Egress = config.AdditionalEgress
+		policy.Spec.PolicyTypes = append(policy.Spec.PolicyTypes, networkingv1.PolicyTypeEgress)
+	}
+
+	if len(config.AdditionalIngress) > 0 {
+		policy.Spec.Ingress = config.AdditionalIngress
+		policy.Spec.PolicyTypes = append(policy.Spec.PolicyTypes, networkingv1.PolicyTypeIngress)
+	}
+
+	return policy
+}
@@ -5,9 +5,14 @@ import (
 	"sort"
 	"testing"
 
+	configv1 "github.com/openshift/api/config/v1"
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
@@ -15,7 +20,7 @@ func TestCreateOrUpdateAutoscalerNetworkPolicies(t *testing.T) {
 	r := newFakeReconciler()
 	ca := NewClusterAutoscaler()
 
-	expected := r.AutoscalerNetworkPolicies(ca)
+	expected := r.AutoscalerNetworkPolicies(ca, ClusterState{})
 	for i := range expected {
 		if err := controllerutil.SetControllerReference(ca, &expected[i], r.scheme); err != nil {
 			t.Fatalf("Unexpected error: %v", err)
@@ -64,7 +69,7 @@ func TestCreateOrUpdateAutoscalerNetworkPolicies(t *testing.T) {
 			r = newFakeReconciler()
 		}
 
-		op, err := r.createOrUpdateAutoscalerNetworkPolicies(ca)
+		op, err := r.createOrUpdateAutoscalerNetworkPolicies(ca, ClusterState{})
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
 		}
@@ -92,3 +97,157 @@ func TestCreateOrUpdateAutoscalerNetworkPolicies(t *testing.T) {
 		}
 	}
 }
+
+func TestParseEndpoint(t *testing.T) {
+	testCases := []struct {
+		url          string
+		expectedHost string
+		expectedPort int32
+		expectError  bool
+	}{
+		{url: "https://api-int.example.com:6443", expectedHost: "api-int.example.com", expectedPort: 6443},
+		{url: "https://api-int.example.com", expectedHost: "api-int.example.com", expectedPort: 443},
+		{url: "http://proxy.example.com", expectedHost: "proxy.example.com", expectedPort: 80},
+		{url: "https://10.0.0.1:6443", expectedHost: "10.0.0.1", expectedPort: 6443},
+		{url: "https://[fd00::1]:6443", expectedHost: "fd00::1", expectedPort: 6443},
+		{url: "tcp://endpoint.example.com", expectedHost: "endpoint.example.com", expectedPort: 0},
+		{url: "https://api-int.example.com:99999", expectError: true},
+		{url: "not a url", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.url, func(t *testing.T) {
+			host, port, err := parseEndpoint(tc.url)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedHost, host)
+			assert.Equal(t, tc.expectedPort, port)
+		})
+	}
+}
+
+func TestAPIServerEgressRule(t *testing.T) {
+	protocolTCP := corev1.ProtocolTCP
+
+	testCases := []struct {
+		name         string
+		url          string
+		expectedPort int32
+	}{
+		{name: "No URL", expectedPort: defaultAPIServerPort},
+		{name: "Hostname", url: "https://api-int.example.com:6443", expectedPort: 6443},
+		{name: "Custom port", url: "https://api-int.example.com:443", expectedPort: 443},
+		{name: "IPv4", url: "https://10.0.0.1:6443", expectedPort: 6443},
+		{name: "IPv6", url: "https://[fd00::1]:6443", expectedPort: 6443},
+		{name: "Invalid URL", url: "https://api-int.example.com:bad", expectedPort: defaultAPIServerPort},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule := apiServerEgressRule(&configv1.InfrastructureStatus{APIServerInternalURL: tc.url})
+			assert.Equal(t, []networkingv1.NetworkPolicyPort{
+				makePort(&protocolTCP, intstr.FromInt32(tc.expectedPort), 0),
+			}, rule.Ports)
+
+			// The API server is reached through the kubernetes service, so
+			// the destination is never pinned to the address in the URL.
+			assert.Empty(t, rule.To)
+		})
+	}
+}
+
+func TestProxyAndCloudEndpointNetworkPolicies(t *testing.T) {
+	ca := NewClusterAutoscaler()
+	r := newFakeReconciler()
+	name := r.AutoscalerName(ca).Name
+
+	state := ClusterState{}
+	state.Proxy = &configv1.ProxyStatus{
+		HTTPProxy:  "http://[fd00::10]:3128",
+		HTTPSProxy: "http://[fd00::10]:3128",
+	}
+	state.Infrastructure = &configv1.InfrastructureStatus{
+		PlatformStatus: &configv1.PlatformStatus{
+			Type: configv1.AWSPlatformType,
+			AWS: &configv1.AWSPlatformStatus{
+				ServiceEndpoints: []configv1.AWSServiceEndpoint{
+					{Name: "ec2", URL: "https://ec2.example.com"},
+					{Name: "elasticloadbalancing", URL: "https://elb.example.com:8443"},
+				},
+			},
+		},
+	}
+
+	policies := map[string]networkingv1.NetworkPolicy{}
+	for _, policy := range r.AutoscalerNetworkPolicies(ca, state) {
+		policies[policy.Name] = policy
+	}
+
+	proxy, ok := policies[name+"-allow-egress-to-proxy"]
+	if assert.True(t, ok, "missing proxy networkpolicy") {
+		// Both proxies are the same endpoint, which is only allowed once.
+		assert.Len(t, proxy.Spec.Egress, 1)
+		assert.Equal(t, intstr.FromInt32(3128), *proxy.Spec.Egress[0].Ports[0].Port)
+		assert.Equal(t, "fd00::10/128", proxy.Spec.Egress[0].To[0].IPBlock.CIDR)
+	}
+
+	cloud, ok := policies[name+"-allow-egress-to-cloud-endpoints"]
+	if assert.True(t, ok, "missing cloud endpoints networkpolicy") {
+		assert.Len(t, cloud.Spec.Egress, 2)
+		assert.Equal(t, intstr.FromInt32(443), *cloud.Spec.Egress[0].Ports[0].Port)
+		assert.Equal(t, intstr.FromInt32(8443), *cloud.Spec.Egress[1].Ports[0].Port)
+	}
+}
+
+func TestNetworkPolicyConfig(t *testing.T) {
+	protocolTCP := corev1.ProtocolTCP
+
+	ca := NewClusterAutoscaler()
+	r := newFakeReconciler(ca)
+	name := r.AutoscalerName(ca).Name
+
+	ca.Spec.NetworkPolicy = &autoscalingv1.NetworkPolicyConfig{
+		AdditionalEgress: []networkingv1.NetworkPolicyEgressRule{
+			{
+				Ports: []networkingv1.NetworkPolicyPort{
+					makePort(&protocolTCP, intstr.FromInt32(8200), 0),
+				},
+			},
+		},
+	}
+
+	_, err := r.createOrUpdateAutoscalerNetworkPolicies(ca, ClusterState{})
+	assert.NoError(t, err)
+
+	policies := &networkingv1.NetworkPolicyList{}
+	assert.NoError(t, r.client.List(context.TODO(), policies))
+	assert.Len(t, policies.Items, len(r.AutoscalerNetworkPolicies(ca, ClusterState{})))
+
+	additional := &networkingv1.NetworkPolicy{}
+	key := r.AutoscalerName(ca)
+	key.Name = name + "-allow-additional"
+	assert.NoError(t, r.client.Get(context.TODO(), key, additional))
+	assert.Equal(t, []networkingv1.PolicyType{networkingv1.PolicyTypeEgress}, additional.Spec.PolicyTypes)
+	assert.Equal(t, ca.Spec.NetworkPolicy.AdditionalEgress, additional.Spec.Egress)
+
+	// Removing the additional rules removes the additional policy.
+	ca.Spec.NetworkPolicy.AdditionalEgress = nil
+
+	_, err = r.createOrUpdateAutoscalerNetworkPolicies(ca, ClusterState{})
+	assert.NoError(t, err)
+	assert.NoError(t, r.client.List(context.TODO(), policies))
+	assert.Len(t, policies.Items, len(r.AutoscalerNetworkPolicies(ca, ClusterState{})))
+
+	// Unmanaged policies are removed entirely.
+	ca.Spec.NetworkPolicy.Mode = autoscalingv1.NetworkPolicyModeUnmanaged
+
+	result, err := r.createOrUpdateAutoscalerNetworkPolicies(ca, ClusterState{})
+	assert.NoError(t, err)
+	assert.Empty(t, result)
+	assert.NoError(t, r.client.List(context.TODO(), policies))
+	assert.Empty(t, policies.Items)
+}
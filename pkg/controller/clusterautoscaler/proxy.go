@@ -0,0 +1,233 @@
+package clusterautoscaler
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// proxyName is the name of the cluster-wide Proxy configuration object.
+	proxyName = "cluster"
+
+	// InjectTrustedCABundleLabel is the label used to request that the
+	// cluster-network-operator injects the cluster trusted CA bundle into a
+	// ConfigMap.
+	InjectTrustedCABundleLabel = "config.openshift.io/inject-trusted-cabundle"
+
+	// TrustedCABundleHashAnnotation is the pod template annotation holding a
+	// hash of the injected trusted CA bundle.  Changes to the bundle change the
+	// annotation, which rolls out the cluster-autoscaler deployment.
+	TrustedCABundleHashAnnotation = "autoscaling.openshift.io/trusted-ca-bundle-hash"
+
+	trustedCABundleKey       = "ca-bundle.crt"
+	trustedCABundleVolume    = "trusted-ca-bundle"
+	trustedCABundleMountPath = "/etc/pki/ca-trust/extracted/pem"
+	trustedCABundleFileName  = "tls-ca-bundle.pem"
+
+	httpProxyEnvVar  = "HTTP_PROXY"
+	httpsProxyEnvVar = "HTTPS_PROXY"
+	noProxyEnvVar    = "NO_PROXY"
+)
+
+// ensureProxyConfig observes the cluster-wide proxy configuration and
+// ensures the trusted CA bundle ConfigMap exists for the given
+// ClusterAutoscaler.  The proxy status and the hash of the injected bundle
+// are set in the given cluster state.
+func (r *Reconciler) ensureProxyConfig(ca *autoscalingv1.ClusterAutoscaler, state *ClusterState) error {
+	proxy, err := r.getProxyStatus()
+	if err != nil {
+		return err
+	}
+
+	bundle, err := r.createOrUpdateTrustedCABundle(ca)
+	if err != nil {
+		return fmt.Errorf("error ensuring trusted CA bundle configmap: %v", err)
+	}
+
+	state.Proxy = proxy
+	state.TrustedCABundleHash = ""
+	if len(bundle) > 0 {
+		state.TrustedCABundleHash = fmt.Sprintf("%x", sha256.Sum256([]byte(bundle)))
+	}
+
+	return nil
+}
+
+// getProxyStatus returns the status of the cluster-wide Proxy configuration, or
+// nil if no proxy is configured.
+func (r *Reconciler) getProxyStatus() (*configv1.ProxyStatus, error) {
+	if r.config.Standalone {
+		return nil, nil
+	}
+
+	proxy := &configv1.Proxy{}
+
+	if err := r.client.Get(context.TODO(), client.ObjectKey{Name: proxyName}, proxy); err != nil {
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("unable to get proxy object: %w", err)
+	}
+
+	return &proxy.Status, nil
+}
+
+// createOrUpdateTrustedCABundle ensures the ConfigMap into which the trusted CA
+// bundle is injected exists, and returns the bundle currently injected, if any.
+func (r *Reconciler) createOrUpdateTrustedCABundle(ca *autoscalingv1.ClusterAutoscaler) (string, error) {
+	desired := r.AutoscalerTrustedCABundle(ca)
+
+	_, err := r.createOrUpdateObjectForCA(ca, desired, func() error {
+		// The data is owned by the cluster-network-operator, only the
+		// injection label is managed here.
+		labels := desired.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+
+		labels[InjectTrustedCABundleLabel] = "true"
+		desired.SetLabels(labels)
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return desired.Data[trustedCABundleKey], nil
+}
+
+// AutoscalerTrustedCABundleName returns the name of the trusted CA bundle
+// ConfigMap belonging to the given ClusterAutoscaler.
+func (r *Reconciler) AutoscalerTrustedCABundleName(ca *autoscalingv1.ClusterAutoscaler) string {
+	return fmt.Sprintf("%s-trusted-ca-bundle", r.AutoscalerName(ca).Name)
+}
+
+// AutoscalerTrustedCABundle returns the expected trusted CA bundle ConfigMap
+// belonging to the given ClusterAutoscaler.
+func (r *Reconciler) AutoscalerTrustedCABundle(ca *autoscalingv1.ClusterAutoscaler) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      r.AutoscalerTrustedCABundleName(ca),
+			Namespace: r.config.Namespace,
+			Labels: map[string]string{
+				"k8s-app":                  "cluster-autoscaler",
+				InjectTrustedCABundleLabel: "true",
+			},
+		},
+	}
+}
+
+// proxyEnv returns the proxy environment variables for the cluster-autoscaler
+// container based on the given cluster-wide proxy status.
+func proxyEnv(proxy *configv1.ProxyStatus) []corev1.EnvVar {
+	if proxy == nil {
+		return nil
+	}
+
+	var env []corev1.EnvVar
+
+	vars := []struct {
+		name  string
+		value string
+	}{
+		{httpProxyEnvVar, proxy.HTTPProxy},
+		{httpsProxyEnvVar, proxy.HTTPSProxy},
+		{noProxyEnvVar, proxy.NoProxy},
+	}
+
+	for _, v := range vars {
+		if v.value != "" {
+			env = append(env, corev1.EnvVar{Name: v.name, Value: v.value})
+		}
+	}
+
+	return env
+}
+
+// addTrustedCABundle mounts the trusted CA bundle into the cluster-autoscaler
+// container of the given pod spec, if a bundle has been injected, as told by
+// the given hash.
+func (r *Reconciler) addTrustedCABundle(ca *autoscalingv1.ClusterAutoscaler, spec *corev1.PodSpec, hash string) {
+	if hash == "" {
+		return
+	}
+
+	spec.Volumes = append(spec.Volumes, corev1.Volume{
+		Name: trustedCABundleVolume,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: r.AutoscalerTrustedCABundleName(ca),
+				},
+				Items: []corev1.KeyToPath{
+					{
+						Key:  trustedCABundleKey,
+						Path: trustedCABundleFileName,
+					},
+				},
+			},
+		},
+	})
+
+	spec.Containers[0].VolumeMounts = append(spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      trustedCABundleVolume,
+		MountPath: trustedCABundleMountPath,
+		ReadOnly:  true,
+	})
+}
+
+// setTrustedCABundleHash records the given hash of the currently injected
+// trusted CA bundle on the given pod template metadata.
+func setTrustedCABundleHash(obj metav1.Object, hash string) {
+	annotations := obj.GetAnnotations()
+
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	if hash == "" {
+		delete(annotations, TrustedCABundleHashAnnotation)
+	} else {
+		annotations[TrustedCABundleHashAnnotation] = hash
+	}
+
+	obj.SetAnnotations(annotations)
+}
+
+// trustedCABundleHashMatches indicates whether the given pod template metadata
+// carries the given hash of the currently injected trusted CA bundle.
+func trustedCABundleHashMatches(obj metav1.Object, hash string) bool {
+	return obj.GetAnnotations()[TrustedCABundleHashAnnotation] == hash
+}
+
+// proxyRequest is used with handler.EnqueueRequestsFromMapFunc to enqueue a
+// reconcile request for the singleton ClusterAutoscaler when the cluster-wide
+// proxy configuration changes.
+func (r *Reconciler) proxyRequest(_ context.Context, proxy *configv1.Proxy) []reconcile.Request {
+	if proxy.GetName() != proxyName {
+		return nil
+	}
+
+	name := r.configName()
+
+	klog.V(2).Infof("Queuing reconcile for ClusterAutoscaler %s after proxy change.", name)
+
+	return []reconcile.Request{{NamespacedName: client.ObjectKey{Name: name}}}
+}
@@ -0,0 +1,140 @@
+package clusterautoscaler
+
+import (
+	"context"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newProxy(status configv1.ProxyStatus) *configv1.Proxy {
+	return &configv1.Proxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: proxyName,
+		},
+		Status: status,
+	}
+}
+
+func TestProxyEnv(t *testing.T) {
+	testCases := []struct {
+		name     string
+		proxy    *configv1.Proxy
+		expected []corev1.EnvVar
+	}{
+		{
+			name:     "No proxy object",
+			expected: nil,
+		},
+		{
+			name:     "Proxy object without a proxy configured",
+			proxy:    newProxy(configv1.ProxyStatus{}),
+			expected: nil,
+		},
+		{
+			name: "Proxy object with all values set",
+			proxy: newProxy(configv1.ProxyStatus{
+				HTTPProxy:  "http://proxy.example.com:3128",
+				HTTPSProxy: "https://proxy.example.com:3129",
+				NoProxy:    ".cluster.local,10.0.0.0/16",
+			}),
+			expected: []corev1.EnvVar{
+				{Name: httpProxyEnvVar, Value: "http://proxy.example.com:3128"},
+				{Name: httpsProxyEnvVar, Value: "https://proxy.example.com:3129"},
+				{Name: noProxyEnvVar, Value: ".cluster.local,10.0.0.0/16"},
+			},
+		},
+		{
+			name: "Proxy object with only HTTPS proxy set",
+			proxy: newProxy(configv1.ProxyStatus{
+				HTTPSProxy: "https://proxy.example.com:3129",
+			}),
+			expected: []corev1.EnvVar{
+				{Name: httpsProxyEnvVar, Value: "https://proxy.example.com:3129"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ca := NewClusterAutoscaler()
+
+			r := newFakeReconciler()
+			if tc.proxy != nil {
+				r = newFakeReconciler(tc.proxy)
+			}
+
+			state := ClusterState{}
+			assert.NoError(t, r.ensureProxyConfig(ca, &state))
+			assert.Equal(t, tc.expected, proxyEnv(state.Proxy))
+
+			spec, err := r.AutoscalerPodSpec(ca, state)
+			assert.NoError(t, err)
+
+			env := spec.Containers[0].Env
+			for _, e := range tc.expected {
+				assert.Contains(t, env, e)
+			}
+		})
+	}
+}
+
+func TestTrustedCABundle(t *testing.T) {
+	ca := NewClusterAutoscaler()
+	r := newFakeReconciler()
+
+	// The ConfigMap is created with the injection label, but nothing is
+	// mounted until a bundle has been injected.
+	state := ClusterState{}
+	assert.NoError(t, r.ensureProxyConfig(ca, &state))
+
+	cm := &corev1.ConfigMap{}
+	cmKey := client.ObjectKey{Namespace: TestNamespace, Name: r.AutoscalerTrustedCABundleName(ca)}
+	assert.NoError(t, r.client.Get(context.TODO(), cmKey, cm))
+	assert.Equal(t, "true", cm.Labels[InjectTrustedCABundleLabel])
+	spec, err := r.AutoscalerPodSpec(ca, state)
+	assert.NoError(t, err)
+	assert.Empty(t, spec.Volumes)
+
+	// Simulate the bundle being injected.
+	cm.Data = map[string]string{trustedCABundleKey: "bundle-1"}
+	assert.NoError(t, r.client.Update(context.TODO(), cm))
+	assert.NoError(t, r.ensureProxyConfig(ca, &state))
+
+	spec, err = r.AutoscalerPodSpec(ca, state)
+	assert.NoError(t, err)
+	assert.Len(t, spec.Volumes, 1)
+	assert.Equal(t, r.AutoscalerTrustedCABundleName(ca), spec.Volumes[0].ConfigMap.Name)
+	assert.Contains(t, spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      trustedCABundleVolume,
+		MountPath: trustedCABundleMountPath,
+		ReadOnly:  true,
+	})
+
+	deployment, err := r.AutoscalerDeployment(ca, state)
+	assert.NoError(t, err)
+
+	firstHash := deployment.Spec.Template.Annotations[TrustedCABundleHashAnnotation]
+	assert.NotEmpty(t, firstHash)
+	assert.NotContains(t, deployment.Annotations, TrustedCABundleHashAnnotation)
+
+	// A change of the bundle content must change the pod template so that the
+	// deployment is rolled out.
+	assert.NoError(t, r.client.Create(context.TODO(), deployment))
+
+	cm.Data[trustedCABundleKey] = "bundle-2"
+	assert.NoError(t, r.client.Update(context.TODO(), cm))
+	assert.NoError(t, r.ensureProxyConfig(ca, &state))
+	assert.NoError(t, r.UpdateAutoscaler(ca, state))
+
+	updated, err := r.GetAutoscaler(ca)
+	assert.NoError(t, err)
+
+	secondHash := updated.Spec.Template.Annotations[TrustedCABundleHashAnnotation]
+	assert.NotEmpty(t, secondHash)
+	assert.NotEqual(t, firstHash, secondHash)
+}
@@ -0,0 +1,117 @@
+package clusterautoscaler
+
+import (
+	"context"
+
+	configv1 "github.com/openshift/api/config/v1"
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// provisioningRequestGroup is the API group of the ProvisioningRequests
+// handled by the cluster-autoscaler.
+const provisioningRequestGroup = "autoscaling.x-k8s.io"
+
+// RelatedObjects returns references to the objects owned by the reconciler
+// for the configured ClusterAutoscaler, and to the ProvisioningRequests
+// handled by the cluster-autoscaler if enabled.  The monitoring objects are
+// only referenced if the prometheus-operator types are served.  Nothing is
+// returned if there is no ClusterAutoscaler.
+func (r *Reconciler) RelatedObjects() []configv1.ObjectReference {
+	// This is called outside of reconciles.
+	r.configMu.Lock()
+	defer r.configMu.Unlock()
+
+	ca := &autoscalingv1.ClusterAutoscaler{}
+	if err := r.client.Get(context.TODO(), client.ObjectKey{Name: r.config.Name}, ca); err != nil {
+		if !errors.IsNotFound(err) {
+			klog.Errorf("Failed to get ClusterAutoscaler for related objects: %v", err)
+		}
+
+		return nil
+	}
+
+	name := r.AutoscalerName(ca)
+
+	relatedObjects := []configv1.ObjectReference{
+		{
+			Group:     appsv1.SchemeGroupVersion.Group,
+			Resource:  "deployments",
+			Name:      name.Name,
+			Namespace: name.Namespace,
+		},
+		{
+			Resource:  "services",
+			Name:      name.Name,
+			Namespace: name.Namespace,
+		},
+		{
+			Resource:  "configmaps",
+			Name:      r.AutoscalerTrustedCABundleName(ca),
+			Namespace: name.Namespace,
+		},
+	}
+
+	// The ServiceMonitor and PrometheusRule are only created when the
+	// prometheus-operator types are served.
+	available, err := r.monitoringAvailable()
+	if err != nil {
+		klog.Errorf("Failed to check for monitoring types for related objects: %v", err)
+	}
+
+	if available {
+		relatedObjects = append(relatedObjects,
+			configv1.ObjectReference{
+				Group:     monitoringv1.SchemeGroupVersion.Group,
+				Resource:  monitoringv1.ServiceMonitorName,
+				Name:      name.Name,
+				Namespace: name.Namespace,
+			},
+			configv1.ObjectReference{
+				Group:     monitoringv1.SchemeGroupVersion.Group,
+				Resource:  monitoringv1.PrometheusRuleName,
+				Name:      name.Name,
+				Namespace: name.Namespace,
+			},
+		)
+	}
+
+	// The set of networkpolicies depends on the ClusterAutoscaler and the
+	// cluster configuration, so list those actually controlled by it.
+	policies := &networkingv1.NetworkPolicyList{}
+	if err := r.client.List(context.TODO(), policies, client.InNamespace(r.config.Namespace)); err != nil {
+		klog.Errorf("Failed to list networkpolicies for related objects: %v", err)
+	} else {
+		for i := range policies.Items {
+			policy := &policies.Items[i]
+			if !metav1.IsControlledBy(policy, ca) {
+				continue
+			}
+
+			relatedObjects = append(relatedObjects, configv1.ObjectReference{
+				Group:     networkingv1.SchemeGroupVersion.Group,
+				Resource:  "networkpolicies",
+				Name:      policy.Name,
+				Namespace: policy.Namespace,
+			})
+		}
+	}
+
+	// ProvisioningRequests are created by users in any namespace, so they
+	// are referenced without a name or namespace, i.e. all of them, as
+	// supported by must-gather.
+	if ProvisioningRequestsEnabled(r.config.FeatureGateAccessor) {
+		relatedObjects = append(relatedObjects, configv1.ObjectReference{
+			Group:    provisioningRequestGroup,
+			Resource: "provisioningrequests",
+		})
+	}
+
+	return relatedObjects
+}
@@ -0,0 +1,108 @@
+package clusterautoscaler
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func TestRelatedObjects(t *testing.T) {
+	// ClusterAutoscalers are cluster scoped.
+	ca := NewClusterAutoscaler()
+	ca.Namespace = ""
+
+	owned := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cluster-autoscaler-test-default-deny",
+			Namespace: TestNamespace,
+		},
+	}
+
+	if err := controllerutil.SetControllerReference(ca, owned, newFakeReconciler().scheme); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	unowned := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "machine-api-operator",
+			Namespace: TestNamespace,
+		},
+	}
+
+	autoscalerObjects := []configv1.ObjectReference{
+		{Group: "apps", Resource: "deployments", Name: "cluster-autoscaler-test", Namespace: TestNamespace},
+		{Resource: "services", Name: "cluster-autoscaler-test", Namespace: TestNamespace},
+		{Resource: "configmaps", Name: "cluster-autoscaler-test-trusted-ca-bundle", Namespace: TestNamespace},
+		{Group: "monitoring.coreos.com", Resource: "servicemonitors", Name: "cluster-autoscaler-test", Namespace: TestNamespace},
+		{Group: "monitoring.coreos.com", Resource: "prometheusrules", Name: "cluster-autoscaler-test", Namespace: TestNamespace},
+		{Group: "networking.k8s.io", Resource: "networkpolicies", Name: "cluster-autoscaler-test-default-deny", Namespace: TestNamespace},
+	}
+
+	// A RESTMapper without the prometheus-operator types, as on clusters
+	// where they are not installed.
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(autoscalingv1.SchemeGroupVersion.WithKind("ClusterAutoscaler"), meta.RESTScopeRoot)
+	mapper.Add(networkingv1.SchemeGroupVersion.WithKind("NetworkPolicy"), meta.RESTScopeNamespace)
+
+	withoutMonitoring := []configv1.ObjectReference{}
+	for _, ref := range autoscalerObjects {
+		if ref.Group != "monitoring.coreos.com" {
+			withoutMonitoring = append(withoutMonitoring, ref)
+		}
+	}
+
+	testCases := []struct {
+		label        string
+		objects      []runtime.Object
+		mapper       meta.RESTMapper
+		featureGates featuregates.FeatureGateAccess
+		expected     []configv1.ObjectReference
+	}{
+		{
+			label: "no ClusterAutoscaler",
+		},
+		{
+			label:    "ClusterAutoscaler",
+			objects:  []runtime.Object{ca, owned, unowned},
+			expected: autoscalerObjects,
+		},
+		{
+			label:    "monitoring types not available",
+			objects:  []runtime.Object{ca, owned, unowned},
+			mapper:   mapper,
+			expected: withoutMonitoring,
+		},
+		{
+			label:   "ProvisioningRequests enabled",
+			objects: []runtime.Object{ca, owned, unowned},
+			featureGates: featuregates.NewHardcodedFeatureGateAccess(
+				[]configv1.FeatureGateName{provisioningRequestFGName},
+				[]configv1.FeatureGateName{},
+			),
+			expected: append(autoscalerObjects, configv1.ObjectReference{
+				Group:    "autoscaling.x-k8s.io",
+				Resource: "provisioningrequests",
+			}),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			r := newFakeReconcilerWithRESTMapper(tc.mapper, tc.objects...)
+			r.config.FeatureGateAccessor = tc.featureGates
+
+			got := r.RelatedObjects()
+			if !equality.Semantic.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %+v, got: %+v", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package clusterautoscaler
+
+import (
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Render returns the objects the reconciler would create for the given
+// ClusterAutoscaler, built without a cluster.  The platform type is taken
+// from the given configuration, and as no proxy or Infrastructure status is
+// observed, the corresponding networkpolicies are not rendered.  Owner
+// references are not set.
+func Render(ca *autoscalingv1.ClusterAutoscaler, cfg Config) ([]client.Object, error) {
+	r := &Reconciler{config: cfg}
+	r.config.platformType = cfg.PlatformType
+
+	// No cluster state is observed.
+	state := ClusterState{}
+
+	deployment, err := r.AutoscalerDeployment(ca, state)
+	if err != nil {
+		return nil, err
+	}
+
+	objs := []client.Object{
+		deployment,
+		r.AutoscalerService(ca),
+		r.AutoscalerServiceMonitor(ca),
+		r.AutoscalerPrometheusRule(ca),
+	}
+
+	policies := r.AutoscalerNetworkPolicies(ca, state)
+	for i := range policies {
+		objs = append(objs, &policies[i])
+	}
+
+	return objs, nil
+}
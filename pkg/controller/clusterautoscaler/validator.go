@@ -9,21 +9,47 @@ import (
 	"time"
 
 	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	"github.com/openshift/cluster-autoscaler-operator/pkg/metrics"
 	util "github.com/openshift/cluster-autoscaler-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/klog/v2"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
+const (
+	// PriorityExpanderConfigMapName is the name of the ConfigMap the
+	// cluster-autoscaler reads the priority expander configuration from.
+	PriorityExpanderConfigMapName = "cluster-autoscaler-priority-expander"
+
+	// maxPodPriorityThreshold is the highest meaningful pod priority cutoff.
+	// It is the priority of the system-node-critical priority class, above
+	// which every pod would be considered expendable.
+	maxPodPriorityThreshold = 2000001000
+)
+
 // Validator validates ClusterAutoscaler resources.
 type Validator struct {
 	client  client.Client
 	decoder admission.Decoder
 
 	clusterAutoscalerName string
+
+	// reader is used to read the live objects a ClusterAutoscaler is
+	// checked against at admission, namespace is the namespace of the
+	// MachineAutoscalers, and strict indicates whether problems found are
+	// errors rather than warnings.
+	reader    client.Reader
+	namespace string
+	strict    bool
 }
 
 // NewValidator returns a new Validator configured with the given
@@ -33,6 +59,7 @@ func NewValidator(name string, client client.Client, scheme *runtime.Scheme) *Va
 		client:                client,
 		decoder:               admission.NewDecoder(scheme),
 		clusterAutoscalerName: name,
+		reader:                client,
 	}
 }
 
@@ -42,6 +69,7 @@ func NewValidator(name string, client client.Client, scheme *runtime.Scheme) *Va
 func (v *Validator) Validate(ca *autoscalingv1.ClusterAutoscaler) util.ValidatorResponse {
 	errs := []error{}
 	warns := []string{}
+	reasons := []string{}
 
 	if ca == nil {
 		err := errors.New("ClusterAutoscaler is nil")
@@ -51,11 +79,13 @@ func (v *Validator) Validate(ca *autoscalingv1.ClusterAutoscaler) util.Validator
 	if ca.GetName() != v.clusterAutoscalerName {
 		errs = append(errs, fmt.Errorf("Name %q is invalid, only %q is allowed",
 			ca.GetName(), v.clusterAutoscalerName))
+		reasons = append(reasons, "InvalidName")
 	}
 
 	if limits := ca.Spec.ResourceLimits; limits != nil {
 		if aggErr := v.validateResourceLimits(limits); aggErr != nil {
 			errs = append(errs, aggErr.Errors()...)
+			reasons = append(reasons, "InvalidResourceLimits")
 		}
 
 		if gpus := limits.GPUS; gpus != nil {
@@ -66,16 +96,208 @@ func (v *Validator) Validate(ca *autoscalingv1.ClusterAutoscaler) util.Validator
 	if scaleDown := ca.Spec.ScaleDown; scaleDown != nil {
 		if aggErr := v.validateScaleDownConfig(scaleDown); aggErr != nil {
 			errs = append(errs, aggErr.Errors()...)
+			reasons = append(reasons, "InvalidScaleDown")
 		}
 	}
 
 	if scaleUp := ca.Spec.ScaleUp; scaleUp != nil {
 		if aggErr := v.validateScaleUpConfig(scaleUp); aggErr != nil {
 			errs = append(errs, aggErr.Errors()...)
+			reasons = append(reasons, "InvalidScaleUp")
+		}
+	}
+
+	return util.ValidatorResponse{Warnings: warns, Errors: utilerrors.NewAggregate(errs), Reasons: reasons}
+}
+
+// ValidateSpecFields validates the spec fields of the given ClusterAutoscaler
+// which were not validated by earlier versions of the operator.  The previous
+// version of the ClusterAutoscaler, if any, is given as old: problems already
+// present in it are returned as warnings, so that existing ClusterAutoscalers
+// are still admitted and reconciled, and only new problems are errors.
+func (v *Validator) ValidateSpecFields(ca, old *autoscalingv1.ClusterAutoscaler) util.ValidatorResponse {
+	if ca == nil || ca.GetDeletionTimestamp() != nil {
+		return util.ValidatorResponse{}
+	}
+
+	existing := sets.New[string]()
+	if old != nil {
+		for _, err := range v.validateSpecFields(&old.Spec, field.NewPath("spec")) {
+			existing.Insert(err.Error())
+		}
+	}
+
+	errs := []error{}
+	warns := []string{}
+
+	for _, err := range v.validateSpecFields(&ca.Spec, field.NewPath("spec")) {
+		if existing.Has(err.Error()) {
+			warns = append(warns, err.Error())
+		} else {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) == 0 {
+		return util.ValidatorResponse{Warnings: warns}
+	}
+
+	return util.ValidatorResponse{Warnings: warns, Errors: utilerrors.NewAggregate(errs), Reasons: []string{"InvalidField"}}
+}
+
+// validateSpecFields validates the ClusterAutoscalerSpec fields which are not
+// covered by the resource limits, scale down and scale up validation.
+func (v *Validator) validateSpecFields(spec *autoscalingv1.ClusterAutoscalerSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if spec.MaxPodGracePeriod != nil && *spec.MaxPodGracePeriod < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxPodGracePeriod"), *spec.MaxPodGracePeriod,
+			"must be greater than or equal to 0"))
+	}
+
+	if spec.MaxNodeProvisionTime != "" {
+		path := fldPath.Child("maxNodeProvisionTime")
+		if duration, err := time.ParseDuration(spec.MaxNodeProvisionTime); err != nil {
+			allErrs = append(allErrs, field.Invalid(path, spec.MaxNodeProvisionTime, err.Error()))
+		} else if duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(path, spec.MaxNodeProvisionTime, "must be a positive duration"))
+		}
+	}
+
+	if spec.PodPriorityThreshold != nil && *spec.PodPriorityThreshold > maxPodPriorityThreshold {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("podPriorityThreshold"), *spec.PodPriorityThreshold,
+			fmt.Sprintf("must be less than or equal to %d, otherwise all pods are considered expendable", maxPodPriorityThreshold)))
+	}
+
+	if spec.LogVerbosity != nil && *spec.LogVerbosity < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("logVerbosity"), *spec.LogVerbosity,
+			"must be greater than or equal to 0"))
+	}
+
+	allErrs = append(allErrs, validateLabelKeys(spec.BalancingIgnoredLabels, fldPath.Child("balancingIgnoredLabels"))...)
+	allErrs = append(allErrs, validateLabelKeys(spec.StartupTaints, fldPath.Child("startupTaints"))...)
+	allErrs = append(allErrs, validateExpanders(spec.Expanders, fldPath.Child("expanders"))...)
+
+	if spec.EnforceNodeGroupMinSize != nil {
+		switch *spec.EnforceNodeGroupMinSize {
+		case autoscalingv1.EnforceNodeGroupMinSizeModeEnabled, autoscalingv1.EnforceNodeGroupMinSizeModeDisabled:
+		default:
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("enforceNodeGroupMinSize"), *spec.EnforceNodeGroupMinSize,
+				[]autoscalingv1.EnforceNodeGroupMinSizeMode{autoscalingv1.EnforceNodeGroupMinSizeModeEnabled, autoscalingv1.EnforceNodeGroupMinSizeModeDisabled}))
+		}
+	}
+
+	if sd := spec.ScaleDown; sd != nil && sd.CordonNodeBeforeTerminating != nil {
+		switch *sd.CordonNodeBeforeTerminating {
+		case autoscalingv1.CordonNodeBeforeTerminatingModeEnabled, autoscalingv1.CordonNodeBeforeTerminatingModeDisabled:
+		default:
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("scaleDown", "cordonNodeBeforeTerminating"), *sd.CordonNodeBeforeTerminating,
+				[]autoscalingv1.CordonNodeBeforeTerminatingMode{autoscalingv1.CordonNodeBeforeTerminatingModeEnabled, autoscalingv1.CordonNodeBeforeTerminatingModeDisabled}))
+		}
+	}
+
+	if rl := spec.ResourceLimits; rl != nil {
+		types := sets.New[string]()
+		for i, gpu := range rl.GPUS {
+			path := fldPath.Child("resourceLimits", "gpus").Index(i).Child("type")
+			if types.Has(gpu.Type) {
+				allErrs = append(allErrs, field.Duplicate(path, gpu.Type))
+			}
+			types.Insert(gpu.Type)
+		}
+	}
+
+	if np := spec.NetworkPolicy; np != nil {
+		switch np.Mode {
+		case "", autoscalingv1.NetworkPolicyModeManaged, autoscalingv1.NetworkPolicyModeUnmanaged:
+		default:
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("networkPolicy", "mode"), np.Mode,
+				[]autoscalingv1.NetworkPolicyMode{autoscalingv1.NetworkPolicyModeManaged, autoscalingv1.NetworkPolicyModeUnmanaged}))
+		}
+	}
+
+	return allErrs
+}
+
+// validateLabelKeys validates that the given values are valid label keys.
+func validateLabelKeys(keys []string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	seen := sets.New[string]()
+	for i, key := range keys {
+		for _, msg := range validation.IsQualifiedName(key) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), key, msg))
+		}
+
+		if seen.Has(key) {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Index(i), key))
+		}
+		seen.Insert(key)
+	}
+
+	return allErrs
+}
+
+// validateExpanders validates that the given expanders are supported and
+// listed at most once.
+func validateExpanders(expanders []autoscalingv1.ExpanderString, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	supported := []autoscalingv1.ExpanderString{
+		autoscalingv1.LeastWasteExpander,
+		autoscalingv1.PriorityExpander,
+		autoscalingv1.RandomExpander,
+	}
+
+	seen := sets.New[autoscalingv1.ExpanderString]()
+	for i, expander := range expanders {
+		switch expander {
+		case autoscalingv1.LeastWasteExpander, autoscalingv1.PriorityExpander, autoscalingv1.RandomExpander:
+		default:
+			allErrs = append(allErrs, field.NotSupported(fldPath.Index(i), expander, supported))
+		}
+
+		if seen.Has(expander) {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Index(i), expander))
+		}
+		seen.Insert(expander)
+	}
+
+	return allErrs
+}
+
+// ValidatePriorityExpander validates that the priority expander configuration
+// exists if the given ClusterAutoscaler uses the priority expander.  This is
+// only checked at admission, so that existing ClusterAutoscalers continue to
+// be reconciled while the configuration is being created.
+func (v *Validator) ValidatePriorityExpander(ca *autoscalingv1.ClusterAutoscaler) util.ValidatorResponse {
+	if ca == nil || ca.GetDeletionTimestamp() != nil {
+		return util.ValidatorResponse{}
+	}
+
+	for i, expander := range ca.Spec.Expanders {
+		if expander != autoscalingv1.PriorityExpander {
+			continue
+		}
+
+		cm := &corev1.ConfigMap{}
+		key := client.ObjectKey{Namespace: v.namespace, Name: PriorityExpanderConfigMapName}
+
+		err := v.reader.Get(context.TODO(), key, cm)
+		if apierrors.IsNotFound(err) {
+			fieldErr := field.Invalid(field.NewPath("spec", "expanders").Index(i), expander,
+				fmt.Sprintf("the priority expander requires the %s/%s ConfigMap", v.namespace, PriorityExpanderConfigMapName))
+			return util.ValidatorResponse{Errors: utilerrors.NewAggregate([]error{fieldErr}), Reasons: []string{"MissingPriorityExpanderConfig"}}
+		}
+
+		if err != nil {
+			klog.Warningf("Unable to get priority expander configuration for validation: %v", err)
 		}
+
+		break
 	}
 
-	return util.ValidatorResponse{Warnings: warns, Errors: utilerrors.NewAggregate(errs)}
+	return util.ValidatorResponse{}
 }
 
 // validateGPUTypes validates that the GPU limits Type fields are properly formatted.
@@ -197,23 +419,94 @@ func (v *Validator) validateScaleUpConfig(su *autoscalingv1.ScaleUpConfig) utile
 	return utilerrors.NewAggregate(errs)
 }
 
+// ValidateCrossResource validates the resource limits of the given
+// ClusterAutoscaler against the live MachineAutoscalers and nodes.  Problems
+// are returned as warnings, or as errors in strict mode.  The previous
+// version of the ClusterAutoscaler, if any, is given as old: in strict mode,
+// problems are only errors if the change lowers the node limit, so that
+// changes leaving an existing problem as is, or reducing it, are still
+// admitted.
+func (v *Validator) ValidateCrossResource(ca, old *autoscalingv1.ClusterAutoscaler) util.ValidatorResponse {
+	if ca == nil || ca.GetDeletionTimestamp() != nil {
+		return util.ValidatorResponse{}
+	}
+
+	if ca.Spec.ResourceLimits == nil || ca.Spec.ResourceLimits.MaxNodesTotal == nil {
+		return util.ValidatorResponse{}
+	}
+
+	maxNodesTotal := *ca.Spec.ResourceLimits.MaxNodesTotal
+	worse := old == nil || old.Spec.ResourceLimits == nil || old.Spec.ResourceLimits.MaxNodesTotal == nil ||
+		maxNodesTotal < *old.Spec.ResourceLimits.MaxNodesTotal
+
+	problems := []string{}
+	reasons := []string{}
+
+	mas := &autoscalingv1.MachineAutoscalerList{}
+	if err := v.reader.List(context.TODO(), mas, client.InNamespace(v.namespace)); err != nil {
+		klog.Warningf("Unable to list MachineAutoscalers for validation: %v", err)
+	} else {
+		minReplicas := map[string]int32{}
+		for _, ma := range mas.Items {
+			if ma.GetDeletionTimestamp() == nil {
+				minReplicas[ma.GetName()] = ma.Spec.MinReplicas
+			}
+		}
+
+		if msg := util.MinReplicasExceedMaxNodesTotal(maxNodesTotal, minReplicas); msg != "" {
+			problems = append(problems, msg)
+			reasons = append(reasons, "MinReplicasExceedMaxNodesTotal")
+		}
+	}
+
+	nodes := &metav1.PartialObjectMetadataList{}
+	nodes.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("NodeList"))
+
+	if err := v.reader.List(context.TODO(), nodes); err != nil {
+		klog.Warningf("Unable to list nodes for validation: %v", err)
+	} else if count := len(nodes.Items); count > int(maxNodesTotal) {
+		problems = append(problems, fmt.Sprintf("ResourceLimits.MaxNodesTotal (%d) is below the current number of nodes (%d), the cluster will not be scaled up",
+			maxNodesTotal, count))
+		reasons = append(reasons, "MaxNodesTotalBelowNodeCount")
+	}
+
+	return util.CrossResourceResponse(v.strict && worse, problems, reasons)
+}
+
 // Handle handles HTTP requests for admission webhook servers.
 func (v *Validator) Handle(ctx context.Context, req admission.Request) admission.Response {
 	ca := &autoscalingv1.ClusterAutoscaler{}
 
 	if err := v.decoder.Decode(req, ca); err != nil {
+		metrics.RecordAdmissionDecision(metrics.ResourceClusterAutoscaler, metrics.AdmissionErrored)
 		return admission.Errored(http.StatusBadRequest, err)
 	}
 
 	klog.Infof("Validation webhook called for ClusterAutoscaler: %s", ca.GetName())
 
+	var old *autoscalingv1.ClusterAutoscaler
+	if len(req.OldObject.Raw) > 0 {
+		old = &autoscalingv1.ClusterAutoscaler{}
+		if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			metrics.RecordAdmissionDecision(metrics.ResourceClusterAutoscaler, metrics.AdmissionErrored)
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+	}
+
 	var admRes admission.Response
 
-	valRes := v.Validate(ca)
+	valRes := util.MergeValidatorResponses(v.Validate(ca), v.ValidateSpecFields(ca, old),
+		v.ValidatePriorityExpander(ca), v.ValidateCrossResource(ca, old))
 	if valRes.IsValid() {
 		admRes = admission.Allowed("ClusterAutoscaler valid")
+		metrics.RecordAdmissionDecision(metrics.ResourceClusterAutoscaler, metrics.AdmissionAllowed)
 	} else {
 		admRes = admission.Denied(valRes.Errors.Error())
+		metrics.RecordAdmissionDecision(metrics.ResourceClusterAutoscaler, metrics.AdmissionDenied)
+
+		for _, reason := range valRes.Reasons {
+			metrics.RecordValidationFailure(metrics.ResourceClusterAutoscaler, reason)
+		}
 	}
 
 	if len(valRes.Warnings) > 0 {
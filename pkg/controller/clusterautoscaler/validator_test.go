@@ -1,11 +1,18 @@
 package clusterautoscaler
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	util "github.com/openshift/cluster-autoscaler-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/utils/pointer"
+	"k8s.io/utils/ptr"
 	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -102,6 +109,17 @@ func TestValidate(t *testing.T) {
 				return ca
 			},
 		},
+		{
+			// The CRD pattern accepts any unit, e.g. days.
+			label:            "ClusterAutoscaler has ScaleDown durations with unsupported units",
+			expectedOk:       false,
+			expectedWarnings: false,
+			caFunc: func() *autoscalingv1.ClusterAutoscaler {
+				ca := ca.DeepCopy()
+				ca.Spec.ScaleDown.DelayAfterAdd = pointer.String("1d")
+				return ca
+			},
+		},
 		{
 			label:            "ClusterAutoscaler has negative ScaleDown durations",
 			expectedOk:       false,
@@ -153,11 +171,156 @@ func TestValidate(t *testing.T) {
 				return ca
 			},
 		},
+		{
+			label:            "ClusterAutoscaler has invalid maxNodeProvisionTime",
+			expectedOk:       false,
+			expectedWarnings: false,
+			caFunc: func() *autoscalingv1.ClusterAutoscaler {
+				ca := ca.DeepCopy()
+				ca.Spec.MaxNodeProvisionTime = "15"
+				return ca
+			},
+		},
+		{
+			label:            "ClusterAutoscaler has zero maxNodeProvisionTime",
+			expectedOk:       false,
+			expectedWarnings: false,
+			caFunc: func() *autoscalingv1.ClusterAutoscaler {
+				ca := ca.DeepCopy()
+				ca.Spec.MaxNodeProvisionTime = "0s"
+				return ca
+			},
+		},
+		{
+			label:            "ClusterAutoscaler has negative maxPodGracePeriod",
+			expectedOk:       false,
+			expectedWarnings: false,
+			caFunc: func() *autoscalingv1.ClusterAutoscaler {
+				ca := ca.DeepCopy()
+				ca.Spec.MaxPodGracePeriod = pointer.Int32(-1)
+				return ca
+			},
+		},
+		{
+			label:            "ClusterAutoscaler has too high podPriorityThreshold",
+			expectedOk:       false,
+			expectedWarnings: false,
+			caFunc: func() *autoscalingv1.ClusterAutoscaler {
+				ca := ca.DeepCopy()
+				ca.Spec.PodPriorityThreshold = pointer.Int32(maxPodPriorityThreshold + 1)
+				return ca
+			},
+		},
+		{
+			label:            "ClusterAutoscaler has negative logVerbosity",
+			expectedOk:       false,
+			expectedWarnings: false,
+			caFunc: func() *autoscalingv1.ClusterAutoscaler {
+				ca := ca.DeepCopy()
+				ca.Spec.LogVerbosity = pointer.Int32(-1)
+				return ca
+			},
+		},
+		{
+			label:            "ClusterAutoscaler has invalid balancingIgnoredLabels",
+			expectedOk:       false,
+			expectedWarnings: false,
+			caFunc: func() *autoscalingv1.ClusterAutoscaler {
+				ca := ca.DeepCopy()
+				ca.Spec.BalancingIgnoredLabels = []string{"valid.example.com/label", "not a label"}
+				return ca
+			},
+		},
+		{
+			label:            "ClusterAutoscaler has invalid startupTaints",
+			expectedOk:       false,
+			expectedWarnings: false,
+			caFunc: func() *autoscalingv1.ClusterAutoscaler {
+				ca := ca.DeepCopy()
+				ca.Spec.StartupTaints = []string{"-invalid"}
+				return ca
+			},
+		},
+		{
+			label:            "ClusterAutoscaler has duplicate startupTaints",
+			expectedOk:       false,
+			expectedWarnings: false,
+			caFunc: func() *autoscalingv1.ClusterAutoscaler {
+				ca := ca.DeepCopy()
+				ca.Spec.StartupTaints = []string{"example.com/taint", "example.com/taint"}
+				return ca
+			},
+		},
+		{
+			label:            "ClusterAutoscaler has duplicate expanders",
+			expectedOk:       false,
+			expectedWarnings: false,
+			caFunc: func() *autoscalingv1.ClusterAutoscaler {
+				ca := ca.DeepCopy()
+				ca.Spec.Expanders = []autoscalingv1.ExpanderString{autoscalingv1.RandomExpander, autoscalingv1.RandomExpander}
+				return ca
+			},
+		},
+		{
+			label:            "ClusterAutoscaler has unknown expander",
+			expectedOk:       false,
+			expectedWarnings: false,
+			caFunc: func() *autoscalingv1.ClusterAutoscaler {
+				ca := ca.DeepCopy()
+				ca.Spec.Expanders = []autoscalingv1.ExpanderString{"MostPods"}
+				return ca
+			},
+		},
+		{
+			label:            "ClusterAutoscaler has invalid enforceNodeGroupMinSize",
+			expectedOk:       false,
+			expectedWarnings: false,
+			caFunc: func() *autoscalingv1.ClusterAutoscaler {
+				ca := ca.DeepCopy()
+				mode := autoscalingv1.EnforceNodeGroupMinSizeMode("Sometimes")
+				ca.Spec.EnforceNodeGroupMinSize = &mode
+				return ca
+			},
+		},
+		{
+			label:            "ClusterAutoscaler has duplicate GPU types",
+			expectedOk:       false,
+			expectedWarnings: false,
+			caFunc: func() *autoscalingv1.ClusterAutoscaler {
+				ca := ca.DeepCopy()
+				ca.Spec.ResourceLimits.GPUS = append(ca.Spec.ResourceLimits.GPUS, ca.Spec.ResourceLimits.GPUS[0])
+				return ca
+			},
+		},
+		{
+			label:            "ClusterAutoscaler has invalid networkPolicy mode",
+			expectedOk:       false,
+			expectedWarnings: false,
+			caFunc: func() *autoscalingv1.ClusterAutoscaler {
+				ca := ca.DeepCopy()
+				ca.Spec.NetworkPolicy = &autoscalingv1.NetworkPolicyConfig{Mode: "Sometimes"}
+				return ca
+			},
+		},
+		{
+			label:            "ClusterAutoscaler has valid label keys and expanders",
+			expectedOk:       true,
+			expectedWarnings: false,
+			caFunc: func() *autoscalingv1.ClusterAutoscaler {
+				ca := ca.DeepCopy()
+				ca.Spec.MaxNodeProvisionTime = "15m"
+				ca.Spec.BalancingIgnoredLabels = []string{"topology.example.com/zone", "lifecycle"}
+				ca.Spec.StartupTaints = []string{"node.example.com/starting"}
+				ca.Spec.Expanders = []autoscalingv1.ExpanderString{autoscalingv1.PriorityExpander, autoscalingv1.LeastWasteExpander}
+				return ca
+			},
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.label, func(t *testing.T) {
-			res := validator.Validate(tc.caFunc())
+			ca := tc.caFunc()
+			res := util.MergeValidatorResponses(validator.Validate(ca), validator.ValidateSpecFields(ca, nil))
 
 			if !res.IsValid() && len(res.Errors.Errors()) == 0 {
 				t.Error("validation failed, but err is nil")
@@ -173,3 +336,284 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+// TestValidateSpecFieldsUpdate validates that problems already present in
+// the previous version of a ClusterAutoscaler are only warnings, so that
+// existing ClusterAutoscalers keep being admitted and reconciled.
+func TestValidateSpecFieldsUpdate(t *testing.T) {
+	validator := NewValidator("test", fakeclient.NewClientBuilder().Build(), scheme.Scheme)
+
+	old := NewClusterAutoscaler()
+	old.Spec.MaxNodeProvisionTime = "0s"
+	old.Spec.BalancingIgnoredLabels = []string{"lifecycle", "lifecycle"}
+
+	testCases := []struct {
+		label            string
+		caFunc           func() *autoscalingv1.ClusterAutoscaler
+		old              *autoscalingv1.ClusterAutoscaler
+		expectedOk       bool
+		expectedWarnings int
+	}{
+		{
+			label:      "Create with invalid fields",
+			caFunc:     old.DeepCopy,
+			expectedOk: false,
+		},
+		{
+			label:            "Reconcile with invalid fields",
+			caFunc:           old.DeepCopy,
+			old:              old,
+			expectedOk:       true,
+			expectedWarnings: 2,
+		},
+		{
+			label: "Update of other fields",
+			caFunc: func() *autoscalingv1.ClusterAutoscaler {
+				ca := old.DeepCopy()
+				ca.Spec.MaxPodGracePeriod = pointer.Int32(120)
+				return ca
+			},
+			old:              old,
+			expectedOk:       true,
+			expectedWarnings: 2,
+		},
+		{
+			label: "Update of an invalid field to another invalid value",
+			caFunc: func() *autoscalingv1.ClusterAutoscaler {
+				ca := old.DeepCopy()
+				ca.Spec.MaxNodeProvisionTime = "-1m"
+				return ca
+			},
+			old:              old,
+			expectedOk:       false,
+			expectedWarnings: 1,
+		},
+		{
+			label: "Update fixing an invalid field",
+			caFunc: func() *autoscalingv1.ClusterAutoscaler {
+				ca := old.DeepCopy()
+				ca.Spec.MaxNodeProvisionTime = "15m"
+				return ca
+			},
+			old:              old,
+			expectedOk:       true,
+			expectedWarnings: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			res := validator.ValidateSpecFields(tc.caFunc(), tc.old)
+
+			if res.IsValid() != tc.expectedOk {
+				t.Errorf("invalid resource, got %v, want %v, err: %v", res.IsValid(), tc.expectedOk, res.Errors)
+			}
+
+			if len(res.Warnings) != tc.expectedWarnings {
+				t.Errorf("expected %d warnings, got %v", tc.expectedWarnings, res.Warnings)
+			}
+		})
+	}
+}
+
+func TestValidateCrossResource(t *testing.T) {
+	newMachineAutoscaler := func(name string, min int32) runtime.Object {
+		return &autoscalingv1.MachineAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: TestNamespace},
+			Spec: autoscalingv1.MachineAutoscalerSpec{
+				MinReplicas: min,
+				MaxReplicas: min + 1,
+			},
+		}
+	}
+
+	newNodes := func(count int) []runtime.Object {
+		var nodes []runtime.Object
+		for i := 0; i < count; i++ {
+			nodes = append(nodes, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("node-%d", i)}})
+		}
+		return nodes
+	}
+
+	testCases := []struct {
+		label            string
+		maxNodesTotal    *int32
+		objects          []runtime.Object
+		expectedProblems []string
+	}{
+		{
+			label:         "No node limit",
+			maxNodesTotal: nil,
+			objects:       append(newNodes(10), newMachineAutoscaler("a", 20)),
+		},
+		{
+			label:         "Limits agree",
+			maxNodesTotal: ptr.To[int32](10),
+			objects:       append(newNodes(6), newMachineAutoscaler("a", 2), newMachineAutoscaler("b", 3)),
+		},
+		{
+			label:            "Sum of minReplicas exceeds the node limit",
+			maxNodesTotal:    ptr.To[int32](4),
+			objects:          append(newNodes(2), newMachineAutoscaler("a", 2), newMachineAutoscaler("b", 3)),
+			expectedProblems: []string{"a (2), b (3)"},
+		},
+		{
+			label:            "Node limit is below the node count",
+			maxNodesTotal:    ptr.To[int32](4),
+			objects:          newNodes(5),
+			expectedProblems: []string{"current number of nodes (5)"},
+		},
+	}
+
+	for _, tc := range testCases {
+		for _, strict := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s (strict: %v)", tc.label, strict), func(t *testing.T) {
+				client := fakeclient.NewClientBuilder().WithRuntimeObjects(tc.objects...).Build()
+				validator := NewValidator("test", client, scheme.Scheme)
+				validator.namespace = TestNamespace
+				validator.strict = strict
+
+				ca := NewClusterAutoscaler()
+				ca.Spec.ResourceLimits.MaxNodesTotal = tc.maxNodesTotal
+
+				res := validator.ValidateCrossResource(ca, nil)
+
+				problems := res.Warnings
+				if !res.IsValid() {
+					problems = nil
+					for _, err := range res.Errors.Errors() {
+						problems = append(problems, err.Error())
+					}
+				}
+
+				if res.IsValid() == (strict && len(tc.expectedProblems) > 0) {
+					t.Errorf("got valid %v in strict mode %v with problems %v", res.IsValid(), strict, problems)
+				}
+
+				if len(problems) != len(tc.expectedProblems) {
+					t.Fatalf("got problems %v, want %v", problems, tc.expectedProblems)
+				}
+
+				for i, expected := range tc.expectedProblems {
+					if !strings.Contains(problems[i], expected) {
+						t.Errorf("got problem %q, want it to contain %q", problems[i], expected)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestValidateCrossResourceUpdate(t *testing.T) {
+	var nodes []runtime.Object
+	for i := 0; i < 5; i++ {
+		nodes = append(nodes, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("node-%d", i)}})
+	}
+
+	client := fakeclient.NewClientBuilder().WithRuntimeObjects(nodes...).Build()
+	validator := NewValidator("test", client, scheme.Scheme)
+	validator.namespace = TestNamespace
+	validator.strict = true
+
+	testCases := []struct {
+		label         string
+		oldMaxNodes   *int32
+		maxNodesTotal int32
+		expectedOk    bool
+	}{
+		{
+			label:         "Node limit lowered below the node count",
+			oldMaxNodes:   ptr.To[int32](10),
+			maxNodesTotal: 4,
+			expectedOk:    false,
+		},
+		{
+			label:         "Node limit newly set below the node count",
+			maxNodesTotal: 4,
+			expectedOk:    false,
+		},
+		{
+			label:         "Node limit unchanged below the node count",
+			oldMaxNodes:   ptr.To[int32](4),
+			maxNodesTotal: 4,
+			expectedOk:    true,
+		},
+		{
+			label:         "Node limit raised but still below the node count",
+			oldMaxNodes:   ptr.To[int32](3),
+			maxNodesTotal: 4,
+			expectedOk:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			old := NewClusterAutoscaler()
+			old.Spec.ResourceLimits.MaxNodesTotal = tc.oldMaxNodes
+
+			ca := NewClusterAutoscaler()
+			ca.Spec.ResourceLimits.MaxNodesTotal = ptr.To(tc.maxNodesTotal)
+
+			res := validator.ValidateCrossResource(ca, old)
+
+			if res.IsValid() != tc.expectedOk {
+				t.Errorf("got valid %v, want %v, err: %v", res.IsValid(), tc.expectedOk, res.Errors)
+			}
+
+			// Problems which are not rejected are still reported.
+			if res.IsValid() && len(res.Warnings) == 0 {
+				t.Error("expected a warning for the existing problem")
+			}
+		})
+	}
+}
+
+func TestValidatePriorityExpander(t *testing.T) {
+	priorityConfig := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: PriorityExpanderConfigMapName, Namespace: TestNamespace},
+	}
+
+	testCases := []struct {
+		label      string
+		expanders  []autoscalingv1.ExpanderString
+		objects    []runtime.Object
+		expectedOk bool
+	}{
+		{
+			label:      "Priority expander is not used",
+			expanders:  []autoscalingv1.ExpanderString{autoscalingv1.RandomExpander},
+			expectedOk: true,
+		},
+		{
+			label:      "Priority expander with configuration",
+			expanders:  []autoscalingv1.ExpanderString{autoscalingv1.PriorityExpander},
+			objects:    []runtime.Object{priorityConfig},
+			expectedOk: true,
+		},
+		{
+			label:      "Priority expander without configuration",
+			expanders:  []autoscalingv1.ExpanderString{autoscalingv1.LeastWasteExpander, autoscalingv1.PriorityExpander},
+			expectedOk: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			client := fakeclient.NewClientBuilder().WithRuntimeObjects(tc.objects...).Build()
+			validator := NewValidator("test", client, scheme.Scheme)
+			validator.namespace = TestNamespace
+
+			ca := NewClusterAutoscaler()
+			ca.Spec.Expanders = tc.expanders
+
+			res := validator.ValidatePriorityExpander(ca)
+			if res.IsValid() != tc.expectedOk {
+				t.Errorf("got %v, want %v, err: %v", res.IsValid(), tc.expectedOk, res.Errors)
+			}
+
+			if !res.IsValid() && !strings.Contains(res.Errors.Error(), "spec.expanders[1]") {
+				t.Errorf("expected error with field path, got: %v", res.Errors)
+			}
+		})
+	}
+}
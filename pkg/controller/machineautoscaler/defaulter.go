@@ -0,0 +1,82 @@
+package machineautoscaler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// Defaulter sets defaults on MachineAutoscaler resources.
+type Defaulter struct {
+	decoder admission.Decoder
+
+	supportedTargetGVKs []schema.GroupVersionKind
+}
+
+// NewDefaulter returns a new Defaulter which normalizes target references
+// against the given list of supported GroupVersionKinds.
+func NewDefaulter(scheme *runtime.Scheme, supportedTargetGVKs []schema.GroupVersionKind) *Defaulter {
+	return &Defaulter{
+		decoder:             admission.NewDecoder(scheme),
+		supportedTargetGVKs: supportedTargetGVKs,
+	}
+}
+
+// Default sets defaults on the given MachineAutoscaler resource so that the
+// stored object reflects how it is handled by the operator.
+func (d *Defaulter) Default(ma *autoscalingv1.MachineAutoscaler) {
+	if ma == nil {
+		return
+	}
+
+	ref := &ma.Spec.ScaleTargetRef
+	ref.APIVersion = d.normalizeAPIVersion(strings.TrimSpace(ref.APIVersion), ref.Kind)
+}
+
+// normalizeAPIVersion returns the full API version of a supported target of
+// the given kind when the API version is missing, or only specifies the API
+// group.  Other API versions are returned unchanged.
+func (d *Defaulter) normalizeAPIVersion(apiVersion, kind string) string {
+	if strings.Contains(apiVersion, "/") {
+		return apiVersion
+	}
+
+	for _, gvk := range d.supportedTargetGVKs {
+		if gvk.Kind != kind {
+			continue
+		}
+
+		if apiVersion == "" || apiVersion == gvk.Group {
+			return gvk.GroupVersion().String()
+		}
+	}
+
+	return apiVersion
+}
+
+// Handle handles HTTP requests for admission webhook servers.
+func (d *Defaulter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	ma := &autoscalingv1.MachineAutoscaler{}
+
+	if err := d.decoder.Decode(req, ma); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	klog.V(2).Infof("Defaulting webhook called for MachineAutoscaler: %s", ma.GetName())
+
+	d.Default(ma)
+
+	marshaled, err := json.Marshal(ma)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
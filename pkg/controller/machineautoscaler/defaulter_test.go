@@ -0,0 +1,99 @@
+package machineautoscaler
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestDefault(t *testing.T) {
+	defaulter := NewDefaulter(scheme.Scheme, DefaultSupportedTargetGVKs())
+
+	testCases := []struct {
+		label      string
+		apiVersion string
+		kind       string
+		expected   string
+	}{
+		{
+			label:      "full API version is unchanged",
+			apiVersion: "machine.openshift.io/v1beta1",
+			kind:       "MachineSet",
+			expected:   "machine.openshift.io/v1beta1",
+		},
+		{
+			label:      "missing API version is set",
+			apiVersion: "",
+			kind:       "MachineSet",
+			expected:   "machine.openshift.io/v1beta1",
+		},
+		{
+			label:      "group is completed with the version",
+			apiVersion: " machine.openshift.io ",
+			kind:       "MachineSet",
+			expected:   "machine.openshift.io/v1beta1",
+		},
+		{
+			label:      "unsupported kind is unchanged",
+			apiVersion: "",
+			kind:       "MachineDeployment",
+			expected:   "",
+		},
+		{
+			label:      "unsupported API version is unchanged",
+			apiVersion: "cluster.x-k8s.io/v1beta1",
+			kind:       "MachineSet",
+			expected:   "cluster.x-k8s.io/v1beta1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			ma := NewMachineAutoscaler()
+			ma.Spec.ScaleTargetRef.APIVersion = tc.apiVersion
+			ma.Spec.ScaleTargetRef.Kind = tc.kind
+
+			defaulter.Default(ma)
+
+			if got := ma.Spec.ScaleTargetRef.APIVersion; got != tc.expected {
+				t.Errorf("got API version %q, want %q", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDefaulterHandle(t *testing.T) {
+	defaulter := NewDefaulter(scheme.Scheme, DefaultSupportedTargetGVKs())
+
+	ma := NewMachineAutoscaler()
+	ma.Spec.ScaleTargetRef.APIVersion = ""
+
+	raw, err := json.Marshal(ma)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	res := defaulter.Handle(context.TODO(), admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	})
+
+	if !res.Allowed {
+		t.Fatalf("expected request to be allowed: %v", res.Result)
+	}
+
+	if len(res.Patches) != 1 {
+		t.Fatalf("got %d patches, want 1: %v", len(res.Patches), res.Patches)
+	}
+
+	if res.Patches[0].Path != "/spec/scaleTargetRef/apiVersion" {
+		t.Errorf("got patch path %q, want /spec/scaleTargetRef/apiVersion", res.Patches[0].Path)
+	}
+}
@@ -5,12 +5,14 @@ import (
 	"errors"
 	"fmt"
 
-	"github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1beta1"
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	"github.com/openshift/cluster-autoscaler-operator/pkg/metrics"
 	"github.com/openshift/cluster-autoscaler-operator/pkg/util"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -37,6 +39,12 @@ const (
 	minSizeAnnotation = "machine.openshift.io/cluster-api-autoscaler-node-group-min-size"
 	maxSizeAnnotation = "machine.openshift.io/cluster-api-autoscaler-node-group-max-size"
 
+	// The upstream Cluster API group, and the min and max annotations used on
+	// its targets.
+	clusterAPIGroup             = "cluster.x-k8s.io"
+	clusterAPIMinSizeAnnotation = "cluster.x-k8s.io/cluster-api-autoscaler-node-group-min-size"
+	clusterAPIMaxSizeAnnotation = "cluster.x-k8s.io/cluster-api-autoscaler-node-group-max-size"
+
 	controllerName = "machine_autoscaler_controller"
 )
 
@@ -66,6 +74,15 @@ func DefaultSupportedTargetGVKs() []schema.GroupVersionKind {
 	}
 }
 
+// ClusterAPITargetGVKs returns the list of upstream Cluster API
+// GroupVersionKinds which can be targeted by MachineAutoscaler resources.
+func ClusterAPITargetGVKs() []schema.GroupVersionKind {
+	return []schema.GroupVersionKind{
+		{Group: clusterAPIGroup, Version: "v1beta1", Kind: "MachineSet"},
+		{Group: clusterAPIGroup, Version: "v1beta1", Kind: "MachineDeployment"},
+	}
+}
+
 // Config represents the configuration for a reconciler instance.
 type Config struct {
 	// The namespace for MachineAutosclaers and their targets.
@@ -73,17 +90,30 @@ type Config struct {
 
 	// The list of supported GroupVersionKinds for a reconciler.
 	SupportedTargetGVKs []schema.GroupVersionKind
+
+	// Whether cross-resource admission checks reject rather than warn.
+	StrictValidation bool
 }
 
 // NewReconciler returns a new Reconciler.
 func NewReconciler(mgr manager.Manager, config Config) *Reconciler {
-	return &Reconciler{
+	r := &Reconciler{
 		client:    mgr.GetClient(),
 		scheme:    mgr.GetScheme(),
 		recorder:  mgr.GetEventRecorder(controllerName),
 		validator: NewValidator(mgr.GetClient(), mgr.GetScheme()),
 		config:    config,
 	}
+
+	// Resolve targets at admission the same way the reconciler does.
+	r.validator.targets = r
+
+	// Cross-resource admission checks read live objects directly rather
+	// than through the cache.
+	r.validator.reader = mgr.GetAPIReader()
+	r.validator.strict = config.StrictValidation
+
+	return r
 }
 
 // AddToManager adds a new Controller to mgr with r as the reconcile.Reconciler
@@ -96,7 +126,7 @@ func (r *Reconciler) AddToManager(mgr manager.Manager) error {
 	}
 
 	// Watch for changes to primary resource MachineAutoscaler
-	err = c.Watch(source.Kind(mgr.GetCache(), &v1beta1.MachineAutoscaler{}, &handler.TypedEnqueueRequestForObject[*v1beta1.MachineAutoscaler]{}))
+	err = c.Watch(source.Kind(mgr.GetCache(), &autoscalingv1.MachineAutoscaler{}, &handler.TypedEnqueueRequestForObject[*autoscalingv1.MachineAutoscaler]{}))
 	if err != nil {
 		return err
 	}
@@ -175,19 +205,20 @@ type Reconciler struct {
 // Reconcile reads that state of the cluster for a MachineAutoscaler object and
 // makes changes based on the state read and what is in the
 // MachineAutoscaler.Spec
-func (r *Reconciler) Reconcile(_ context.Context, request reconcile.Request) (reconcile.Result, error) {
+func (r *Reconciler) Reconcile(_ context.Context, request reconcile.Request) (result reconcile.Result, err error) {
 	// TODO(elmiko) update this function to use the context that is provided
 	klog.Infof("Reconciling MachineAutoscaler %s/%s\n", request.Namespace, request.Name)
 
 	// Fetch the MachineAutoscaler instance
-	ma := &v1beta1.MachineAutoscaler{}
-	err := r.client.Get(context.TODO(), request.NamespacedName, ma)
+	ma := &autoscalingv1.MachineAutoscaler{}
+	err = r.client.Get(context.TODO(), request.NamespacedName, ma)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile
 			// request.  Owned objects are automatically garbage collected. For
 			// additional cleanup logic use finalizers.
 			// Return and don't requeue.
+			metrics.DeleteMachineAutoscaler(request.NamespacedName)
 			return reconcile.Result{}, nil
 		}
 
@@ -215,10 +246,28 @@ func (r *Reconciler) Reconcile(_ context.Context, request reconcile.Request) (re
 		}
 	}
 
+	// The first observed target and the outcome of the reconcile, in the
+	// Ready condition, are recorded with a single status update once done.
+	var lastTarget *corev1.ObjectReference
+	var ready *metav1.Condition
+	defer func() {
+		if statusErr := r.updateStatus(ma, lastTarget, ready); statusErr != nil {
+			errMsg := fmt.Sprintf("Error updating status: %v", statusErr)
+			r.recorder.Eventf(ma, nil, corev1.EventTypeWarning, "FailedUpdateStatus", "UpdateStatus", "Error updating status: %v", statusErr)
+			klog.Errorf("%s: %s", request.NamespacedName, errMsg)
+
+			if err == nil {
+				err = statusErr
+			}
+		}
+	}()
+
 	// Validate the MachineAutoscaler early and return if any errors are found.
 	if res := r.validator.Validate(ma); !res.IsValid() {
 		r.recorder.Eventf(ma, nil, corev1.EventTypeWarning, "FailedValidation", "Validate", "MachineAutoscaler validation error: %v", res.Errors)
 		klog.Errorf("%s: %s", request.NamespacedName, fmt.Sprintf("MachineAutoscaler validation error: %v", res.Errors))
+		metrics.SetMachineAutoscalerCondition(request.NamespacedName, metrics.ConditionInvalid)
+		ready = notReady(autoscalingv1.InvalidReason, fmt.Sprintf("Validation error: %v", res.Errors))
 
 		return reconcile.Result{}, res.Errors
 	}
@@ -231,6 +280,14 @@ func (r *Reconciler) Reconcile(_ context.Context, request reconcile.Request) (re
 		r.recorder.Eventf(ma, targetRef, corev1.EventTypeWarning, "FailedGetTarget", "GetTarget", "Error getting target: %v", err)
 		klog.Errorf("%s: %s", request.NamespacedName, errMsg)
 
+		if apierrors.IsNotFound(err) {
+			metrics.SetMachineAutoscalerCondition(request.NamespacedName, metrics.ConditionTargetNotFound)
+			ready = notReady(autoscalingv1.TargetNotFoundReason, errMsg)
+		} else {
+			metrics.SetMachineAutoscalerCondition(request.NamespacedName, metrics.ConditionTargetError)
+			ready = notReady(autoscalingv1.TargetErrorReason, errMsg)
+		}
+
 		return reconcile.Result{}, err
 	}
 
@@ -241,6 +298,15 @@ func (r *Reconciler) Reconcile(_ context.Context, request reconcile.Request) (re
 		r.recorder.Eventf(ma, target, corev1.EventTypeWarning, "FailedSetOwner", "SetOwner", "Error setting target owner: %v", err)
 		klog.Errorf("%s: %s", request.NamespacedName, errMsg)
 
+		if errors.Is(err, ErrTargetAlreadyOwned) {
+			metrics.RecordTargetOwnershipConflict(request.NamespacedName, targetRef.Kind, targetRef.Name)
+			metrics.SetMachineAutoscalerCondition(request.NamespacedName, metrics.ConditionTargetConflict)
+			ready = notReady(autoscalingv1.TargetConflictReason, errMsg)
+		} else {
+			metrics.SetMachineAutoscalerCondition(request.NamespacedName, metrics.ConditionTargetError)
+			ready = notReady(autoscalingv1.TargetErrorReason, errMsg)
+		}
+
 		return reconcile.Result{}, err
 	}
 
@@ -252,13 +318,7 @@ func (r *Reconciler) Reconcile(_ context.Context, request reconcile.Request) (re
 
 	// Set the previous target if we don't have one.
 	if ma.Status.LastTargetRef == nil {
-		if err := r.SetLastTarget(ma, targetRef); err != nil {
-			errMsg := fmt.Sprintf("Error setting previous target: %v", err)
-			r.recorder.Eventf(ma, targetRef, corev1.EventTypeWarning, "FailedSetLastTarget", "SetLastTarget", "Error setting previous target: %v", err)
-			klog.Errorf("%s: %s", request.NamespacedName, errMsg)
-
-			return reconcile.Result{}, err
-		}
+		lastTarget = targetRef
 	}
 
 	// Ensure our finalizers have been added.
@@ -274,6 +334,8 @@ func (r *Reconciler) Reconcile(_ context.Context, request reconcile.Request) (re
 		errMsg := fmt.Sprintf("Error updating target: %v", err)
 		r.recorder.Eventf(ma, target, corev1.EventTypeWarning, "FailedUpdateTarget", "UpdateTarget", "Error updating target: %v", err)
 		klog.Errorf("%s: %s", request.NamespacedName, errMsg)
+		metrics.SetMachineAutoscalerCondition(request.NamespacedName, metrics.ConditionUpdateFailed)
+		ready = notReady(autoscalingv1.UpdateFailedReason, errMsg)
 
 		return reconcile.Result{}, err
 	}
@@ -282,12 +344,23 @@ func (r *Reconciler) Reconcile(_ context.Context, request reconcile.Request) (re
 	r.recorder.Eventf(ma, target, corev1.EventTypeNormal, "SuccessfulUpdate", "UpdateTarget", "Updated MachineAutoscaler target: %s", target.NamespacedName())
 	klog.V(2).Infof("%s: %s", request.NamespacedName, msg)
 
+	replicas, _ := target.GetReplicas()
+	metrics.SetMachineAutoscalerReplicas(request.NamespacedName, targetRef.Kind, targetRef.Name,
+		ma.Spec.MinReplicas, ma.Spec.MaxReplicas, replicas)
+	metrics.SetMachineAutoscalerCondition(request.NamespacedName, metrics.ConditionReady)
+
+	ready = &metav1.Condition{
+		Status:  metav1.ConditionTrue,
+		Reason:  autoscalingv1.TargetUpdatedReason,
+		Message: msg,
+	}
+
 	return reconcile.Result{}, nil
 }
 
 // HandleDelete is called by Reconcile to handle MachineAutoscaler deletion,
 // i.e. finalize the resource and remove finalizers.
-func (r *Reconciler) HandleDelete(ma *v1beta1.MachineAutoscaler) (reconcile.Result, error) {
+func (r *Reconciler) HandleDelete(ma *autoscalingv1.MachineAutoscaler) (reconcile.Result, error) {
 	targetRef := objectReference(ma.Spec.ScaleTargetRef)
 
 	target, err := r.GetTarget(targetRef)
@@ -311,13 +384,15 @@ func (r *Reconciler) HandleDelete(ma *v1beta1.MachineAutoscaler) (reconcile.Resu
 		return reconcile.Result{}, err
 	}
 
+	metrics.DeleteMachineAutoscaler(types.NamespacedName{Namespace: ma.Namespace, Name: ma.Name})
+
 	return reconcile.Result{}, nil
 }
 
 // HandleTargetChange is called by Reconcile to handle updates to a the target
 // referenced by a MachineAutoscaler.  When a target changes, the previous
 // target must have its autoscaling configuration removed.
-func (r *Reconciler) HandleTargetChange(ma *v1beta1.MachineAutoscaler) error {
+func (r *Reconciler) HandleTargetChange(ma *autoscalingv1.MachineAutoscaler) error {
 	// If the previous target is nil, there's nothing to do.
 	if ma.Status.LastTargetRef == nil {
 		return nil
@@ -372,6 +447,12 @@ func (r *Reconciler) Validator() *Validator {
 	return r.validator
 }
 
+// Defaulter returns a defaulter for MachineAutoscaler resources which
+// normalizes target references against the currently supported targets.
+func (r *Reconciler) Defaulter() *Defaulter {
+	return NewDefaulter(r.scheme, r.SupportedGVKs())
+}
+
 // GetTarget fetches the object targeted by the given reference.
 func (r *Reconciler) GetTarget(ref *corev1.ObjectReference) (*MachineTarget, error) {
 	obj := &unstructured.Unstructured{}
@@ -448,7 +529,7 @@ func (r *Reconciler) FinalizeTarget(target *MachineTarget) error {
 
 // TargetChanged indicates whether a MachineAutoscaler's current target has
 // changed relative to the last observed target noted in the status.
-func (r *Reconciler) TargetChanged(ma *v1beta1.MachineAutoscaler) bool {
+func (r *Reconciler) TargetChanged(ma *autoscalingv1.MachineAutoscaler) bool {
 	currentRef := ma.Spec.ScaleTargetRef
 	lastRef := ma.Status.LastTargetRef
 
@@ -461,8 +542,8 @@ func (r *Reconciler) TargetChanged(ma *v1beta1.MachineAutoscaler) bool {
 
 // SetLastTarget updates the give MachineAutoscaler's status with the given
 // object as the last observed target.
-func (r *Reconciler) SetLastTarget(ma *v1beta1.MachineAutoscaler, ref *corev1.ObjectReference) error {
-	ma.Status.LastTargetRef = &v1beta1.CrossVersionObjectReference{
+func (r *Reconciler) SetLastTarget(ma *autoscalingv1.MachineAutoscaler, ref *corev1.ObjectReference) error {
+	ma.Status.LastTargetRef = &autoscalingv1.ScaleTargetReference{
 		APIVersion: ref.APIVersion,
 		Kind:       ref.Kind,
 		Name:       ref.Name,
@@ -471,8 +552,46 @@ func (r *Reconciler) SetLastTarget(ma *v1beta1.MachineAutoscaler, ref *corev1.Ob
 	return r.client.Status().Update(context.TODO(), ma)
 }
 
+// updateStatus sets the last observed target of the given MachineAutoscaler,
+// unless nil, and its Ready condition to the given one, unless nil, updating
+// its status if changed.
+func (r *Reconciler) updateStatus(ma *autoscalingv1.MachineAutoscaler, lastTarget *corev1.ObjectReference, ready *metav1.Condition) error {
+	changed := false
+
+	if lastTarget != nil {
+		ma.Status.LastTargetRef = &autoscalingv1.ScaleTargetReference{
+			APIVersion: lastTarget.APIVersion,
+			Kind:       lastTarget.Kind,
+			Name:       lastTarget.Name,
+		}
+		changed = true
+	}
+
+	if ready != nil {
+		ready.Type = autoscalingv1.ReadyCondition
+		ready.ObservedGeneration = ma.GetGeneration()
+		changed = meta.SetStatusCondition(&ma.Status.Conditions, *ready) || changed
+	}
+
+	if !changed {
+		return nil
+	}
+
+	return r.client.Status().Update(context.TODO(), ma)
+}
+
+// notReady returns a false Ready condition with the given reason and
+// message, to be set once the reconcile is done.
+func notReady(reason, message string) *metav1.Condition {
+	return &metav1.Condition{
+		Status:  metav1.ConditionFalse,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
 // EnsureFinalizer adds finalizers to the given MachineAutoscaler if necessary.
-func (r *Reconciler) EnsureFinalizer(ma *v1beta1.MachineAutoscaler) error {
+func (r *Reconciler) EnsureFinalizer(ma *autoscalingv1.MachineAutoscaler) error {
 	for _, f := range ma.GetFinalizers() {
 		// Bail early if we already have the finalizer.
 		if f == MachineTargetFinalizer {
@@ -488,7 +607,7 @@ func (r *Reconciler) EnsureFinalizer(ma *v1beta1.MachineAutoscaler) error {
 
 // RemoveFinalizer removes this packages's finalizers from the given
 // MachineAutoscaler instance.
-func (r *Reconciler) RemoveFinalizer(ma *v1beta1.MachineAutoscaler) error {
+func (r *Reconciler) RemoveFinalizer(ma *autoscalingv1.MachineAutoscaler) error {
 	f, found := util.FilterString(ma.GetFinalizers(), MachineTargetFinalizer)
 
 	if found == 0 {
@@ -575,8 +694,8 @@ func targetOwnerRequest[T client.Object](_ context.Context, a T) []reconcile.Req
 }
 
 // objectReference returns a new corev1.ObjectReference for the given
-// CrossVersionObjectReference from a MachineAutoscaler target.
-func objectReference(ref v1beta1.CrossVersionObjectReference) *corev1.ObjectReference {
+// ScaleTargetReference from a MachineAutoscaler target.
+func objectReference(ref autoscalingv1.ScaleTargetReference) *corev1.ObjectReference {
 	obj := &corev1.ObjectReference{}
 	gvk := schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind)
 
@@ -7,16 +7,20 @@ import (
 	"testing"
 
 	"github.com/openshift/cluster-autoscaler-operator/pkg/apis"
-	autoscalingv1beta1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1beta1"
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -44,8 +48,8 @@ func newMachineTarget(name string) *MachineTarget {
 }
 
 // Set the target on the given MachineAutoscaler.
-func setTarget(ma *autoscalingv1beta1.MachineAutoscaler, mt *MachineTarget) {
-	ma.Spec.ScaleTargetRef = autoscalingv1beta1.CrossVersionObjectReference{
+func setTarget(ma *autoscalingv1.MachineAutoscaler, mt *MachineTarget) {
+	ma.Spec.ScaleTargetRef = autoscalingv1.ScaleTargetReference{
 		APIVersion: mt.GetAPIVersion(),
 		Kind:       mt.GetKind(),
 		Name:       mt.GetName(),
@@ -58,7 +62,7 @@ func newFakeReconciler(cfg Config, initObjects ...runtime.Object) *Reconciler {
 		NewClientBuilder().
 		WithScheme(scheme.Scheme).
 		WithRuntimeObjects(initObjects...).
-		WithStatusSubresource(&autoscalingv1beta1.MachineAutoscaler{}).
+		WithStatusSubresource(&autoscalingv1.MachineAutoscaler{}).
 		Build()
 	return &Reconciler{
 		client:   fakeClient,
@@ -320,6 +324,94 @@ func TestHandleTargetChange(t *testing.T) {
 					t.Errorf("got %v, want %v", got, expected)
 				}
 			}
+
+			// Check that the Ready condition reflects the new target.
+			if err := r.client.Get(context.TODO(), maName, ma); err != nil {
+				t.Fatalf("Failed to fetch MachineAutoscaler: %v", err)
+			}
+
+			expectedStatus, expectedReason := metav1.ConditionTrue, autoscalingv1.TargetUpdatedReason
+			if tt.newTarget == missingTarget {
+				expectedStatus, expectedReason = metav1.ConditionFalse, autoscalingv1.TargetNotFoundReason
+			}
+
+			cond := meta.FindStatusCondition(ma.Status.Conditions, autoscalingv1.ReadyCondition)
+			if cond == nil {
+				t.Fatal("Missing Ready condition")
+			}
+
+			if cond.Status != expectedStatus || cond.Reason != expectedReason {
+				t.Errorf("got Ready=%s (%s), want Ready=%s (%s)", cond.Status, cond.Reason, expectedStatus, expectedReason)
+			}
 		})
 	}
 }
+
+func TestReconcileStatusUpdates(t *testing.T) {
+	ma := NewMachineAutoscaler()
+	setTarget(ma, newMachineTarget("worker"))
+
+	maName := types.NamespacedName{Namespace: ma.Namespace, Name: ma.Name}
+
+	r := newFakeReconciler(Config{
+		Namespace:           TestNamespace,
+		SupportedTargetGVKs: DefaultSupportedTargetGVKs(),
+	}, ma)
+
+	statusUpdates := 0
+	r.client = interceptor.NewClient(r.client.(client.WithWatch), interceptor.Funcs{
+		SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+			statusUpdates++
+			return c.SubResource(subResourceName).Update(ctx, obj, opts...)
+		},
+	})
+
+	// A failing reconcile updates the status once, and not again while it
+	// keeps failing the same way.
+	for i := 0; i < 3; i++ {
+		if _, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: maName}); err == nil {
+			t.Fatal("expected reconcile to fail")
+		}
+	}
+
+	if statusUpdates != 1 {
+		t.Errorf("got %d status updates, want 1", statusUpdates)
+	}
+
+	if err := r.client.Get(context.TODO(), maName, ma); err != nil {
+		t.Fatalf("Failed to fetch MachineAutoscaler: %v", err)
+	}
+
+	cond := meta.FindStatusCondition(ma.Status.Conditions, autoscalingv1.ReadyCondition)
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != autoscalingv1.TargetNotFoundReason {
+		t.Errorf("expected Ready=False (%s), got %+v", autoscalingv1.TargetNotFoundReason, cond)
+	}
+
+	// Once the target exists, the last target and the Ready condition are
+	// recorded with a single update.
+	target := newMachineTarget("worker")
+	if err := r.client.Create(context.TODO(), target.ToUnstructured()); err != nil {
+		t.Fatalf("Failed to create target: %v", err)
+	}
+
+	statusUpdates = 0
+	if _, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: maName}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if statusUpdates != 1 {
+		t.Errorf("got %d status updates, want 1", statusUpdates)
+	}
+
+	if err := r.client.Get(context.TODO(), maName, ma); err != nil {
+		t.Fatalf("Failed to fetch MachineAutoscaler: %v", err)
+	}
+
+	if ma.Status.LastTargetRef == nil || ma.Status.LastTargetRef.Name != "worker" {
+		t.Errorf("expected last target to be recorded, got %+v", ma.Status.LastTargetRef)
+	}
+
+	if !meta.IsStatusConditionTrue(ma.Status.Conditions, autoscalingv1.ReadyCondition) {
+		t.Errorf("expected Ready=True, got %+v", ma.Status.Conditions)
+	}
+}
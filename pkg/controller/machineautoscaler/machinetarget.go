@@ -97,29 +97,45 @@ func (mt *MachineTarget) SetLimits(min, max int) {
 		annotations = make(map[string]string)
 	}
 
-	annotations[minSizeAnnotation] = strconv.Itoa(min)
-	annotations[maxSizeAnnotation] = strconv.Itoa(max)
+	minAnnotation, maxAnnotation := mt.limitAnnotations()
+
+	annotations[minAnnotation] = strconv.Itoa(min)
+	annotations[maxAnnotation] = strconv.Itoa(max)
 
 	mt.SetAnnotations(annotations)
 }
 
 // RemoveLimits removes the target's min and max annotations.
 func (mt *MachineTarget) RemoveLimits() bool {
+	minAnnotation, maxAnnotation := mt.limitAnnotations()
+
 	annotations := []string{
-		minSizeAnnotation,
-		maxSizeAnnotation,
+		minAnnotation,
+		maxAnnotation,
 	}
 
 	return mt.RemoveAnnotations(annotations)
 }
 
+// limitAnnotations returns the keys of the target's min and max annotations,
+// which depend on the target's API group.
+func (mt *MachineTarget) limitAnnotations() (string, string) {
+	if mt.GroupVersionKind().Group == clusterAPIGroup {
+		return clusterAPIMinSizeAnnotation, clusterAPIMaxSizeAnnotation
+	}
+
+	return minSizeAnnotation, maxSizeAnnotation
+}
+
 // GetLimits returns the target's min and max limits.  An error may be
 // returned if the annotations's contents could not be parsed as ints.
 func (mt *MachineTarget) GetLimits() (min, max int, err error) {
 	annotations := mt.GetAnnotations()
 
-	minString, minOK := annotations[minSizeAnnotation]
-	maxString, maxOK := annotations[maxSizeAnnotation]
+	minAnnotation, maxAnnotation := mt.limitAnnotations()
+
+	minString, minOK := annotations[minAnnotation]
+	maxString, maxOK := annotations[maxAnnotation]
 
 	if !minOK || !maxOK {
 		return 0, 0, ErrTargetMissingAnnotations
@@ -239,6 +255,17 @@ func (mt *MachineTarget) NamespacedName() types.NamespacedName {
 	}
 }
 
+// GetReplicas returns the number of replicas currently requested by the
+// target, and a bool indicating whether the replicas field was found.
+func (mt *MachineTarget) GetReplicas() (int64, bool) {
+	replicas, found, err := unstructured.NestedInt64(mt.Object, "spec", "replicas")
+	if err != nil || !found {
+		return 0, false
+	}
+
+	return replicas, true
+}
+
 // HasGPUCapacity returns true if the machine target contains the annotation
 // which indicates that the target will have GPU capacity, and that the
 // value is positive.
@@ -93,6 +93,36 @@ func TestSetLimits(t *testing.T) {
 	}
 }
 
+func TestSetLimitsClusterAPI(t *testing.T) {
+	target := NewTarget()
+	target.SetAPIVersion("cluster.x-k8s.io/v1beta1")
+	target.SetKind("MachineDeployment")
+
+	target.SetLimits(2, 4)
+
+	annotations := target.GetAnnotations()
+	if annotations[clusterAPIMinSizeAnnotation] != "2" || annotations[clusterAPIMaxSizeAnnotation] != "4" {
+		t.Errorf("expected cluster.x-k8s.io limit annotations, got: %v", annotations)
+	}
+
+	if _, ok := annotations[minSizeAnnotation]; ok {
+		t.Errorf("unexpected %s annotation on cluster.x-k8s.io target", minSizeAnnotation)
+	}
+
+	min, max, err := target.GetLimits()
+	if err != nil {
+		t.Fatalf("error getting limits: %v", err)
+	}
+
+	if min != 2 || max != 4 {
+		t.Errorf("got %d-%d, want 2-4", min, max)
+	}
+
+	if !target.RemoveLimits() {
+		t.Errorf("expected limit annotations to be removed")
+	}
+}
+
 func TestGetLimits(t *testing.T) {
 	target := NewTarget()
 
@@ -3,21 +3,43 @@ package machineautoscaler
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 
-	autoscalingv1beta1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1beta1"
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	"github.com/openshift/cluster-autoscaler-operator/pkg/metrics"
 	"github.com/openshift/cluster-autoscaler-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
+// targetGetter resolves MachineAutoscaler target references.
+type targetGetter interface {
+	GetTarget(ref *corev1.ObjectReference) (*MachineTarget, error)
+	SupportedGVKs() []schema.GroupVersionKind
+}
+
 // Validator validates MachineAutoscaler resources.
 type Validator struct {
 	client  client.Client
 	decoder admission.Decoder
+
+	// targets resolves target references during admission.  Target checks
+	// are skipped if it is not set.
+	targets targetGetter
+
+	// reader is used to read the live objects a MachineAutoscaler is checked
+	// against at admission, and strict indicates whether problems found are
+	// errors rather than warnings.
+	reader client.Reader
+	strict bool
 }
 
 // NewValidator returns a new Validator.
@@ -25,12 +47,14 @@ func NewValidator(client client.Client, scheme *runtime.Scheme) *Validator {
 	return &Validator{
 		client:  client,
 		decoder: admission.NewDecoder(scheme),
+		reader:  client,
 	}
 }
 
 // Validate validates the given MachineAutoscaler resource.
-func (v *Validator) Validate(ma *autoscalingv1beta1.MachineAutoscaler) util.ValidatorResponse {
+func (v *Validator) Validate(ma *autoscalingv1.MachineAutoscaler) util.ValidatorResponse {
 	var errs []error
+	var reasons []string
 
 	if ma == nil {
 		err := errors.New("MachineAutoscaler is nil")
@@ -39,36 +63,156 @@ func (v *Validator) Validate(ma *autoscalingv1beta1.MachineAutoscaler) util.Vali
 
 	if ma.Spec.MinReplicas < 0 || ma.Spec.MaxReplicas < 0 {
 		errs = append(errs, errors.New("min and max replicas must be greater than 0"))
+		reasons = append(reasons, "NegativeReplicas")
 	}
 
 	if ma.Spec.MaxReplicas < ma.Spec.MinReplicas {
 		errs = append(errs, errors.New("max replicas must be greater than or equal to min"))
+		reasons = append(reasons, "MaxReplicasBelowMin")
 	}
 
 	if len(errs) > 0 {
-		return util.ValidatorResponse{Warnings: nil, Errors: utilerrors.NewAggregate(errs)}
+		return util.ValidatorResponse{Warnings: nil, Errors: utilerrors.NewAggregate(errs), Reasons: reasons}
 	}
 
 	return util.ValidatorResponse{}
 }
 
+// ValidateTarget validates the target of the given MachineAutoscaler resource
+// against the live target object.  Unsupported targets, and targets owned by
+// a different MachineAutoscaler, are errors.  Targets which do not exist yet
+// only result in a warning, as they may be created later.
+func (v *Validator) ValidateTarget(ma *autoscalingv1.MachineAutoscaler) util.ValidatorResponse {
+	if v.targets == nil || ma == nil || ma.GetDeletionTimestamp() != nil {
+		return util.ValidatorResponse{}
+	}
+
+	ref := objectReference(ma.Spec.ScaleTargetRef)
+
+	target, err := v.targets.GetTarget(ref)
+	switch {
+	case errors.Is(err, ErrUnsupportedTarget):
+		err := fmt.Errorf("target %s is not supported, supported targets are: %v",
+			ref.GroupVersionKind(), v.targets.SupportedGVKs())
+		return util.ValidatorResponse{Errors: utilerrors.NewAggregate([]error{err}), Reasons: []string{"UnsupportedTarget"}}
+
+	case errors.Is(err, ErrInvalidTarget):
+		return util.ValidatorResponse{Errors: utilerrors.NewAggregate([]error{err}), Reasons: []string{"InvalidTarget"}}
+
+	case apierrors.IsNotFound(err):
+		warning := fmt.Sprintf("target %s %q does not exist, it will be scaled once created", ref.Kind, ref.Name)
+		return util.ValidatorResponse{Warnings: []string{warning}}
+
+	case err != nil:
+		// The target could not be resolved, leave it to the reconciler
+		// rather than rejecting the MachineAutoscaler.
+		klog.Warningf("Unable to get target of MachineAutoscaler %s: %v", ma.GetName(), err)
+		return util.ValidatorResponse{}
+	}
+
+	owner := types.NamespacedName{Namespace: ma.GetNamespace(), Name: ma.GetName()}
+	if current, ok := target.GetAnnotations()[MachineTargetOwnerAnnotation]; ok && current != owner.String() {
+		err := fmt.Errorf("target %s %q is %v: %s", ref.Kind, ref.Name, ErrTargetAlreadyOwned, current)
+		return util.ValidatorResponse{Errors: utilerrors.NewAggregate([]error{err}), Reasons: []string{"TargetOwnedByOther"}}
+	}
+
+	return util.ValidatorResponse{}
+}
+
+// ValidateCrossResource validates the minimum replicas of the given
+// MachineAutoscaler, together with those of the other MachineAutoscalers,
+// against the node limit of the live ClusterAutoscaler.  Problems are
+// returned as warnings, or as errors in strict mode.  The previous version of
+// the MachineAutoscaler, if any, is given as old: in strict mode, problems
+// are only errors if the change raises the minimum replicas, so that changes
+// leaving an existing problem as is, or reducing it, are still admitted.
+func (v *Validator) ValidateCrossResource(ma, old *autoscalingv1.MachineAutoscaler) util.ValidatorResponse {
+	if ma == nil || ma.GetDeletionTimestamp() != nil {
+		return util.ValidatorResponse{}
+	}
+
+	cas := &autoscalingv1.ClusterAutoscalerList{}
+	if err := v.reader.List(context.TODO(), cas); err != nil {
+		klog.Warningf("Unable to list ClusterAutoscalers for validation: %v", err)
+		return util.ValidatorResponse{}
+	}
+
+	mas := &autoscalingv1.MachineAutoscalerList{}
+	if err := v.reader.List(context.TODO(), mas, client.InNamespace(ma.GetNamespace())); err != nil {
+		klog.Warningf("Unable to list MachineAutoscalers for validation: %v", err)
+		return util.ValidatorResponse{}
+	}
+
+	// The admitted MachineAutoscaler replaces its live version, if any.
+	minReplicas := map[string]int32{}
+	for _, other := range mas.Items {
+		if other.GetDeletionTimestamp() == nil {
+			minReplicas[other.GetName()] = other.Spec.MinReplicas
+		}
+	}
+	minReplicas[ma.GetName()] = ma.Spec.MinReplicas
+
+	var oldMinReplicas int32
+	if old != nil {
+		oldMinReplicas = old.Spec.MinReplicas
+	}
+	worse := ma.Spec.MinReplicas > oldMinReplicas
+
+	problems := []string{}
+	reasons := []string{}
+
+	for _, ca := range cas.Items {
+		if ca.Spec.ResourceLimits == nil || ca.Spec.ResourceLimits.MaxNodesTotal == nil {
+			continue
+		}
+
+		if msg := util.MinReplicasExceedMaxNodesTotal(*ca.Spec.ResourceLimits.MaxNodesTotal, minReplicas); msg != "" {
+			problems = append(problems, fmt.Sprintf("ClusterAutoscaler %s: %s", ca.GetName(), msg))
+			reasons = append(reasons, "MinReplicasExceedMaxNodesTotal")
+		}
+	}
+
+	return util.CrossResourceResponse(v.strict && worse, problems, reasons)
+}
+
 // Handle handles HTTP requests for admission webhook servers.
 func (v *Validator) Handle(ctx context.Context, req admission.Request) admission.Response {
-	ma := &autoscalingv1beta1.MachineAutoscaler{}
+	ma := &autoscalingv1.MachineAutoscaler{}
 
 	if err := v.decoder.Decode(req, ma); err != nil {
+		metrics.RecordAdmissionDecision(metrics.ResourceMachineAutoscaler, metrics.AdmissionErrored)
 		return admission.Errored(http.StatusBadRequest, err)
 	}
 
 	klog.Infof("Validation webhook called for MachineAutoscaler: %s", ma.GetName())
 
+	// The namespace may be omitted from the object on creation.
+	if ma.GetNamespace() == "" {
+		ma.SetNamespace(req.Namespace)
+	}
+
+	var old *autoscalingv1.MachineAutoscaler
+	if len(req.OldObject.Raw) > 0 {
+		old = &autoscalingv1.MachineAutoscaler{}
+		if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			metrics.RecordAdmissionDecision(metrics.ResourceMachineAutoscaler, metrics.AdmissionErrored)
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+	}
+
 	var admRes admission.Response
 
-	valRes := v.Validate(ma)
+	valRes := util.MergeValidatorResponses(v.Validate(ma), v.ValidateTarget(ma), v.ValidateCrossResource(ma, old))
 	if valRes.IsValid() {
 		admRes = admission.Allowed("MachineAutoscaler valid")
+		metrics.RecordAdmissionDecision(metrics.ResourceMachineAutoscaler, metrics.AdmissionAllowed)
 	} else {
 		admRes = admission.Denied(valRes.Errors.Error())
+		metrics.RecordAdmissionDecision(metrics.ResourceMachineAutoscaler, metrics.AdmissionDenied)
+
+		for _, reason := range valRes.Reasons {
+			metrics.RecordValidationFailure(metrics.ResourceMachineAutoscaler, reason)
+		}
 	}
 
 	if len(valRes.Warnings) > 0 {
@@ -1,12 +1,21 @@
 package machineautoscaler
 
 import (
+	"context"
+	"encoding/json"
+	"strings"
 	"testing"
 
-	autoscalingv1beta1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1beta1"
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	"github.com/openshift/cluster-autoscaler-operator/pkg/metrics"
+	dto "github.com/prometheus/client_model/go"
+	admissionv1 "k8s.io/api/admission/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
 	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 const (
@@ -14,20 +23,20 @@ const (
 	TestMaxReplicas = 8
 )
 
-func NewMachineAutoscaler() *autoscalingv1beta1.MachineAutoscaler {
-	return &autoscalingv1beta1.MachineAutoscaler{
+func NewMachineAutoscaler() *autoscalingv1.MachineAutoscaler {
+	return &autoscalingv1.MachineAutoscaler{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "MachineAutoscaler",
-			APIVersion: "autoscaling.openshift.io/v1beta1",
+			APIVersion: "autoscaling.openshift.io/v1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "test",
 			Namespace: TestNamespace,
 		},
-		Spec: autoscalingv1beta1.MachineAutoscalerSpec{
+		Spec: autoscalingv1.MachineAutoscalerSpec{
 			MinReplicas: TestMinReplicas,
 			MaxReplicas: TestMaxReplicas,
-			ScaleTargetRef: autoscalingv1beta1.CrossVersionObjectReference{
+			ScaleTargetRef: autoscalingv1.ScaleTargetReference{
 				APIVersion: "machine.openshift.io/v1beta1",
 				Kind:       "MachineSet",
 				Name:       "test",
@@ -44,19 +53,19 @@ func TestValidate(t *testing.T) {
 	testCases := []struct {
 		label      string
 		expectedOk bool
-		maFunc     func() *autoscalingv1beta1.MachineAutoscaler
+		maFunc     func() *autoscalingv1.MachineAutoscaler
 	}{
 		{
 			label:      "MachineAutoscaler is valid",
 			expectedOk: true,
-			maFunc: func() *autoscalingv1beta1.MachineAutoscaler {
+			maFunc: func() *autoscalingv1.MachineAutoscaler {
 				return ma.DeepCopy()
 			},
 		},
 		{
 			label:      "MachineAutoscaler has negative MinReplicas",
 			expectedOk: false,
-			maFunc: func() *autoscalingv1beta1.MachineAutoscaler {
+			maFunc: func() *autoscalingv1.MachineAutoscaler {
 				ma := ma.DeepCopy()
 				ma.Spec.MinReplicas = -10
 				return ma
@@ -65,7 +74,7 @@ func TestValidate(t *testing.T) {
 		{
 			label:      "MachineAutoscaler has negative MaxReplicas",
 			expectedOk: false,
-			maFunc: func() *autoscalingv1beta1.MachineAutoscaler {
+			maFunc: func() *autoscalingv1.MachineAutoscaler {
 				ma := ma.DeepCopy()
 				ma.Spec.MaxReplicas = -10
 				return ma
@@ -74,7 +83,7 @@ func TestValidate(t *testing.T) {
 		{
 			label:      "MachineAutoscaler has MaxReplicas lower than MinReplicas",
 			expectedOk: false,
-			maFunc: func() *autoscalingv1beta1.MachineAutoscaler {
+			maFunc: func() *autoscalingv1.MachineAutoscaler {
 				ma := ma.DeepCopy()
 				ma.Spec.MinReplicas = 8
 				ma.Spec.MaxReplicas = 2
@@ -97,3 +106,258 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+// validationFailures returns the current value of the validation failure
+// counter for MachineAutoscalers with the given reason.
+func validationFailures(t *testing.T, reason string) float64 {
+	t.Helper()
+
+	m := &dto.Metric{}
+	if err := metrics.ValidationFailures.WithLabelValues(metrics.ResourceMachineAutoscaler, reason).Write(m); err != nil {
+		t.Fatalf("failed to read metric: %v", err)
+	}
+
+	return m.GetCounter().GetValue()
+}
+
+func TestValidationFailureMetric(t *testing.T) {
+	client := fakeclient.NewClientBuilder().Build()
+	validator := NewValidator(client, scheme.Scheme)
+
+	ma := NewMachineAutoscaler()
+	ma.Spec.MinReplicas = 8
+	ma.Spec.MaxReplicas = 2
+
+	before := validationFailures(t, "MaxReplicasBelowMin")
+
+	// Validation outside of admission, e.g. by the reconciler, is not
+	// counted as a failure.
+	if res := validator.Validate(ma); res.IsValid() {
+		t.Fatal("expected validation to fail")
+	}
+
+	if got := validationFailures(t, "MaxReplicasBelowMin"); got != before {
+		t.Errorf("got %v validation failures after Validate, want %v", got, before)
+	}
+
+	raw, err := json.Marshal(ma)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	res := validator.Handle(context.TODO(), admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	})
+
+	if res.Allowed {
+		t.Fatal("expected request to be denied")
+	}
+
+	if got := validationFailures(t, "MaxReplicasBelowMin"); got != before+1 {
+		t.Errorf("got %v validation failures after Handle, want %v", got, before+1)
+	}
+}
+
+func TestValidateTarget(t *testing.T) {
+	owned := newMachineTarget("owned")
+	owned.SetOwner(&metav1.ObjectMeta{Namespace: TestNamespace, Name: "other"})
+
+	ownedBySelf := newMachineTarget("owned-by-self")
+	ownedBySelf.SetOwner(&metav1.ObjectMeta{Namespace: TestNamespace, Name: "test"})
+
+	free := newMachineTarget("free")
+
+	r := newFakeReconciler(Config{
+		Namespace:           TestNamespace,
+		SupportedTargetGVKs: DefaultSupportedTargetGVKs(),
+	}, owned.ToUnstructured(), ownedBySelf.ToUnstructured(), free.ToUnstructured())
+
+	validator := NewValidator(r.client, scheme.Scheme)
+	validator.targets = r
+
+	testCases := []struct {
+		label           string
+		expectedOk      bool
+		expectedWarning bool
+		maFunc          func() *autoscalingv1.MachineAutoscaler
+	}{
+		{
+			label:      "Target is not owned",
+			expectedOk: true,
+			maFunc: func() *autoscalingv1.MachineAutoscaler {
+				ma := NewMachineAutoscaler()
+				setTarget(ma, free)
+				return ma
+			},
+		},
+		{
+			label:      "Target is owned by the MachineAutoscaler",
+			expectedOk: true,
+			maFunc: func() *autoscalingv1.MachineAutoscaler {
+				ma := NewMachineAutoscaler()
+				setTarget(ma, ownedBySelf)
+				return ma
+			},
+		},
+		{
+			label:      "Target is owned by another MachineAutoscaler",
+			expectedOk: false,
+			maFunc: func() *autoscalingv1.MachineAutoscaler {
+				ma := NewMachineAutoscaler()
+				setTarget(ma, owned)
+				return ma
+			},
+		},
+		{
+			label:      "Target is not supported",
+			expectedOk: false,
+			maFunc: func() *autoscalingv1.MachineAutoscaler {
+				ma := NewMachineAutoscaler()
+				ma.Spec.ScaleTargetRef.APIVersion = "cluster.x-k8s.io/v1beta1"
+				ma.Spec.ScaleTargetRef.Kind = "MachineDeployment"
+				return ma
+			},
+		},
+		{
+			label:           "Target does not exist",
+			expectedOk:      true,
+			expectedWarning: true,
+			maFunc: func() *autoscalingv1.MachineAutoscaler {
+				ma := NewMachineAutoscaler()
+				ma.Spec.ScaleTargetRef.Name = "missing"
+				return ma
+			},
+		},
+		{
+			label:      "MachineAutoscaler is being deleted",
+			expectedOk: true,
+			maFunc: func() *autoscalingv1.MachineAutoscaler {
+				ma := NewMachineAutoscaler()
+				setTarget(ma, owned)
+				now := metav1.Now()
+				ma.SetDeletionTimestamp(&now)
+				return ma
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			res := validator.ValidateTarget(tc.maFunc())
+
+			if res.IsValid() != tc.expectedOk {
+				t.Errorf("got %v, want %v, err: %v", res.IsValid(), tc.expectedOk, res.Errors)
+			}
+
+			if (len(res.Warnings) > 0) != tc.expectedWarning {
+				t.Errorf("got warnings %v, want warning: %v", res.Warnings, tc.expectedWarning)
+			}
+		})
+	}
+}
+
+func TestValidateCrossResource(t *testing.T) {
+	ca := &autoscalingv1.ClusterAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: autoscalingv1.ClusterAutoscalerSpec{
+			ResourceLimits: &autoscalingv1.ResourceLimits{
+				MaxNodesTotal: ptr.To[int32](10),
+			},
+		},
+	}
+
+	other := NewMachineAutoscaler()
+	other.Name = "other"
+	other.Spec.MinReplicas = 6
+
+	// The live version of the admitted MachineAutoscaler is replaced.
+	live := NewMachineAutoscaler()
+	live.Spec.MinReplicas = 8
+
+	client := fakeclient.NewClientBuilder().WithScheme(scheme.Scheme).WithRuntimeObjects(ca, other, live).Build()
+
+	testCases := []struct {
+		label           string
+		minReplicas     int32
+		oldMinReplicas  *int32
+		strict          bool
+		expectedOk      bool
+		expectedWarning bool
+	}{
+		{
+			label:       "MinReplicas within the node limit",
+			minReplicas: 4,
+			expectedOk:  true,
+		},
+		{
+			label:           "MinReplicas exceeding the node limit",
+			minReplicas:     5,
+			expectedOk:      true,
+			expectedWarning: true,
+		},
+		{
+			label:       "MinReplicas exceeding the node limit in strict mode",
+			minReplicas: 5,
+			strict:      true,
+			expectedOk:  false,
+		},
+		{
+			label:          "MinReplicas raised beyond the node limit in strict mode",
+			minReplicas:    5,
+			oldMinReplicas: ptr.To[int32](4),
+			strict:         true,
+			expectedOk:     false,
+		},
+		{
+			label:           "Update keeping MinReplicas beyond the node limit in strict mode",
+			minReplicas:     5,
+			oldMinReplicas:  ptr.To[int32](5),
+			strict:          true,
+			expectedOk:      true,
+			expectedWarning: true,
+		},
+		{
+			label:           "MinReplicas lowered but still beyond the node limit in strict mode",
+			minReplicas:     5,
+			oldMinReplicas:  ptr.To[int32](7),
+			strict:          true,
+			expectedOk:      true,
+			expectedWarning: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			validator := NewValidator(client, scheme.Scheme)
+			validator.strict = tc.strict
+
+			ma := NewMachineAutoscaler()
+			ma.Spec.MinReplicas = tc.minReplicas
+
+			var old *autoscalingv1.MachineAutoscaler
+			if tc.oldMinReplicas != nil {
+				old = NewMachineAutoscaler()
+				old.Spec.MinReplicas = *tc.oldMinReplicas
+			}
+
+			res := validator.ValidateCrossResource(ma, old)
+
+			if res.IsValid() != tc.expectedOk {
+				t.Errorf("got %v, want %v, err: %v", res.IsValid(), tc.expectedOk, res.Errors)
+			}
+
+			if (len(res.Warnings) > 0) != tc.expectedWarning {
+				t.Errorf("got warnings %v, want warning: %v", res.Warnings, tc.expectedWarning)
+			}
+
+			for _, w := range res.Warnings {
+				if !strings.Contains(w, "other (6)") || !strings.Contains(w, "test (5)") {
+					t.Errorf("expected warning to name the MachineAutoscalers involved, got %q", w)
+				}
+			}
+		})
+	}
+}
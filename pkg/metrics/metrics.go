@@ -0,0 +1,266 @@
+package metrics
+
+import (
+	"sync"
+
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// metricsNamespace is the prefix used for all metrics exported by the operator.
+const metricsNamespace = "cluster_autoscaler_operator"
+
+// Resource label values used to identify the kind of object a metric refers to.
+const (
+	ResourceClusterAutoscaler = "ClusterAutoscaler"
+	ResourceMachineAutoscaler = "MachineAutoscaler"
+)
+
+// Admission decision label values.
+const (
+	AdmissionAllowed = "allowed"
+	AdmissionDenied  = "denied"
+	AdmissionErrored = "errored"
+)
+
+// MachineAutoscaler condition label values.  These describe the outcome of the
+// most recent reconcile of a MachineAutoscaler.
+const (
+	ConditionReady          = "Ready"
+	ConditionInvalid        = "Invalid"
+	ConditionTargetNotFound = "TargetNotFound"
+	ConditionTargetError    = "TargetError"
+	ConditionTargetConflict = "TargetConflict"
+	ConditionUpdateFailed   = "UpdateFailed"
+)
+
+var (
+	// MachineAutoscalerMinReplicas is the configured minimum number of
+	// replicas for each MachineAutoscaler target.
+	MachineAutoscalerMinReplicas = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "machineautoscaler",
+			Name:      "min_replicas",
+			Help:      "Configured minimum replicas of a MachineAutoscaler target.",
+		}, []string{"namespace", "name", "target_kind", "target_name"},
+	)
+
+	// MachineAutoscalerMaxReplicas is the configured maximum number of
+	// replicas for each MachineAutoscaler target.
+	MachineAutoscalerMaxReplicas = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "machineautoscaler",
+			Name:      "max_replicas",
+			Help:      "Configured maximum replicas of a MachineAutoscaler target.",
+		}, []string{"namespace", "name", "target_kind", "target_name"},
+	)
+
+	// MachineAutoscalerCurrentReplicas is the number of replicas currently
+	// requested by each MachineAutoscaler target.
+	MachineAutoscalerCurrentReplicas = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "machineautoscaler",
+			Name:      "current_replicas",
+			Help:      "Current replicas of a MachineAutoscaler target.",
+		}, []string{"namespace", "name", "target_kind", "target_name"},
+	)
+
+	// MachineAutoscalers is the number of MachineAutoscalers by the condition
+	// observed during their most recent reconcile.
+	MachineAutoscalers = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "machineautoscalers",
+			Help:      "Number of MachineAutoscalers by condition.",
+		}, []string{"condition"},
+	)
+
+	// ClusterAutoscalerResourceLimit is the resource limits configured on the
+	// ClusterAutoscaler.
+	ClusterAutoscalerResourceLimit = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "clusterautoscaler",
+			Name:      "resource_limit",
+			Help:      "Resource limits configured on the ClusterAutoscaler.",
+		}, []string{"resource", "bound"},
+	)
+
+	// ValidationFailures counts admission requests denied by the validating
+	// webhooks, by resource and reason.
+	ValidationFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "validation_failures_total",
+			Help:      "Number of admission requests denied by validation, by resource and reason.",
+		}, []string{"resource", "reason"},
+	)
+
+	// WebhookAdmissionDecisions counts admission webhook decisions by resource
+	// and decision.
+	WebhookAdmissionDecisions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "webhook_admission_decisions_total",
+			Help:      "Number of admission webhook decisions by resource and decision.",
+		}, []string{"resource", "decision"},
+	)
+
+	// TargetOwnershipConflicts counts attempts by a MachineAutoscaler to claim
+	// a target already owned by another MachineAutoscaler.
+	TargetOwnershipConflicts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "machineautoscaler",
+			Name:      "target_ownership_conflicts_total",
+			Help:      "Number of times a MachineAutoscaler target was found owned by another MachineAutoscaler.",
+		}, []string{"namespace", "name", "target_kind", "target_name"},
+	)
+)
+
+// conditions tracks the last observed condition of each MachineAutoscaler so
+// that the MachineAutoscalers gauge can be recomputed as conditions change.
+var conditions = &conditionTracker{
+	conditions: map[types.NamespacedName]string{},
+}
+
+type conditionTracker struct {
+	sync.Mutex
+	conditions map[types.NamespacedName]string
+}
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		MachineAutoscalerMinReplicas,
+		MachineAutoscalerMaxReplicas,
+		MachineAutoscalerCurrentReplicas,
+		MachineAutoscalers,
+		ClusterAutoscalerResourceLimit,
+		ValidationFailures,
+		WebhookAdmissionDecisions,
+		TargetOwnershipConflicts,
+	)
+}
+
+// SetMachineAutoscalerReplicas records the configured and current replicas for
+// the given MachineAutoscaler target.  Series for any previous target of the
+// MachineAutoscaler are removed.
+func SetMachineAutoscalerReplicas(ma types.NamespacedName, targetKind, targetName string, min, max int32, current int64) {
+	deleteMachineAutoscalerReplicas(ma)
+
+	labels := prometheus.Labels{
+		"namespace":   ma.Namespace,
+		"name":        ma.Name,
+		"target_kind": targetKind,
+		"target_name": targetName,
+	}
+
+	MachineAutoscalerMinReplicas.With(labels).Set(float64(min))
+	MachineAutoscalerMaxReplicas.With(labels).Set(float64(max))
+	MachineAutoscalerCurrentReplicas.With(labels).Set(float64(current))
+}
+
+// SetMachineAutoscalerCondition records the condition observed for the given
+// MachineAutoscaler and updates the per-condition counts.
+func SetMachineAutoscalerCondition(ma types.NamespacedName, condition string) {
+	conditions.Lock()
+	defer conditions.Unlock()
+
+	conditions.conditions[ma] = condition
+	conditions.update()
+}
+
+// DeleteMachineAutoscaler removes all series recorded for the given
+// MachineAutoscaler, e.g. after it has been deleted.
+func DeleteMachineAutoscaler(ma types.NamespacedName) {
+	deleteMachineAutoscalerReplicas(ma)
+
+	conditions.Lock()
+	defer conditions.Unlock()
+
+	delete(conditions.conditions, ma)
+	conditions.update()
+}
+
+// SetClusterAutoscalerResourceLimits records the given ClusterAutoscaler
+// resource limits, replacing any previously recorded limits.  A nil value
+// clears the recorded limits.
+func SetClusterAutoscalerResourceLimits(rl *autoscalingv1.ResourceLimits) {
+	ClusterAutoscalerResourceLimit.Reset()
+
+	if rl == nil {
+		return
+	}
+
+	if rl.MaxNodesTotal != nil {
+		ClusterAutoscalerResourceLimit.WithLabelValues("nodes", "max").Set(float64(*rl.MaxNodesTotal))
+	}
+
+	if rl.Cores != nil {
+		ClusterAutoscalerResourceLimit.WithLabelValues("cores", "min").Set(float64(rl.Cores.Min))
+		ClusterAutoscalerResourceLimit.WithLabelValues("cores", "max").Set(float64(rl.Cores.Max))
+	}
+
+	if rl.Memory != nil {
+		ClusterAutoscalerResourceLimit.WithLabelValues("memory_gib", "min").Set(float64(rl.Memory.Min))
+		ClusterAutoscalerResourceLimit.WithLabelValues("memory_gib", "max").Set(float64(rl.Memory.Max))
+	}
+
+	for _, gpu := range rl.GPUS {
+		resource := "gpu_" + gpu.Type
+		ClusterAutoscalerResourceLimit.WithLabelValues(resource, "min").Set(float64(gpu.Min))
+		ClusterAutoscalerResourceLimit.WithLabelValues(resource, "max").Set(float64(gpu.Max))
+	}
+}
+
+// RecordValidationFailure increments the validation failure count for the
+// given resource and reason.
+func RecordValidationFailure(resource, reason string) {
+	ValidationFailures.WithLabelValues(resource, reason).Inc()
+}
+
+// RecordAdmissionDecision increments the admission decision count for the
+// given resource and decision.
+func RecordAdmissionDecision(resource, decision string) {
+	WebhookAdmissionDecisions.WithLabelValues(resource, decision).Inc()
+}
+
+// RecordTargetOwnershipConflict increments the ownership conflict count for
+// the given MachineAutoscaler and target.
+func RecordTargetOwnershipConflict(ma types.NamespacedName, targetKind, targetName string) {
+	TargetOwnershipConflicts.WithLabelValues(ma.Namespace, ma.Name, targetKind, targetName).Inc()
+}
+
+// deleteMachineAutoscalerReplicas removes the replica series for the given
+// MachineAutoscaler regardless of target.
+func deleteMachineAutoscalerReplicas(ma types.NamespacedName) {
+	labels := prometheus.Labels{
+		"namespace": ma.Namespace,
+		"name":      ma.Name,
+	}
+
+	MachineAutoscalerMinReplicas.DeletePartialMatch(labels)
+	MachineAutoscalerMaxReplicas.DeletePartialMatch(labels)
+	MachineAutoscalerCurrentReplicas.DeletePartialMatch(labels)
+}
+
+// update recomputes the MachineAutoscalers gauge from the tracked conditions.
+// The caller must hold the lock.
+func (c *conditionTracker) update() {
+	counts := map[string]int{}
+
+	for _, condition := range c.conditions {
+		counts[condition]++
+	}
+
+	MachineAutoscalers.Reset()
+
+	for condition, count := range counts {
+		MachineAutoscalers.WithLabelValues(condition).Set(float64(count))
+	}
+}
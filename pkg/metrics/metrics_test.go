@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"testing"
+
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+)
+
+// gaugeValue returns the value of the gauge with the given labels.
+func gaugeValue(t *testing.T, vec *prometheus.GaugeVec, labels ...string) float64 {
+	t.Helper()
+
+	m := &dto.Metric{}
+	if err := vec.WithLabelValues(labels...).Write(m); err != nil {
+		t.Fatalf("failed to read metric: %v", err)
+	}
+
+	return m.GetGauge().GetValue()
+}
+
+// seriesCount returns the number of series currently exported by a collector.
+func seriesCount(c prometheus.Collector) int {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		c.Collect(ch)
+		close(ch)
+	}()
+
+	count := 0
+	for range ch {
+		count++
+	}
+
+	return count
+}
+
+func TestMachineAutoscalerConditions(t *testing.T) {
+	maA := types.NamespacedName{Namespace: "test", Name: "a"}
+	maB := types.NamespacedName{Namespace: "test", Name: "b"}
+	maC := types.NamespacedName{Namespace: "test", Name: "c"}
+
+	SetMachineAutoscalerCondition(maA, ConditionReady)
+	SetMachineAutoscalerCondition(maB, ConditionReady)
+	SetMachineAutoscalerCondition(maC, ConditionTargetConflict)
+
+	if got := gaugeValue(t, MachineAutoscalers, ConditionReady); got != 2 {
+		t.Errorf("got %v %s MachineAutoscalers, want 2", got, ConditionReady)
+	}
+
+	if got := gaugeValue(t, MachineAutoscalers, ConditionTargetConflict); got != 1 {
+		t.Errorf("got %v %s MachineAutoscalers, want 1", got, ConditionTargetConflict)
+	}
+
+	// A condition change moves the MachineAutoscaler between counts.
+	SetMachineAutoscalerCondition(maC, ConditionReady)
+	DeleteMachineAutoscaler(maA)
+
+	if got := gaugeValue(t, MachineAutoscalers, ConditionReady); got != 2 {
+		t.Errorf("got %v %s MachineAutoscalers, want 2", got, ConditionReady)
+	}
+
+	if got := seriesCount(MachineAutoscalers); got != 1 {
+		t.Errorf("got %d condition series, want 1", got)
+	}
+
+	DeleteMachineAutoscaler(maB)
+	DeleteMachineAutoscaler(maC)
+}
+
+func TestSetMachineAutoscalerReplicas(t *testing.T) {
+	ma := types.NamespacedName{Namespace: "test", Name: "replicas"}
+
+	SetMachineAutoscalerReplicas(ma, "MachineSet", "old", 1, 3, 2)
+	SetMachineAutoscalerReplicas(ma, "MachineSet", "new", 2, 5, 4)
+
+	if got := seriesCount(MachineAutoscalerMinReplicas); got != 1 {
+		t.Errorf("got %d min replica series, want 1", got)
+	}
+
+	labels := []string{ma.Namespace, ma.Name, "MachineSet", "new"}
+
+	if got := gaugeValue(t, MachineAutoscalerMinReplicas, labels...); got != 2 {
+		t.Errorf("got min replicas %v, want 2", got)
+	}
+
+	if got := gaugeValue(t, MachineAutoscalerMaxReplicas, labels...); got != 5 {
+		t.Errorf("got max replicas %v, want 5", got)
+	}
+
+	if got := gaugeValue(t, MachineAutoscalerCurrentReplicas, labels...); got != 4 {
+		t.Errorf("got current replicas %v, want 4", got)
+	}
+
+	DeleteMachineAutoscaler(ma)
+
+	if got := seriesCount(MachineAutoscalerMaxReplicas); got != 0 {
+		t.Errorf("got %d max replica series after delete, want 0", got)
+	}
+}
+
+func TestSetClusterAutoscalerResourceLimits(t *testing.T) {
+	SetClusterAutoscalerResourceLimits(&autoscalingv1.ResourceLimits{
+		MaxNodesTotal: ptr.To[int32](24),
+		Cores:         &autoscalingv1.ResourceRange{Min: 8, Max: 128},
+		GPUS: []autoscalingv1.GPULimit{
+			{Type: "nvidia-t4", Min: 0, Max: 4},
+		},
+	})
+
+	if got := gaugeValue(t, ClusterAutoscalerResourceLimit, "nodes", "max"); got != 24 {
+		t.Errorf("got max nodes %v, want 24", got)
+	}
+
+	if got := gaugeValue(t, ClusterAutoscalerResourceLimit, "cores", "min"); got != 8 {
+		t.Errorf("got min cores %v, want 8", got)
+	}
+
+	if got := gaugeValue(t, ClusterAutoscalerResourceLimit, "gpu_nvidia-t4", "max"); got != 4 {
+		t.Errorf("got max GPUs %v, want 4", got)
+	}
+
+	if got := seriesCount(ClusterAutoscalerResourceLimit); got != 5 {
+		t.Errorf("got %d limit series, want 5", got)
+	}
+
+	SetClusterAutoscalerResourceLimits(nil)
+
+	if got := seriesCount(ClusterAutoscalerResourceLimit); got != 0 {
+		t.Errorf("got %d limit series after clearing, want 0", got)
+	}
+}
@@ -0,0 +1,147 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// AutoscalerStatusConfigMapName is the name of the ConfigMap the
+// cluster-autoscaler writes its status to, in its namespace.
+const AutoscalerStatusConfigMapName = "cluster-autoscaler-status"
+
+// autoscalerStatusKey is the key of the status in the ConfigMap.
+const autoscalerStatusKey = "status"
+
+// Reasons used in status conditions for the cluster-autoscaler's runtime
+// health.
+const (
+	ReasonAutoscalerUnsafe  = "AutoscalerUnsafe"
+	ReasonNodeGroupsBackoff = "NodeGroupsBackoff"
+)
+
+// Values of the cluster-autoscaler status, see
+// https://github.com/kubernetes/autoscaler/blob/master/cluster-autoscaler/clusterstate/api/types.go
+const (
+	autoscalerRunning = "Running"
+	clusterUnhealthy  = "Unhealthy"
+	scaleUpBackoff    = "Backoff"
+)
+
+// autoscalerStatus is the subset of the status written by the
+// cluster-autoscaler used to check its runtime health.
+type autoscalerStatus struct {
+	AutoscalerStatus string `json:"autoscalerStatus"`
+	Message          string `json:"message,omitempty"`
+
+	ClusterWide struct {
+		Health struct {
+			Status string `json:"status"`
+		} `json:"health"`
+	} `json:"clusterWide"`
+
+	NodeGroups []struct {
+		Name    string `json:"name"`
+		ScaleUp struct {
+			Status      string `json:"status"`
+			BackoffInfo struct {
+				ErrorCode    string `json:"errorCode,omitempty"`
+				ErrorMessage string `json:"errorMessage,omitempty"`
+			} `json:"backoffInfo,omitempty"`
+		} `json:"scaleUp"`
+	} `json:"nodeGroups,omitempty"`
+}
+
+// AutoscalerHealthProblem describes why a running cluster-autoscaler is
+// unable to autoscale.
+type AutoscalerHealthProblem struct {
+	Reason  string
+	Message string
+}
+
+// CheckAutoscalerHealth checks the runtime health of the cluster-autoscaler,
+// as reported in its status ConfigMap.  It returns nil if the autoscaler is
+// healthy, or its health is not known yet, e.g. before the ConfigMap is
+// written or while the autoscaler is initializing.  This is independent of
+// the rollout of the Deployment, which is checked by CheckClusterAutoscaler.
+func (r *StatusReporter) CheckAutoscalerHealth() (*AutoscalerHealthProblem, error) {
+	cm := &corev1.ConfigMap{}
+	key := client.ObjectKey{
+		Namespace: r.config.ClusterAutoscalerNamespace,
+		Name:      AutoscalerStatusConfigMapName,
+	}
+
+	if err := r.client.Get(context.TODO(), key, cm); err != nil {
+		if errors.IsNotFound(err) {
+			klog.V(2).Info("No cluster-autoscaler status ConfigMap, skipping health check.")
+			return nil, nil
+		}
+
+		klog.Errorf("Error getting cluster-autoscaler status ConfigMap: %v", err)
+		return nil, err
+	}
+
+	return autoscalerHealth(cm), nil
+}
+
+// autoscalerHealth returns the problem reported in the given
+// cluster-autoscaler status ConfigMap, if any.
+func autoscalerHealth(cm *corev1.ConfigMap) *AutoscalerHealthProblem {
+	status := &autoscalerStatus{}
+
+	// Older cluster-autoscalers write a human readable status, which is not
+	// checked.
+	if err := yaml.Unmarshal([]byte(cm.Data[autoscalerStatusKey]), status); err != nil || status.AutoscalerStatus == "" {
+		klog.V(2).Info("Unable to parse cluster-autoscaler status, skipping health check.")
+		return nil
+	}
+
+	if status.AutoscalerStatus != autoscalerRunning {
+		klog.V(2).Infof("cluster-autoscaler is %s, skipping health check.", status.AutoscalerStatus)
+		return nil
+	}
+
+	if status.ClusterWide.Health.Status == clusterUnhealthy {
+		msg := "cluster-autoscaler reports the cluster is not safe to autoscale"
+		if status.Message != "" {
+			msg = fmt.Sprintf("%s: %s", msg, status.Message)
+		}
+
+		return &AutoscalerHealthProblem{
+			Reason:  ReasonAutoscalerUnsafe,
+			Message: msg,
+		}
+	}
+
+	var backoff []string
+
+	for _, ng := range status.NodeGroups {
+		if ng.ScaleUp.Status != scaleUpBackoff {
+			continue
+		}
+
+		if info := ng.ScaleUp.BackoffInfo; info.ErrorCode != "" {
+			backoff = append(backoff, fmt.Sprintf("%s (%s: %s)", ng.Name, info.ErrorCode, info.ErrorMessage))
+		} else {
+			backoff = append(backoff, ng.Name)
+		}
+	}
+
+	if len(backoff) > 0 {
+		sort.Strings(backoff)
+
+		return &AutoscalerHealthProblem{
+			Reason:  ReasonNodeGroupsBackoff,
+			Message: fmt.Sprintf("cluster-autoscaler node groups in scale-up backoff: %s", strings.Join(backoff, ", ")),
+		}
+	}
+
+	return nil
+}
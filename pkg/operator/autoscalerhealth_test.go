@@ -0,0 +1,251 @@
+package operator
+
+import (
+	"strings"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	fakeconfigclient "github.com/openshift/client-go/config/clientset/versioned/fake"
+	"github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const healthyAutoscalerStatus = `
+time: 2025-01-01 00:00:00.000000000 +0000 UTC
+autoscalerStatus: Running
+clusterWide:
+  health:
+    status: Healthy
+    nodeCounts:
+      registered:
+        total: 6
+        ready: 6
+  scaleUp:
+    status: NoActivity
+  scaleDown:
+    status: NoCandidates
+nodeGroups:
+- name: MachineSet/openshift-machine-api/worker-a
+  health:
+    status: Healthy
+  scaleUp:
+    status: NoActivity
+`
+
+const unhealthyAutoscalerStatus = `
+autoscalerStatus: Running
+clusterWide:
+  health:
+    status: Unhealthy
+    nodeCounts:
+      registered:
+        total: 6
+        ready: 2
+        unready: 4
+`
+
+const backoffAutoscalerStatus = `
+autoscalerStatus: Running
+clusterWide:
+  health:
+    status: Healthy
+  scaleUp:
+    status: Backoff
+nodeGroups:
+- name: MachineSet/openshift-machine-api/worker-b
+  scaleUp:
+    status: Backoff
+    backoffInfo:
+      errorCode: OutOfResource
+      errorMessage: insufficient capacity
+- name: MachineSet/openshift-machine-api/worker-a
+  scaleUp:
+    status: Backoff
+- name: MachineSet/openshift-machine-api/worker-c
+  scaleUp:
+    status: InProgress
+`
+
+const initializingAutoscalerStatus = `
+autoscalerStatus: Initializing
+clusterWide:
+  health:
+    status: Unhealthy
+`
+
+const legacyAutoscalerStatus = `Cluster-autoscaler status at 2025-01-01 00:00:00 +0000 UTC:
+Cluster-wide:
+  Health:      Unhealthy (ready=2 unready=4 notStarted=0 longNotStarted=0 registered=6 longUnregistered=0)
+`
+
+// newAutoscalerStatusConfigMap returns a cluster-autoscaler status ConfigMap
+// with the given status.
+func newAutoscalerStatusConfigMap(status string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      AutoscalerStatusConfigMapName,
+			Namespace: ClusterAutoscalerNamespace,
+		},
+		Data: map[string]string{
+			autoscalerStatusKey: status,
+		},
+	}
+}
+
+func TestCheckAutoscalerHealth(t *testing.T) {
+	testCases := []struct {
+		label           string
+		objects         []runtime.Object
+		expectedReason  string
+		expectedMessage string
+	}{
+		{
+			label:   "no status ConfigMap",
+			objects: []runtime.Object{},
+		},
+		{
+			label:   "healthy",
+			objects: []runtime.Object{newAutoscalerStatusConfigMap(healthyAutoscalerStatus)},
+		},
+		{
+			label:           "unhealthy",
+			objects:         []runtime.Object{newAutoscalerStatusConfigMap(unhealthyAutoscalerStatus)},
+			expectedReason:  ReasonAutoscalerUnsafe,
+			expectedMessage: "cluster-autoscaler reports the cluster is not safe to autoscale",
+		},
+		{
+			label:          "node groups in backoff",
+			objects:        []runtime.Object{newAutoscalerStatusConfigMap(backoffAutoscalerStatus)},
+			expectedReason: ReasonNodeGroupsBackoff,
+			expectedMessage: "cluster-autoscaler node groups in scale-up backoff: " +
+				"MachineSet/openshift-machine-api/worker-a, " +
+				"MachineSet/openshift-machine-api/worker-b (OutOfResource: insufficient capacity)",
+		},
+		{
+			label:   "initializing",
+			objects: []runtime.Object{newAutoscalerStatusConfigMap(initializingAutoscalerStatus)},
+		},
+		{
+			label:   "legacy status format",
+			objects: []runtime.Object{newAutoscalerStatusConfigMap(legacyAutoscalerStatus)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			reporter := &StatusReporter{
+				client:       fakeclient.NewFakeClient(tc.objects...),
+				configClient: fakeconfigclient.NewSimpleClientset(),
+				config:       &TestStatusReporterConfig,
+			}
+
+			startConfigInformers(t, reporter)
+
+			problem, err := reporter.CheckAutoscalerHealth()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tc.expectedReason == "" {
+				if problem != nil {
+					t.Errorf("expected no problem, got %+v", problem)
+				}
+
+				return
+			}
+
+			if problem == nil {
+				t.Fatalf("expected problem with reason %s, got none", tc.expectedReason)
+			}
+
+			if problem.Reason != tc.expectedReason || problem.Message != tc.expectedMessage {
+				t.Errorf("got %s: %q, want %s: %q", problem.Reason, problem.Message, tc.expectedReason, tc.expectedMessage)
+			}
+		})
+	}
+}
+
+func TestReportStatusAutoscalerHealth(t *testing.T) {
+	reporter := &StatusReporter{
+		client: fakeclient.NewFakeClient(
+			clusterAutoscaler,
+			deployment.WithReleaseVersion(ReleaseVersion).Object(),
+			newAutoscalerStatusConfigMap(unhealthyAutoscalerStatus),
+		),
+		configClient: fakeconfigclient.NewSimpleClientset(
+			machineAPI.WithConditions(AvailableConditions).Object(),
+		),
+		config: &TestStatusReporterConfig,
+	}
+
+	startConfigInformers(t, reporter)
+
+	for range DegradedCountThreshold {
+		if ok, err := reporter.ReportStatus(); !ok || err != nil {
+			t.Fatalf("got %t, %v, want true, nil", ok, err)
+		}
+	}
+
+	co, err := getClusterOperator(reporter)
+	if err != nil {
+		t.Fatalf("error getting ClusterOperator: %v", err)
+	}
+
+	// The unhealthy autoscaler is reported in the Available condition,
+	// rather than as degraded.
+	available := v1helpers.FindStatusCondition(co.Status.Conditions, configv1.OperatorAvailable)
+	if available == nil || available.Status != configv1.ConditionTrue || available.Reason != ReasonAutoscalerUnsafe {
+		t.Errorf("expected Available=True with reason %s, got %+v", ReasonAutoscalerUnsafe, available)
+	}
+
+	if available != nil && !strings.Contains(available.Message, "not safe to autoscale") {
+		t.Errorf("expected the Available message to report the problem, got %q", available.Message)
+	}
+
+	if !v1helpers.IsStatusConditionFalse(co.Status.Conditions, configv1.OperatorDegraded) {
+		t.Errorf("expected Degraded=False, got %+v", co.Status.Conditions)
+	}
+
+	if !v1helpers.IsStatusConditionFalse(co.Status.Conditions, configv1.OperatorProgressing) {
+		t.Errorf("expected Progressing=False, got %+v", co.Status.Conditions)
+	}
+}
+
+func TestReportStatusAutoscalerHealthDuringUpgrade(t *testing.T) {
+	reporter := &StatusReporter{
+		client: fakeclient.NewFakeClient(
+			clusterAutoscaler,
+			deployment.WithReleaseVersion(ReleaseVersion).Object(),
+			newAutoscalerStatusConfigMap(unhealthyAutoscalerStatus),
+		),
+		configClient: fakeconfigclient.NewSimpleClientset(
+			machineAPI.WithConditions(AvailableConditions).Object(),
+			clusterAutoscalerOperator.WithVersion("v99.0.0").Object(),
+		),
+		config: &TestStatusReporterConfig,
+	}
+
+	startConfigInformers(t, reporter)
+
+	if ok, err := reporter.ReportStatus(); ok || err != nil {
+		t.Fatalf("got %t, %v, want false, nil", ok, err)
+	}
+
+	co, err := getClusterOperator(reporter)
+	if err != nil {
+		t.Fatalf("error getting ClusterOperator: %v", err)
+	}
+
+	// The version change is reported even though the autoscaler is
+	// unhealthy.
+	if !v1helpers.IsStatusConditionTrue(co.Status.Conditions, configv1.OperatorProgressing) {
+		t.Errorf("expected Progressing=True, got %+v", co.Status.Conditions)
+	}
+
+	if v1helpers.IsStatusConditionTrue(co.Status.Conditions, configv1.OperatorDegraded) {
+		t.Errorf("expected Degraded not to be True, got %+v", co.Status.Conditions)
+	}
+}
@@ -0,0 +1,173 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	"github.com/openshift/cluster-autoscaler-operator/pkg/controller/clusterautoscaler"
+	"github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ClusterAPIOperatorName is the name of the ClusterOperator of the
+// cluster-api operator.
+const ClusterAPIOperatorName = "cluster-api"
+
+// Reasons used in status conditions for the Cluster API dependencies of the
+// cluster-autoscaler.
+const (
+	ReasonCheckClusterAPI       = "UnableToCheckClusterAPI"
+	ReasonClusterAPINotReady    = "ClusterAPINotReady"
+	ReasonClusterAPICRDsMissing = "ClusterAPICRDsMissing"
+)
+
+// infrastructureName is the name of the cluster Infrastructure object.
+const infrastructureName = "cluster"
+
+// clusterAPICRDTimeout is the timeout for reading the Cluster API CRDs from
+// the API server.
+const clusterAPICRDTimeout = 10 * time.Second
+
+// clusterAPICRDs are the CRDs used by the cluster-autoscaler to discover and
+// scale Cluster API node groups.
+var clusterAPICRDs = []string{
+	"machines.cluster.x-k8s.io",
+	"machinesets.cluster.x-k8s.io",
+	"machinedeployments.cluster.x-k8s.io",
+}
+
+// DependencyProblem describes why a dependency of the cluster-autoscaler is
+// not ready.
+type DependencyProblem struct {
+	Reason  string
+	Message string
+}
+
+// CheckClusterAPI checks the dependencies of the cluster-autoscaler's Cluster
+// API provider: the cluster-api operator, as reported to the CVO, and the
+// Cluster API CRDs.  The Infrastructure and ClusterOperator are read from the
+// config informers.  It returns nil if they are ready, if there is no
+// ClusterAutoscaler, or if the provider is not enabled on the cluster's
+// platform.
+func (r *StatusReporter) CheckClusterAPI() (*DependencyProblem, error) {
+	ca := &autoscalingv1.ClusterAutoscaler{}
+	caName := client.ObjectKey{Name: r.config.ClusterAutoscalerName}
+
+	if err := r.client.Get(context.TODO(), caName, ca); err != nil {
+		if errors.IsNotFound(err) {
+			klog.V(2).Info("No ClusterAutoscaler, skipping Cluster API checks.")
+			return nil, nil
+		}
+
+		klog.Errorf("Error getting ClusterAutoscaler: %v", err)
+		return nil, err
+	}
+
+	enabled, err := r.clusterAPIProviderEnabled()
+	if err != nil {
+		return nil, err
+	}
+
+	if !enabled {
+		return nil, nil
+	}
+
+	capi, err := r.configInformers.Config().V1().ClusterOperators().Lister().Get(ClusterAPIOperatorName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return &DependencyProblem{
+				Reason:  ReasonClusterAPINotReady,
+				Message: "cluster-api ClusterOperator not found",
+			}, nil
+		}
+
+		klog.Errorf("failed to get dependency cluster-api status: %v", err)
+		return nil, err
+	}
+
+	conds := capi.Status.Conditions
+
+	if !v1helpers.IsStatusConditionTrue(conds, configv1.OperatorAvailable) ||
+		!v1helpers.IsStatusConditionFalse(conds, configv1.OperatorDegraded) {
+		klog.Infof("cluster-api operator not ready yet")
+
+		return &DependencyProblem{
+			Reason:  ReasonClusterAPINotReady,
+			Message: "cluster-api not ready",
+		}, nil
+	}
+
+	// The CRDs are read from the API server, rather than the manager's
+	// cache, to avoid watching all CRDs of the cluster.
+	ctx, cancel := context.WithTimeout(context.Background(), clusterAPICRDTimeout)
+	defer cancel()
+
+	var missing []string
+
+	for _, name := range clusterAPICRDs {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+
+		if err := r.apiReader.Get(ctx, client.ObjectKey{Name: name}, crd); err != nil {
+			if errors.IsNotFound(err) {
+				missing = append(missing, name)
+				continue
+			}
+
+			klog.Errorf("Error getting CRD %s: %v", name, err)
+			return nil, err
+		}
+
+		if !crdEstablished(crd) {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return &DependencyProblem{
+			Reason:  ReasonClusterAPICRDsMissing,
+			Message: fmt.Sprintf("Cluster API CRDs not established: %s", strings.Join(missing, ", ")),
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// clusterAPIProviderEnabled returns true if the cluster-autoscaler runs with
+// its Cluster API provider on the cluster's platform.
+func (r *StatusReporter) clusterAPIProviderEnabled() (bool, error) {
+	infra, err := r.configInformers.Config().V1().Infrastructures().Lister().Get(infrastructureName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			klog.V(2).Info("No Infrastructure, skipping Cluster API checks.")
+			return false, nil
+		}
+
+		klog.Errorf("Error getting Infrastructure: %v", err)
+		return false, err
+	}
+
+	if infra.Status.PlatformStatus == nil {
+		return false, nil
+	}
+
+	return clusterautoscaler.ClusterAPIProviderEnabled(infra.Status.PlatformStatus.Type, r.config.FeatureGateAccessor), nil
+}
+
+// crdEstablished returns true if the given CRD is established, i.e. served
+// by the API server.
+func crdEstablished(crd *apiextensionsv1.CustomResourceDefinition) bool {
+	for _, cond := range crd.Status.Conditions {
+		if cond.Type == apiextensionsv1.Established {
+			return cond.Status == apiextensionsv1.ConditionTrue
+		}
+	}
+
+	return false
+}
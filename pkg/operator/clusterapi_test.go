@@ -0,0 +1,243 @@
+package operator
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	fakeconfigclient "github.com/openshift/client-go/config/clientset/versioned/fake"
+	"github.com/openshift/cluster-autoscaler-operator/test/helpers"
+	"github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
+	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var clusterAPI = helpers.NewTestClusterOperator(&configv1.ClusterOperator{
+	TypeMeta: metav1.TypeMeta{
+		Kind:       "ClusterOperator",
+		APIVersion: "config.openshift.io/v1",
+	},
+	ObjectMeta: metav1.ObjectMeta{
+		Name: ClusterAPIOperatorName,
+	},
+})
+
+// newInfrastructure returns the cluster Infrastructure with the given
+// platform type.
+func newInfrastructure(platformType configv1.PlatformType) *configv1.Infrastructure {
+	return &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: infrastructureName,
+		},
+		Status: configv1.InfrastructureStatus{
+			PlatformStatus: &configv1.PlatformStatus{
+				Type: platformType,
+			},
+		},
+	}
+}
+
+// newClusterAPICRD returns a CRD with the given name and established status.
+func newClusterAPICRD(name string, established bool) *apiextensionsv1.CustomResourceDefinition {
+	status := apiextensionsv1.ConditionFalse
+	if established {
+		status = apiextensionsv1.ConditionTrue
+	}
+
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	crd.Name = name
+	crd.Status.Conditions = []apiextensionsv1.CustomResourceDefinitionCondition{
+		{Type: apiextensionsv1.Established, Status: status},
+	}
+
+	return crd
+}
+
+// clusterAPICRDObjects returns established Cluster API CRDs.
+func clusterAPICRDObjects() []client.Object {
+	objs := []client.Object{}
+	for _, name := range clusterAPICRDs {
+		objs = append(objs, newClusterAPICRD(name, true))
+	}
+
+	return objs
+}
+
+func TestCheckClusterAPI(t *testing.T) {
+	clusterAPIEnabled := featuregates.NewHardcodedFeatureGateAccess(
+		[]configv1.FeatureGateName{"ClusterAPIMachineManagementAWS"},
+		[]configv1.FeatureGateName{},
+	)
+
+	clusterAPIDisabled := featuregates.NewHardcodedFeatureGateAccess(
+		[]configv1.FeatureGateName{},
+		[]configv1.FeatureGateName{"ClusterAPIMachineManagementAWS"},
+	)
+
+	testCases := []struct {
+		label           string
+		featureGates    featuregates.FeatureGateAccess
+		objects         []runtime.Object
+		crds            []client.Object
+		configObjs      []runtime.Object
+		expectedReason  string
+		expectedMessage string
+	}{
+		{
+			label:        "no ClusterAutoscaler",
+			featureGates: clusterAPIEnabled,
+			configObjs: []runtime.Object{
+				newInfrastructure(configv1.AWSPlatformType),
+				clusterAPI.WithConditions(DegradedConditions).Object(),
+			},
+		},
+		{
+			label:        "no infrastructure",
+			featureGates: clusterAPIEnabled,
+			objects:      []runtime.Object{clusterAutoscaler},
+		},
+		{
+			label:        "provider disabled",
+			featureGates: clusterAPIDisabled,
+			objects:      []runtime.Object{clusterAutoscaler},
+			configObjs: []runtime.Object{
+				newInfrastructure(configv1.AWSPlatformType),
+			},
+		},
+		{
+			label:        "unsupported platform",
+			featureGates: clusterAPIEnabled,
+			objects:      []runtime.Object{clusterAutoscaler},
+			configObjs: []runtime.Object{
+				newInfrastructure(configv1.NonePlatformType),
+			},
+		},
+		{
+			label:        "ready",
+			featureGates: clusterAPIEnabled,
+			objects:      []runtime.Object{clusterAutoscaler},
+			crds:         clusterAPICRDObjects(),
+			configObjs: []runtime.Object{
+				newInfrastructure(configv1.AWSPlatformType),
+				clusterAPI.WithConditions(AvailableConditions).Object(),
+			},
+		},
+		{
+			label:        "cluster-api missing",
+			featureGates: clusterAPIEnabled,
+			objects:      []runtime.Object{clusterAutoscaler},
+			crds:         clusterAPICRDObjects(),
+			configObjs: []runtime.Object{
+				newInfrastructure(configv1.AWSPlatformType),
+			},
+			expectedReason:  ReasonClusterAPINotReady,
+			expectedMessage: "cluster-api ClusterOperator not found",
+		},
+		{
+			label:        "cluster-api degraded",
+			featureGates: clusterAPIEnabled,
+			objects:      []runtime.Object{clusterAutoscaler},
+			crds:         clusterAPICRDObjects(),
+			configObjs: []runtime.Object{
+				newInfrastructure(configv1.AWSPlatformType),
+				clusterAPI.WithConditions(DegradedConditions).Object(),
+			},
+			expectedReason:  ReasonClusterAPINotReady,
+			expectedMessage: "cluster-api not ready",
+		},
+		{
+			label:        "CRDs missing",
+			featureGates: clusterAPIEnabled,
+			objects:      []runtime.Object{clusterAutoscaler},
+			crds: []client.Object{
+				newClusterAPICRD("machines.cluster.x-k8s.io", true),
+				newClusterAPICRD("machinedeployments.cluster.x-k8s.io", false),
+			},
+			configObjs: []runtime.Object{
+				newInfrastructure(configv1.AWSPlatformType),
+				clusterAPI.WithConditions(AvailableConditions).Object(),
+			},
+			expectedReason:  ReasonClusterAPICRDsMissing,
+			expectedMessage: "Cluster API CRDs not established: machinesets.cluster.x-k8s.io, machinedeployments.cluster.x-k8s.io",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			cfg := TestStatusReporterConfig
+			cfg.FeatureGateAccessor = tc.featureGates
+
+			// The CRDs are read with the permissions of the operator, and
+			// not from the cached client.
+			reporter := &StatusReporter{
+				client:       fakeclient.NewFakeClient(tc.objects...),
+				apiReader:    newRBACEnforcingClient(t, tc.crds...),
+				configClient: fakeconfigclient.NewSimpleClientset(tc.configObjs...),
+				config:       &cfg,
+			}
+
+			startConfigInformers(t, reporter)
+
+			problem, err := reporter.CheckClusterAPI()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tc.expectedReason == "" {
+				if problem != nil {
+					t.Errorf("expected no problem, got %+v", problem)
+				}
+
+				return
+			}
+
+			if problem == nil {
+				t.Fatalf("expected problem with reason %s, got none", tc.expectedReason)
+			}
+
+			if problem.Reason != tc.expectedReason || problem.Message != tc.expectedMessage {
+				t.Errorf("got %s: %q, want %s: %q", problem.Reason, problem.Message, tc.expectedReason, tc.expectedMessage)
+			}
+		})
+	}
+}
+
+func TestReportStatusClusterAPI(t *testing.T) {
+	cfg := TestStatusReporterConfig
+	cfg.FeatureGateAccessor = featuregates.NewHardcodedFeatureGateAccess(
+		[]configv1.FeatureGateName{"ClusterAPIMachineManagementAWS"},
+		[]configv1.FeatureGateName{},
+	)
+
+	reporter := &StatusReporter{
+		client:    fakeclient.NewFakeClient(clusterAutoscaler),
+		apiReader: newRBACEnforcingClient(t),
+		configClient: fakeconfigclient.NewSimpleClientset(
+			newInfrastructure(configv1.AWSPlatformType),
+			machineAPI.WithConditions(AvailableConditions).Object(),
+			clusterAPI.WithConditions(AvailableConditions).Object(),
+		),
+		config: &cfg,
+	}
+
+	startConfigInformers(t, reporter)
+
+	for range DegradedCountThreshold {
+		if ok, err := reporter.ReportStatus(); ok || err != nil {
+			t.Fatalf("got %t, %v, want false, nil", ok, err)
+		}
+	}
+
+	co, err := getClusterOperator(reporter)
+	if err != nil {
+		t.Fatalf("error getting ClusterOperator: %v", err)
+	}
+
+	degraded := v1helpers.FindStatusCondition(co.Status.Conditions, configv1.OperatorDegraded)
+	if degraded == nil || degraded.Status != configv1.ConditionTrue || degraded.Reason != ReasonClusterAPICRDsMissing {
+		t.Errorf("expected Degraded=True with reason %s, got %+v", ReasonClusterAPICRDsMissing, degraded)
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
@@ -58,8 +61,52 @@ const (
 	// server TLS assets.
 	DefaultWebhooksCertDir = "/etc/cluster-autoscaler-operator/tls"
 
+	// DefaultWebhooksSelfManagedCerts is the default value indicating whether
+	// the operator generates and rotates its own webhook certificates.
+	DefaultWebhooksSelfManagedCerts = false
+
+	// DefaultWebhooksCertSecretName is the default name of the Secret holding
+	// self-managed webhook certificates.
+	DefaultWebhooksCertSecretName = "cluster-autoscaler-operator-webhook-cert"
+
+	// DefaultWebhooksStrictValidation is the default value indicating whether
+	// cross-resource admission checks reject objects rather than warn.
+	DefaultWebhooksStrictValidation = false
+
+	// DefaultWebhooksFailurePolicy is the default failure policy of the
+	// admission webhooks registered with the API server.
+	DefaultWebhooksFailurePolicy = "Ignore"
+
+	// DefaultWebhooksTimeoutSeconds is the default number of seconds the API
+	// server waits for the admission webhooks to respond.
+	DefaultWebhooksTimeoutSeconds = 10
+
 	// DefaultMetricsPort is the default port to expose metrics.
 	DefaultMetricsPort = 8080
+
+	// DefaultMachineAutoscalerDegradedThreshold is the default percentage of
+	// MachineAutoscalers persistently failing to reconcile at which the
+	// operator is reported degraded.
+	DefaultMachineAutoscalerDegradedThreshold = 50
+
+	// DefaultStandaloneMode is the default standalone mode.
+	DefaultStandaloneMode = StandaloneModeAuto
+)
+
+// StandaloneMode controls whether the operator runs without the OpenShift
+// configuration APIs, e.g. on upstream Kubernetes clusters.
+type StandaloneMode string
+
+const (
+	// StandaloneModeAuto enables standalone mode when the OpenShift
+	// configuration APIs are not served by the cluster.
+	StandaloneModeAuto StandaloneMode = "Auto"
+
+	// StandaloneModeEnabled always enables standalone mode.
+	StandaloneModeEnabled StandaloneMode = "Enabled"
+
+	// StandaloneModeDisabled never enables standalone mode.
+	StandaloneModeDisabled StandaloneMode = "Disabled"
 )
 
 // Config represents the runtime configuration for the operator.
@@ -125,35 +172,105 @@ type Config struct {
 	// webhook server.
 	WebhooksCertDir string
 
+	// WebhooksSelfManagedCerts indicates whether the operator generates a CA
+	// and serving certificate for the admission webhook server, rather than
+	// relying on the service-ca-operator and WebhooksCertDir.
+	WebhooksSelfManagedCerts bool
+
+	// WebhooksCertSecretName is the name of the Secret, in the watch
+	// namespace, holding self-managed webhook certificates.
+	WebhooksCertSecretName string
+
+	// WebhooksStrictValidation indicates whether problems found by
+	// cross-resource admission checks, e.g. MachineAutoscaler minimum
+	// replicas exceeding the ClusterAutoscaler node limit, are returned as
+	// errors rather than warnings.
+	WebhooksStrictValidation bool
+
+	// WebhooksFailurePolicy is the failure policy of the admission webhooks
+	// registered with the API server, either "Ignore" or "Fail".
+	WebhooksFailurePolicy string
+
+	// WebhooksTimeoutSeconds is the number of seconds the API server waits
+	// for the admission webhooks to respond, between 1 and 30.
+	WebhooksTimeoutSeconds int32
+
+	// WebhooksNamespaceSelector is a label selector, in the format accepted
+	// by kubectl, limiting the namespaces whose objects are sent to the
+	// admission webhooks.  An empty selector matches all namespaces.
+	WebhooksNamespaceSelector string
+
+	// WebhooksObjectSelector is a label selector, in the format accepted by
+	// kubectl, limiting the objects sent to the admission webhooks.  An empty
+	// selector matches all objects.
+	WebhooksObjectSelector string
+
 	// metricsPort is the port the metrics are exposed.
 	MetricsPort int
+
+	// MachineAutoscalerDegradedThreshold is the percentage of
+	// MachineAutoscalers persistently failing to reconcile, between 0 and
+	// 100, at which the operator is reported degraded.  Zero means any
+	// persistently failing MachineAutoscaler.
+	MachineAutoscalerDegradedThreshold int
+
+	// StandaloneMode controls whether the operator runs in standalone mode,
+	// without relying on OpenShift APIs such as the cluster TLS profile,
+	// feature gates, infrastructure, and ClusterOperator status.
+	StandaloneMode StandaloneMode
+
+	// FeatureGates is the static set of feature gates used in standalone
+	// mode, keyed by name.  Gates not listed are considered disabled.
+	FeatureGates map[string]bool
+
+	// PlatformType is the static platform type used in standalone mode.
+	PlatformType string
 }
 
 // NewConfig returns a new Config object with defaults set.
 func NewConfig() *Config {
 	return &Config{
-		WatchNamespace:                 DefaultWatchNamespace,
-		LeaderElection:                 DefaultLeaderElection,
-		LeaderElectionNamespace:        DefaultLeaderElectionNamespace,
-		LeaderElectionID:               DefaultLeaderElectionID,
-		ClusterAutoscalerNamespace:     DefaultClusterAutoscalerNamespace,
-		ClusterAutoscalerName:          DefaultClusterAutoscalerName,
-		ClusterAutoscalerImage:         DefaultClusterAutoscalerImage,
-		ClusterAutoscalerReplicas:      DefaultClusterAutoscalerReplicas,
-		ClusterAutoscalerCloudProvider: DefaultClusterAutoscalerCloudProvider,
-		ClusterAutoscalerVerbosity:     DefaultClusterAutoscalerVerbosity,
-		WebhooksEnabled:                DefaultWebhooksEnabled,
-		WebhooksPort:                   DefaultWebhooksPort,
-		WebhooksCertDir:                DefaultWebhooksCertDir,
-		MetricsPort:                    DefaultMetricsPort,
+		WatchNamespace:                     DefaultWatchNamespace,
+		LeaderElection:                     DefaultLeaderElection,
+		LeaderElectionNamespace:            DefaultLeaderElectionNamespace,
+		LeaderElectionID:                   DefaultLeaderElectionID,
+		ClusterAutoscalerNamespace:         DefaultClusterAutoscalerNamespace,
+		ClusterAutoscalerName:              DefaultClusterAutoscalerName,
+		ClusterAutoscalerImage:             DefaultClusterAutoscalerImage,
+		ClusterAutoscalerReplicas:          DefaultClusterAutoscalerReplicas,
+		ClusterAutoscalerCloudProvider:     DefaultClusterAutoscalerCloudProvider,
+		ClusterAutoscalerVerbosity:         DefaultClusterAutoscalerVerbosity,
+		WebhooksEnabled:                    DefaultWebhooksEnabled,
+		WebhooksPort:                       DefaultWebhooksPort,
+		WebhooksCertDir:                    DefaultWebhooksCertDir,
+		WebhooksSelfManagedCerts:           DefaultWebhooksSelfManagedCerts,
+		WebhooksCertSecretName:             DefaultWebhooksCertSecretName,
+		WebhooksStrictValidation:           DefaultWebhooksStrictValidation,
+		WebhooksFailurePolicy:              DefaultWebhooksFailurePolicy,
+		WebhooksTimeoutSeconds:             DefaultWebhooksTimeoutSeconds,
+		MetricsPort:                        DefaultMetricsPort,
+		MachineAutoscalerDegradedThreshold: DefaultMachineAutoscalerDegradedThreshold,
+		StandaloneMode:                     DefaultStandaloneMode,
 	}
 }
 
 // ConfigFromEnvironment returns a new Config object with defaults
-// overridden by environment variables when set.
+// overridden by the configuration file named by the CONFIG_FILE environment
+// variable, if set, and by environment variables when set.
 func ConfigFromEnvironment() (*Config, error) {
 	config := NewConfig()
 
+	if path := os.Getenv(ConfigFileEnvVar); path != "" {
+		file, err := ReadConfigFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := file.apply(config); err != nil {
+			return nil, fmt.Errorf("error applying configuration file %s: %v", path, err)
+		}
+	}
+
 	if releaseVersion, ok := os.LookupEnv("RELEASE_VERSION"); ok {
 		config.ReleaseVersion = releaseVersion
 	}
@@ -230,6 +347,66 @@ func ConfigFromEnvironment() (*Config, error) {
 		config.WebhooksCertDir = webhooksCertDir
 	}
 
+	if selfManagedCerts, ok := os.LookupEnv("WEBHOOKS_SELF_MANAGED_CERTS"); ok {
+		selfManaged, err := strconv.ParseBool(selfManagedCerts)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing WEBHOOKS_SELF_MANAGED_CERTS (%q) environment variable: %v", selfManagedCerts, err)
+		}
+
+		config.WebhooksSelfManagedCerts = selfManaged
+	}
+
+	if certSecretName, ok := os.LookupEnv("WEBHOOKS_CERT_SECRET_NAME"); ok {
+		config.WebhooksCertSecretName = certSecretName
+	}
+
+	if webhooksStrict, ok := os.LookupEnv("WEBHOOKS_STRICT_VALIDATION"); ok {
+		strict, err := strconv.ParseBool(webhooksStrict)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing WEBHOOKS_STRICT_VALIDATION (%q) environment variable: %v", webhooksStrict, err)
+		}
+
+		config.WebhooksStrictValidation = strict
+	}
+
+	if failurePolicy, ok := os.LookupEnv("WEBHOOKS_FAILURE_POLICY"); ok {
+		switch failurePolicy {
+		case "Ignore", "Fail":
+			config.WebhooksFailurePolicy = failurePolicy
+		default:
+			return nil, fmt.Errorf("error parsing WEBHOOKS_FAILURE_POLICY (%q) environment variable: must be one of Ignore, Fail", failurePolicy)
+		}
+	}
+
+	if webhooksTimeout, ok := os.LookupEnv("WEBHOOKS_TIMEOUT_SECONDS"); ok {
+		v, err := strconv.ParseInt(webhooksTimeout, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing WEBHOOKS_TIMEOUT_SECONDS (%q) environment variable: %v", webhooksTimeout, err)
+		}
+
+		if v < 1 || v > 30 {
+			return nil, fmt.Errorf("error parsing WEBHOOKS_TIMEOUT_SECONDS (%q) environment variable: must be between 1 and 30", webhooksTimeout)
+		}
+
+		config.WebhooksTimeoutSeconds = int32(v)
+	}
+
+	if nsSelector, ok := os.LookupEnv("WEBHOOKS_NAMESPACE_SELECTOR"); ok {
+		if _, err := metav1.ParseToLabelSelector(nsSelector); err != nil {
+			return nil, fmt.Errorf("error parsing WEBHOOKS_NAMESPACE_SELECTOR (%q) environment variable: %v", nsSelector, err)
+		}
+
+		config.WebhooksNamespaceSelector = nsSelector
+	}
+
+	if objSelector, ok := os.LookupEnv("WEBHOOKS_OBJECT_SELECTOR"); ok {
+		if _, err := metav1.ParseToLabelSelector(objSelector); err != nil {
+			return nil, fmt.Errorf("error parsing WEBHOOKS_OBJECT_SELECTOR (%q) environment variable: %v", objSelector, err)
+		}
+
+		config.WebhooksObjectSelector = objSelector
+	}
+
 	if metricsPort, ok := os.LookupEnv("METRICS_PORT"); ok {
 		v, err := strconv.Atoi(metricsPort)
 		if err != nil {
@@ -239,5 +416,67 @@ func ConfigFromEnvironment() (*Config, error) {
 		config.MetricsPort = v
 	}
 
+	if maThreshold, ok := os.LookupEnv("MACHINE_AUTOSCALER_DEGRADED_THRESHOLD"); ok {
+		v, err := strconv.Atoi(maThreshold)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing MACHINE_AUTOSCALER_DEGRADED_THRESHOLD (%q) environment variable: %v", maThreshold, err)
+		}
+
+		if v < 0 || v > 100 {
+			return nil, fmt.Errorf("error parsing MACHINE_AUTOSCALER_DEGRADED_THRESHOLD (%q) environment variable: must be between 0 and 100", maThreshold)
+		}
+
+		config.MachineAutoscalerDegradedThreshold = v
+	}
+
+	if standaloneMode, ok := os.LookupEnv("STANDALONE_MODE"); ok {
+		switch mode := StandaloneMode(standaloneMode); mode {
+		case StandaloneModeAuto, StandaloneModeEnabled, StandaloneModeDisabled:
+			config.StandaloneMode = mode
+		default:
+			return nil, fmt.Errorf("error parsing STANDALONE_MODE (%q) environment variable: must be one of Auto, Enabled, Disabled", standaloneMode)
+		}
+	}
+
+	if featureGates, ok := os.LookupEnv("FEATURE_GATES"); ok {
+		gates, err := ParseFeatureGates(featureGates)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing FEATURE_GATES (%q) environment variable: %v", featureGates, err)
+		}
+
+		config.FeatureGates = gates
+	}
+
+	if platformType, ok := os.LookupEnv("PLATFORM_TYPE"); ok {
+		config.PlatformType = platformType
+	}
+
 	return config, nil
 }
+
+// ParseFeatureGates parses a comma separated list of feature gates in the
+// form "Name=true,Other=false".
+func ParseFeatureGates(s string) (map[string]bool, error) {
+	gates := map[string]bool{}
+
+	for _, gate := range strings.Split(s, ",") {
+		gate = strings.TrimSpace(gate)
+		if gate == "" {
+			continue
+		}
+
+		name, value, found := strings.Cut(gate, "=")
+		if !found || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid feature gate %q, expected Name=true|false", gate)
+		}
+
+		enabled, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for feature gate %q: %v", name, err)
+		}
+
+		gates[strings.TrimSpace(name)] = enabled
+	}
+
+	return gates, nil
+}
@@ -2,6 +2,7 @@ package operator
 
 import (
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 )
@@ -25,27 +26,49 @@ func TestConfigFromEnvironment(t *testing.T) {
 	}{
 		{
 			envVars: map[string]string{
-				"WEBHOOKS_PORT":                "1234",
-				"METRICS_PORT":                 "5678",
-				"LEADER_ELECTION":              "false",
-				"CLUSTER_AUTOSCALER_VERBOSITY": "5",
-				"WEBHOOKS_ENABLED":             "false",
+				"WEBHOOKS_PORT":                         "1234",
+				"METRICS_PORT":                          "5678",
+				"LEADER_ELECTION":                       "false",
+				"CLUSTER_AUTOSCALER_VERBOSITY":          "5",
+				"WEBHOOKS_ENABLED":                      "false",
+				"WEBHOOKS_STRICT_VALIDATION":            "true",
+				"WEBHOOKS_FAILURE_POLICY":               "Fail",
+				"WEBHOOKS_SELF_MANAGED_CERTS":           "true",
+				"WEBHOOKS_TIMEOUT_SECONDS":              "5",
+				"WEBHOOKS_OBJECT_SELECTOR":              "app=test",
+				"STANDALONE_MODE":                       "Enabled",
+				"MACHINE_AUTOSCALER_DEGRADED_THRESHOLD": "25",
+				"FEATURE_GATES":                         "ProvisioningRequestAvailable=true, Other=false",
+				"PLATFORM_TYPE":                         "AWS",
 			},
 			expectedConfig: &Config{
-				WatchNamespace:                 DefaultWatchNamespace,
-				LeaderElection:                 false,
-				LeaderElectionNamespace:        DefaultLeaderElectionNamespace,
-				LeaderElectionID:               DefaultLeaderElectionID,
-				ClusterAutoscalerNamespace:     DefaultClusterAutoscalerNamespace,
-				ClusterAutoscalerName:          DefaultClusterAutoscalerName,
-				ClusterAutoscalerImage:         DefaultClusterAutoscalerImage,
-				ClusterAutoscalerReplicas:      DefaultClusterAutoscalerReplicas,
-				ClusterAutoscalerCloudProvider: DefaultClusterAutoscalerCloudProvider,
-				ClusterAutoscalerVerbosity:     5,
-				WebhooksEnabled:                false,
-				WebhooksPort:                   1234,
-				WebhooksCertDir:                DefaultWebhooksCertDir,
-				MetricsPort:                    5678,
+				WatchNamespace:                     DefaultWatchNamespace,
+				LeaderElection:                     false,
+				LeaderElectionNamespace:            DefaultLeaderElectionNamespace,
+				LeaderElectionID:                   DefaultLeaderElectionID,
+				ClusterAutoscalerNamespace:         DefaultClusterAutoscalerNamespace,
+				ClusterAutoscalerName:              DefaultClusterAutoscalerName,
+				ClusterAutoscalerImage:             DefaultClusterAutoscalerImage,
+				ClusterAutoscalerReplicas:          DefaultClusterAutoscalerReplicas,
+				ClusterAutoscalerCloudProvider:     DefaultClusterAutoscalerCloudProvider,
+				ClusterAutoscalerVerbosity:         5,
+				WebhooksEnabled:                    false,
+				WebhooksPort:                       1234,
+				WebhooksCertDir:                    DefaultWebhooksCertDir,
+				WebhooksSelfManagedCerts:           true,
+				WebhooksCertSecretName:             DefaultWebhooksCertSecretName,
+				WebhooksStrictValidation:           true,
+				WebhooksFailurePolicy:              "Fail",
+				WebhooksTimeoutSeconds:             5,
+				WebhooksObjectSelector:             "app=test",
+				MetricsPort:                        5678,
+				MachineAutoscalerDegradedThreshold: 25,
+				StandaloneMode:                     StandaloneModeEnabled,
+				FeatureGates: map[string]bool{
+					"ProvisioningRequestAvailable": true,
+					"Other":                        false,
+				},
+				PlatformType: "AWS",
 			},
 			expectedError: false,
 		},
@@ -84,6 +107,76 @@ func TestConfigFromEnvironment(t *testing.T) {
 			expectedConfig: nil,
 			expectedError:  true,
 		},
+		{
+			envVars: map[string]string{
+				"WEBHOOKS_STRICT_VALIDATION": "bad_webhooks_strict_validation",
+			},
+			expectedConfig: nil,
+			expectedError:  true,
+		},
+		{
+			envVars: map[string]string{
+				"WEBHOOKS_SELF_MANAGED_CERTS": "bad_self_managed_certs",
+			},
+			expectedConfig: nil,
+			expectedError:  true,
+		},
+		{
+			envVars: map[string]string{
+				"MACHINE_AUTOSCALER_DEGRADED_THRESHOLD": "bad_threshold",
+			},
+			expectedConfig: nil,
+			expectedError:  true,
+		},
+		{
+			envVars: map[string]string{
+				"MACHINE_AUTOSCALER_DEGRADED_THRESHOLD": "101",
+			},
+			expectedConfig: nil,
+			expectedError:  true,
+		},
+		{
+			envVars: map[string]string{
+				"STANDALONE_MODE": "Sometimes",
+			},
+			expectedConfig: nil,
+			expectedError:  true,
+		},
+		{
+			envVars: map[string]string{
+				"FEATURE_GATES": "ProvisioningRequestAvailable",
+			},
+			expectedConfig: nil,
+			expectedError:  true,
+		},
+		{
+			envVars: map[string]string{
+				"FEATURE_GATES": "ProvisioningRequestAvailable=maybe",
+			},
+			expectedConfig: nil,
+			expectedError:  true,
+		},
+		{
+			envVars: map[string]string{
+				"WEBHOOKS_FAILURE_POLICY": "Sometimes",
+			},
+			expectedConfig: nil,
+			expectedError:  true,
+		},
+		{
+			envVars: map[string]string{
+				"WEBHOOKS_TIMEOUT_SECONDS": "31",
+			},
+			expectedConfig: nil,
+			expectedError:  true,
+		},
+		{
+			envVars: map[string]string{
+				"WEBHOOKS_NAMESPACE_SELECTOR": "a in b",
+			},
+			expectedConfig: nil,
+			expectedError:  true,
+		},
 	}
 
 	for _, tc := range testCase {
@@ -102,3 +195,118 @@ func TestConfigFromEnvironment(t *testing.T) {
 		}
 	}
 }
+
+func TestConfigFromFile(t *testing.T) {
+	testCases := []struct {
+		name          string
+		file          string
+		envVars       map[string]string
+		check         func(t *testing.T, cfg *Config)
+		expectedError bool
+	}{
+		{
+			name: "file values",
+			file: `apiVersion: operator.autoscaling.openshift.io/v1alpha1
+kind: OperatorConfig
+watchNamespace: test-namespace
+clusterAutoscaler:
+  image: test/autoscaler:v1
+  replicas: 2
+  verbosity: 4
+  extraArgs: --scan-interval=20s
+webhooks:
+  failurePolicy: Fail
+featureGates:
+  ProvisioningRequestAvailable: true
+`,
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.WatchNamespace != "test-namespace" {
+					t.Errorf("expected watch namespace test-namespace, got %q", cfg.WatchNamespace)
+				}
+				if cfg.ClusterAutoscalerImage != "test/autoscaler:v1" || cfg.ClusterAutoscalerReplicas != 2 || cfg.ClusterAutoscalerVerbosity != 4 {
+					t.Errorf("unexpected cluster-autoscaler configuration %+v", cfg)
+				}
+				if cfg.ClusterAutoscalerExtraArgs != "--scan-interval=20s" {
+					t.Errorf("expected extra args from the file, got %q", cfg.ClusterAutoscalerExtraArgs)
+				}
+				if cfg.WebhooksFailurePolicy != "Fail" {
+					t.Errorf("expected failure policy Fail, got %q", cfg.WebhooksFailurePolicy)
+				}
+				if !cfg.FeatureGates["ProvisioningRequestAvailable"] {
+					t.Errorf("expected feature gates from the file, got %v", cfg.FeatureGates)
+				}
+				if cfg.ClusterAutoscalerName != DefaultClusterAutoscalerName {
+					t.Errorf("expected default name, got %q", cfg.ClusterAutoscalerName)
+				}
+			},
+		},
+		{
+			name: "environment overrides",
+			file: `apiVersion: operator.autoscaling.openshift.io/v1alpha1
+kind: OperatorConfig
+clusterAutoscaler:
+  image: test/autoscaler:v1
+  verbosity: 4
+`,
+			envVars: map[string]string{
+				"CLUSTER_AUTOSCALER_IMAGE": "test/autoscaler:v2",
+			},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.ClusterAutoscalerImage != "test/autoscaler:v2" {
+					t.Errorf("expected image from the environment, got %q", cfg.ClusterAutoscalerImage)
+				}
+				if cfg.ClusterAutoscalerVerbosity != 4 {
+					t.Errorf("expected verbosity from the file, got %d", cfg.ClusterAutoscalerVerbosity)
+				}
+			},
+		},
+		{
+			name: "unsupported version",
+			file: `apiVersion: operator.autoscaling.openshift.io/v1
+kind: OperatorConfig
+`,
+			expectedError: true,
+		},
+		{
+			name: "unknown field",
+			file: `apiVersion: operator.autoscaling.openshift.io/v1alpha1
+kind: OperatorConfig
+clusterAutoscaler:
+  images: test/autoscaler:v1
+`,
+			expectedError: true,
+		},
+		{
+			name: "invalid value",
+			file: `apiVersion: operator.autoscaling.openshift.io/v1alpha1
+kind: OperatorConfig
+webhooks:
+  timeoutSeconds: 60
+`,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tc.file), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			t.Setenv(ConfigFileEnvVar, path)
+			for key, val := range tc.envVars {
+				t.Setenv(key, val)
+			}
+
+			cfg, err := ConfigFromEnvironment()
+			if (err != nil) != tc.expectedError {
+				t.Fatalf("expected error %v, got: %v", tc.expectedError, err)
+			}
+
+			if tc.check != nil {
+				tc.check(t, cfg)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package operator
+
+import (
+	"fmt"
+	"os"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ConfigFileEnvVar is the environment variable naming the operator
+	// configuration file, if any.
+	ConfigFileEnvVar = "CONFIG_FILE"
+
+	// ConfigFileAPIVersion is the supported version of the operator
+	// configuration file schema.
+	ConfigFileAPIVersion = "operator.autoscaling.openshift.io/v1alpha1"
+
+	// ConfigFileKind is the kind of the operator configuration file.
+	ConfigFileKind = "OperatorConfig"
+)
+
+// ConfigFile is the schema of the operator configuration file.  All fields
+// are optional; unset fields keep their defaults, and environment variables
+// override the values set in the file.
+type ConfigFile struct {
+	metav1.TypeMeta `json:",inline"`
+
+	WatchNamespace    *string                  `json:"watchNamespace,omitempty"`
+	LeaderElection    *LeaderElectionConfig    `json:"leaderElection,omitempty"`
+	ClusterAutoscaler *ClusterAutoscalerConfig `json:"clusterAutoscaler,omitempty"`
+	Webhooks          *WebhooksConfig          `json:"webhooks,omitempty"`
+
+	MetricsPort                        *int            `json:"metricsPort,omitempty"`
+	MachineAutoscalerDegradedThreshold *int            `json:"machineAutoscalerDegradedThreshold,omitempty"`
+	StandaloneMode                     *StandaloneMode `json:"standaloneMode,omitempty"`
+	FeatureGates                       map[string]bool `json:"featureGates,omitempty"`
+	PlatformType                       *string         `json:"platformType,omitempty"`
+}
+
+// LeaderElectionConfig configures the leader-election of the operator.
+type LeaderElectionConfig struct {
+	Enabled   *bool   `json:"enabled,omitempty"`
+	Namespace *string `json:"namespace,omitempty"`
+	ID        *string `json:"id,omitempty"`
+}
+
+// ClusterAutoscalerConfig configures the cluster-autoscaler deployments.
+type ClusterAutoscalerConfig struct {
+	Name          *string `json:"name,omitempty"`
+	Namespace     *string `json:"namespace,omitempty"`
+	Image         *string `json:"image,omitempty"`
+	Replicas      *int32  `json:"replicas,omitempty"`
+	CloudProvider *string `json:"cloudProvider,omitempty"`
+	Verbosity     *int    `json:"verbosity,omitempty"`
+	ExtraArgs     *string `json:"extraArgs,omitempty"`
+}
+
+// WebhooksConfig configures the webhook server and the registration of the
+// admission webhooks.
+type WebhooksConfig struct {
+	Enabled           *bool   `json:"enabled,omitempty"`
+	Port              *int    `json:"port,omitempty"`
+	CertDir           *string `json:"certDir,omitempty"`
+	SelfManagedCerts  *bool   `json:"selfManagedCerts,omitempty"`
+	CertSecretName    *string `json:"certSecretName,omitempty"`
+	StrictValidation  *bool   `json:"strictValidation,omitempty"`
+	FailurePolicy     *string `json:"failurePolicy,omitempty"`
+	TimeoutSeconds    *int32  `json:"timeoutSeconds,omitempty"`
+	NamespaceSelector *string `json:"namespaceSelector,omitempty"`
+	ObjectSelector    *string `json:"objectSelector,omitempty"`
+}
+
+// ReadConfigFile reads and decodes the operator configuration file at the
+// given path.  Unknown fields and versions are rejected.
+func ReadConfigFile(path string) (*ConfigFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &ConfigFile{}
+	if err := yaml.UnmarshalStrict(data, file); err != nil {
+		return nil, fmt.Errorf("error decoding configuration file %s: %v", path, err)
+	}
+
+	if file.APIVersion != ConfigFileAPIVersion || file.Kind != ConfigFileKind {
+		return nil, fmt.Errorf("unsupported configuration file %s: expected %s %s, got %q %q",
+			path, ConfigFileAPIVersion, ConfigFileKind, file.APIVersion, file.Kind)
+	}
+
+	return file, nil
+}
+
+// apply sets the values of the configuration file on the given config.
+func (f *ConfigFile) apply(config *Config) error {
+	setString(&config.WatchNamespace, f.WatchNamespace)
+
+	if le := f.LeaderElection; le != nil {
+		setBool(&config.LeaderElection, le.Enabled)
+		setString(&config.LeaderElectionNamespace, le.Namespace)
+		setString(&config.LeaderElectionID, le.ID)
+	}
+
+	if ca := f.ClusterAutoscaler; ca != nil {
+		setString(&config.ClusterAutoscalerName, ca.Name)
+		setString(&config.ClusterAutoscalerNamespace, ca.Namespace)
+		setString(&config.ClusterAutoscalerImage, ca.Image)
+		setString(&config.ClusterAutoscalerCloudProvider, ca.CloudProvider)
+		setString(&config.ClusterAutoscalerExtraArgs, ca.ExtraArgs)
+
+		if ca.Replicas != nil {
+			if *ca.Replicas < 1 {
+				return fmt.Errorf("invalid clusterAutoscaler.replicas (%d): must be at least 1", *ca.Replicas)
+			}
+
+			config.ClusterAutoscalerReplicas = *ca.Replicas
+		}
+
+		if ca.Verbosity != nil {
+			config.ClusterAutoscalerVerbosity = *ca.Verbosity
+		}
+	}
+
+	if w := f.Webhooks; w != nil {
+		setBool(&config.WebhooksEnabled, w.Enabled)
+		setString(&config.WebhooksCertDir, w.CertDir)
+		setBool(&config.WebhooksSelfManagedCerts, w.SelfManagedCerts)
+		setString(&config.WebhooksCertSecretName, w.CertSecretName)
+		setBool(&config.WebhooksStrictValidation, w.StrictValidation)
+
+		if w.Port != nil {
+			config.WebhooksPort = *w.Port
+		}
+
+		if w.FailurePolicy != nil {
+			switch *w.FailurePolicy {
+			case "Ignore", "Fail":
+				config.WebhooksFailurePolicy = *w.FailurePolicy
+			default:
+				return fmt.Errorf("invalid webhooks.failurePolicy (%q): must be one of Ignore, Fail", *w.FailurePolicy)
+			}
+		}
+
+		if w.TimeoutSeconds != nil {
+			if *w.TimeoutSeconds < 1 || *w.TimeoutSeconds > 30 {
+				return fmt.Errorf("invalid webhooks.timeoutSeconds (%d): must be between 1 and 30", *w.TimeoutSeconds)
+			}
+
+			config.WebhooksTimeoutSeconds = *w.TimeoutSeconds
+		}
+
+		if w.NamespaceSelector != nil {
+			if _, err := metav1.ParseToLabelSelector(*w.NamespaceSelector); err != nil {
+				return fmt.Errorf("invalid webhooks.namespaceSelector (%q): %v", *w.NamespaceSelector, err)
+			}
+
+			config.WebhooksNamespaceSelector = *w.NamespaceSelector
+		}
+
+		if w.ObjectSelector != nil {
+			if _, err := metav1.ParseToLabelSelector(*w.ObjectSelector); err != nil {
+				return fmt.Errorf("invalid webhooks.objectSelector (%q): %v", *w.ObjectSelector, err)
+			}
+
+			config.WebhooksObjectSelector = *w.ObjectSelector
+		}
+	}
+
+	if f.MetricsPort != nil {
+		config.MetricsPort = *f.MetricsPort
+	}
+
+	if f.MachineAutoscalerDegradedThreshold != nil {
+		if v := *f.MachineAutoscalerDegradedThreshold; v < 0 || v > 100 {
+			return fmt.Errorf("invalid machineAutoscalerDegradedThreshold (%d): must be between 0 and 100", v)
+		}
+
+		config.MachineAutoscalerDegradedThreshold = *f.MachineAutoscalerDegradedThreshold
+	}
+
+	if f.StandaloneMode != nil {
+		switch mode := *f.StandaloneMode; mode {
+		case StandaloneModeAuto, StandaloneModeEnabled, StandaloneModeDisabled:
+			config.StandaloneMode = mode
+		default:
+			return fmt.Errorf("invalid standaloneMode (%q): must be one of Auto, Enabled, Disabled", mode)
+		}
+	}
+
+	if f.FeatureGates != nil {
+		config.FeatureGates = f.FeatureGates
+	}
+
+	setString(&config.PlatformType, f.PlatformType)
+
+	return nil
+}
+
+func setString(dst *string, src *string) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+func setBool(dst *bool, src *bool) {
+	if src != nil {
+		*dst = *src
+	}
+}
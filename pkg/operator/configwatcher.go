@@ -0,0 +1,146 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/openshift/cluster-autoscaler-operator/pkg/controller/clusterautoscaler"
+	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+// configFileSyncInterval is the interval at which the operator configuration
+// file is checked for changes.  Files mounted from ConfigMaps are updated by
+// the kubelet, which may take up to a minute anyway.
+const configFileSyncInterval = 10 * time.Second
+
+// ConfigSetter is implemented by the controllers which take a new
+// configuration without restarting.
+type ConfigSetter interface {
+	SetConfig(cfg clusterautoscaler.Config)
+}
+
+// ConfigWatcher watches the operator configuration file, and pushes changes
+// to the ClusterAutoscaler controller.  Changes which cannot be applied
+// without restarting the operator are refused, and only take effect once
+// the operator is restarted.
+type ConfigWatcher struct {
+	path                string
+	config              *Config
+	reconciler          ConfigSetter
+	statusReporter      *StatusReporter
+	featureGateAccessor featuregates.FeatureGateAccess
+	standalone          bool
+
+	// contents are the contents of the file last applied, and rejected
+	// those last refused, so that the refusal is only logged once.
+	contents []byte
+	rejected []byte
+}
+
+// NewConfigWatcher returns a new ConfigWatcher of the configuration file at
+// the given path, from which the given configuration was loaded.
+func NewConfigWatcher(path string, cfg *Config, reconciler ConfigSetter) *ConfigWatcher {
+	w := &ConfigWatcher{
+		path:       path,
+		config:     cfg,
+		reconciler: reconciler,
+	}
+
+	// A failure to read the file here is caught on the next sync.
+	w.contents, _ = os.ReadFile(path)
+
+	return w
+}
+
+// Start watches the configuration file until the context is done.
+func (w *ConfigWatcher) Start(ctx context.Context) error {
+	klog.Infof("Watching operator configuration file %s", w.path)
+
+	wait.UntilWithContext(ctx, w.sync, configFileSyncInterval)
+
+	return nil
+}
+
+// NeedLeaderElection implements the manager.LeaderElectionRunnable
+// interface.  The configuration is reloaded by every instance.
+func (w *ConfigWatcher) NeedLeaderElection() bool {
+	return false
+}
+
+// sync reloads the configuration if the file changed.
+func (w *ConfigWatcher) sync(ctx context.Context) {
+	contents, err := os.ReadFile(w.path)
+	if err != nil {
+		klog.Errorf("Failed to read operator configuration file %s: %v", w.path, err)
+		return
+	}
+
+	if bytes.Equal(contents, w.contents) || bytes.Equal(contents, w.rejected) {
+		return
+	}
+
+	cfg, err := ConfigFromEnvironment()
+	if err != nil {
+		klog.Errorf("Ignoring invalid operator configuration: %v", err)
+		w.rejected = contents
+		return
+	}
+
+	if fields := nonReloadableChanges(w.config, cfg); len(fields) > 0 {
+		klog.Errorf("Ignoring operator configuration change of %s, which requires restarting the operator", strings.Join(fields, ", "))
+		w.rejected = contents
+		return
+	}
+
+	w.contents = contents
+	w.rejected = nil
+
+	if reflect.DeepEqual(w.config, cfg) {
+		return
+	}
+
+	klog.Info("Operator configuration changed, updating the ClusterAutoscaler controller")
+
+	w.config = cfg
+	w.reconciler.SetConfig(clusterAutoscalerConfig(cfg, w.featureGateAccessor, w.standalone))
+
+	if w.statusReporter != nil {
+		w.statusReporter.SetClusterAutoscalerExtraArgs(cfg.ClusterAutoscalerExtraArgs)
+	}
+}
+
+// nonReloadableChanges returns the names of the fields which differ between
+// the given configurations, and are not applied without restarting the
+// operator.
+func nonReloadableChanges(old, new *Config) []string {
+	oldValue := reflect.ValueOf(withoutReloadableFields(*old))
+	newValue := reflect.ValueOf(withoutReloadableFields(*new))
+
+	var fields []string
+
+	for i := range oldValue.NumField() {
+		if !reflect.DeepEqual(oldValue.Field(i).Interface(), newValue.Field(i).Interface()) {
+			fields = append(fields, oldValue.Type().Field(i).Name)
+		}
+	}
+
+	return fields
+}
+
+// withoutReloadableFields returns the given configuration with the fields
+// which are applied without restarting the operator unset.
+func withoutReloadableFields(cfg Config) Config {
+	cfg.ClusterAutoscalerImage = ""
+	cfg.ClusterAutoscalerReplicas = 0
+	cfg.ClusterAutoscalerCloudProvider = ""
+	cfg.ClusterAutoscalerVerbosity = 0
+	cfg.ClusterAutoscalerExtraArgs = ""
+
+	return cfg
+}
@@ -0,0 +1,170 @@
+package operator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/cluster-autoscaler-operator/pkg/controller/clusterautoscaler"
+)
+
+type fakeConfigSetter struct {
+	configs []clusterautoscaler.Config
+}
+
+func (s *fakeConfigSetter) SetConfig(cfg clusterautoscaler.Config) {
+	s.configs = append(s.configs, cfg)
+}
+
+const testConfigFile = `apiVersion: operator.autoscaling.openshift.io/v1alpha1
+kind: OperatorConfig
+clusterAutoscaler:
+  image: test/autoscaler:v1
+`
+
+func TestConfigWatcher(t *testing.T) {
+	testCases := []struct {
+		name             string
+		file             string
+		expectedImage    string
+		expectedRejected bool
+	}{
+		{
+			name: "unchanged",
+			file: testConfigFile + "\n",
+		},
+		{
+			name: "reloadable change",
+			file: `apiVersion: operator.autoscaling.openshift.io/v1alpha1
+kind: OperatorConfig
+clusterAutoscaler:
+  image: test/autoscaler:v2
+`,
+			expectedImage: "test/autoscaler:v2",
+		},
+		{
+			name: "restart change",
+			file: `apiVersion: operator.autoscaling.openshift.io/v1alpha1
+kind: OperatorConfig
+clusterAutoscaler:
+  image: test/autoscaler:v2
+metricsPort: 9090
+`,
+			expectedRejected: true,
+		},
+		{
+			name: "invalid change",
+			file: `apiVersion: operator.autoscaling.openshift.io/v1alpha1
+kind: OperatorConfig
+clusterAutoscaler:
+  replicas: 0
+`,
+			expectedRejected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(testConfigFile), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			t.Setenv(ConfigFileEnvVar, path)
+
+			cfg, err := ConfigFromEnvironment()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			setter := &fakeConfigSetter{}
+
+			w := NewConfigWatcher(path, cfg, setter)
+
+			if err := os.WriteFile(path, []byte(tc.file), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			w.sync(t.Context())
+
+			if rejected := w.rejected != nil; rejected != tc.expectedRejected {
+				t.Errorf("expected rejected %v, got %v", tc.expectedRejected, rejected)
+			}
+
+			// Only applied changes are recorded as the current contents.
+			if applied := string(w.contents) == tc.file; applied == tc.expectedRejected {
+				t.Errorf("expected applied %v, got %v", !tc.expectedRejected, applied)
+			}
+
+			if tc.expectedImage == "" {
+				if len(setter.configs) != 0 {
+					t.Errorf("expected no configuration to be set, got %+v", setter.configs)
+				}
+				return
+			}
+
+			if len(setter.configs) != 1 {
+				t.Fatalf("expected a configuration to be set, got %+v", setter.configs)
+			}
+
+			if image := setter.configs[0].Image; image != tc.expectedImage {
+				t.Errorf("expected image %s, got %s", tc.expectedImage, image)
+			}
+		})
+	}
+}
+
+func TestConfigWatcherAfterRejectedChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(testConfigFile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(ConfigFileEnvVar, path)
+
+	cfg, err := ConfigFromEnvironment()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setter := &fakeConfigSetter{}
+	w := NewConfigWatcher(path, cfg, setter)
+
+	sync := func(file string) {
+		t.Helper()
+
+		if err := os.WriteFile(path, []byte(file), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		w.sync(t.Context())
+	}
+
+	// The change of the metrics port is refused, including the image.
+	sync(testConfigFile + "metricsPort: 9090\n")
+	sync(`apiVersion: operator.autoscaling.openshift.io/v1alpha1
+kind: OperatorConfig
+clusterAutoscaler:
+  image: test/autoscaler:v2
+metricsPort: 9090
+`)
+
+	if len(setter.configs) != 0 {
+		t.Fatalf("expected no configuration to be set, got %+v", setter.configs)
+	}
+
+	// Reverting the metrics port applies the image.
+	sync(`apiVersion: operator.autoscaling.openshift.io/v1alpha1
+kind: OperatorConfig
+clusterAutoscaler:
+  image: test/autoscaler:v2
+`)
+
+	if len(setter.configs) != 1 || setter.configs[0].Image != "test/autoscaler:v2" {
+		t.Fatalf("expected the new image to be set, got %+v", setter.configs)
+	}
+
+	if w.rejected != nil {
+		t.Errorf("expected no rejected contents, got %q", w.rejected)
+	}
+}
@@ -0,0 +1,167 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	"github.com/openshift/cluster-autoscaler-operator/pkg/controller/clusterautoscaler"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// StatusExtension is the summary of the autoscaling configuration published
+// in the extension of the ClusterOperator status, for tooling reading the
+// ClusterOperators of many clusters.
+type StatusExtension struct {
+	ClusterAutoscaler  ClusterAutoscalerSummary  `json:"clusterAutoscaler"`
+	MachineAutoscalers MachineAutoscalersSummary `json:"machineAutoscalers"`
+	FeatureGates       FeatureGatesSummary       `json:"featureGates"`
+}
+
+// ClusterAutoscalerSummary summarizes the configured ClusterAutoscaler.
+type ClusterAutoscalerSummary struct {
+	// Exists is true if the ClusterAutoscaler exists.  The other fields are
+	// only set if so.
+	Exists bool `json:"exists"`
+
+	// ResourceLimits are the limits passed to the cluster-autoscaler, with
+	// the memory in GiB.
+	ResourceLimits *autoscalingv1.ResourceLimits `json:"resourceLimits,omitempty"`
+
+	// ScaleDownEnabled is true if the cluster-autoscaler scales down.
+	ScaleDownEnabled bool `json:"scaleDownEnabled"`
+
+	// Expanders are the expanders used by the cluster-autoscaler, in order.
+	Expanders []autoscalingv1.ExpanderString `json:"expanders,omitempty"`
+}
+
+// MachineAutoscalersSummary summarizes the MachineAutoscalers.
+type MachineAutoscalersSummary struct {
+	// Count is the number of MachineAutoscalers.
+	Count int `json:"count"`
+
+	// MinReplicas and MaxReplicas are the sums of the replicas of all
+	// MachineAutoscalers.
+	MinReplicas int64 `json:"minReplicas"`
+	MaxReplicas int64 `json:"maxReplicas"`
+
+	// FailingTargets is the number of MachineAutoscalers whose target
+	// failed to be updated.
+	FailingTargets int `json:"failingTargets"`
+}
+
+// FeatureGatesSummary lists the feature gates active for the
+// cluster-autoscaler.
+type FeatureGatesSummary struct {
+	ProvisioningRequests bool `json:"provisioningRequests"`
+	ClusterAPIProvider   bool `json:"clusterAPIProvider"`
+}
+
+// StatusExtension returns the current summary of the autoscaling
+// configuration.
+func (r *StatusReporter) StatusExtension() (*StatusExtension, error) {
+	ext := &StatusExtension{}
+
+	ca := &autoscalingv1.ClusterAutoscaler{}
+	if err := r.client.Get(context.TODO(), client.ObjectKey{Name: r.config.ClusterAutoscalerName}, ca); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("unable to get ClusterAutoscaler: %v", err)
+		}
+	} else {
+		ext.ClusterAutoscaler = clusterAutoscalerSummary(ca)
+	}
+
+	mas := &autoscalingv1.MachineAutoscalerList{}
+	if err := r.client.List(context.TODO(), mas); err != nil {
+		return nil, fmt.Errorf("unable to list MachineAutoscalers: %v", err)
+	}
+
+	ext.MachineAutoscalers.Count = len(mas.Items)
+
+	for _, ma := range mas.Items {
+		ext.MachineAutoscalers.MinReplicas += int64(ma.Spec.MinReplicas)
+		ext.MachineAutoscalers.MaxReplicas += int64(ma.Spec.MaxReplicas)
+
+		cond := meta.FindStatusCondition(ma.Status.Conditions, autoscalingv1.ReadyCondition)
+		if cond != nil && cond.Status == metav1.ConditionFalse {
+			ext.MachineAutoscalers.FailingTargets++
+		}
+	}
+
+	clusterAPI, err := r.clusterAPIProviderEnabled()
+	if err != nil {
+		return nil, err
+	}
+
+	ext.FeatureGates = FeatureGatesSummary{
+		ProvisioningRequests: clusterautoscaler.ProvisioningRequestsEnabled(r.config.FeatureGateAccessor),
+		ClusterAPIProvider:   clusterAPI,
+	}
+
+	return ext, nil
+}
+
+// clusterAutoscalerSummary returns the summary of the given
+// ClusterAutoscaler, with the defaults of the cluster-autoscaler applied.
+func clusterAutoscalerSummary(ca *autoscalingv1.ClusterAutoscaler) ClusterAutoscalerSummary {
+	summary := ClusterAutoscalerSummary{
+		Exists:           true,
+		ResourceLimits:   ca.Spec.ResourceLimits,
+		ScaleDownEnabled: ca.Spec.ScaleDown == nil || ca.Spec.ScaleDown.Enabled,
+		Expanders:        ca.Spec.Expanders,
+	}
+
+	if len(summary.Expanders) == 0 {
+		summary.Expanders = []autoscalingv1.ExpanderString{autoscalingv1.RandomExpander}
+	}
+
+	return summary
+}
+
+// statusExtension returns the status extension to set on the ClusterOperator
+// status.  If the summary cannot be built, the extension currently reported
+// on the given ClusterOperator is kept.
+func (r *StatusReporter) statusExtension(co *configv1.ClusterOperator) runtime.RawExtension {
+	ext, err := r.StatusExtension()
+	if err != nil {
+		klog.Errorf("Error building status extension: %v", err)
+		return co.Status.Extension
+	}
+
+	raw, err := json.Marshal(ext)
+	if err != nil {
+		klog.Errorf("Error encoding status extension: %v", err)
+		return co.Status.Extension
+	}
+
+	return runtime.RawExtension{Raw: raw}
+}
+
+// rawExtensionEqual returns true if the given encoded extensions hold the same
+// JSON values.  The extension is written in struct field order, but the API
+// server returns it with sorted keys, so the bytes differ.
+func rawExtensionEqual(a, b []byte) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+
+	var aValue, bValue interface{}
+	if err := json.Unmarshal(a, &aValue); err != nil {
+		return false
+	}
+
+	if err := json.Unmarshal(b, &bValue); err != nil {
+		return false
+	}
+
+	return equality.Semantic.DeepEqual(aValue, bValue)
+}
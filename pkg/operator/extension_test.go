@@ -0,0 +1,177 @@
+package operator
+
+import (
+	"encoding/json"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	fakeconfigclient "github.com/openshift/client-go/config/clientset/versioned/fake"
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestStatusExtension(t *testing.T) {
+	limitedAutoscaler := clusterAutoscaler.DeepCopy()
+	limitedAutoscaler.Spec.ResourceLimits = &autoscalingv1.ResourceLimits{
+		MaxNodesTotal: ptr.To[int32](24),
+		Cores:         &autoscalingv1.ResourceRange{Min: 8, Max: 128},
+	}
+	limitedAutoscaler.Spec.ScaleDown = &autoscalingv1.ScaleDownConfig{Enabled: false}
+	limitedAutoscaler.Spec.Expanders = []autoscalingv1.ExpanderString{
+		autoscalingv1.PriorityExpander,
+		autoscalingv1.LeastWasteExpander,
+	}
+
+	testCases := []struct {
+		label        string
+		objects      []runtime.Object
+		configObjs   []runtime.Object
+		featureGates featuregates.FeatureGateAccess
+		expected     StatusExtension
+	}{
+		{
+			label:    "no autoscalers",
+			expected: StatusExtension{},
+		},
+		{
+			label:   "defaults",
+			objects: []runtime.Object{clusterAutoscaler},
+			expected: StatusExtension{
+				ClusterAutoscaler: ClusterAutoscalerSummary{
+					Exists:           true,
+					ScaleDownEnabled: true,
+					Expanders:        []autoscalingv1.ExpanderString{autoscalingv1.RandomExpander},
+				},
+			},
+		},
+		{
+			label: "configured",
+			objects: []runtime.Object{
+				limitedAutoscaler,
+				newTargetMachineAutoscaler("worker-a", "machine.openshift.io/v1beta1", "MachineSet"),
+				newTargetMachineAutoscaler("worker-b", "machine.openshift.io/v1beta1", "MachineSet"),
+				newMachineAutoscaler("worker-c", metav1.ConditionFalse, autoscalingv1.TargetNotFoundReason),
+			},
+			configObjs: []runtime.Object{
+				newInfrastructure(configv1.AWSPlatformType),
+			},
+			featureGates: featuregates.NewHardcodedFeatureGateAccess(
+				[]configv1.FeatureGateName{"ProvisioningRequestAvailable", "ClusterAPIMachineManagementAWS"},
+				[]configv1.FeatureGateName{},
+			),
+			expected: StatusExtension{
+				ClusterAutoscaler: ClusterAutoscalerSummary{
+					Exists:         true,
+					ResourceLimits: limitedAutoscaler.Spec.ResourceLimits,
+					Expanders: []autoscalingv1.ExpanderString{
+						autoscalingv1.PriorityExpander,
+						autoscalingv1.LeastWasteExpander,
+					},
+				},
+				MachineAutoscalers: MachineAutoscalersSummary{
+					Count:          3,
+					MinReplicas:    2,
+					MaxReplicas:    4,
+					FailingTargets: 1,
+				},
+				FeatureGates: FeatureGatesSummary{
+					ProvisioningRequests: true,
+					ClusterAPIProvider:   true,
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			cfg := TestStatusReporterConfig
+			cfg.FeatureGateAccessor = tc.featureGates
+
+			reporter := &StatusReporter{
+				client:       fakeclient.NewFakeClient(tc.objects...),
+				configClient: fakeconfigclient.NewSimpleClientset(tc.configObjs...),
+				config:       &cfg,
+			}
+
+			startConfigInformers(t, reporter)
+
+			if err := reporter.available(ReasonAsExpected, "available"); err != nil {
+				t.Fatalf("error applying status: %v", err)
+			}
+
+			co, err := getClusterOperator(reporter)
+			if err != nil {
+				t.Fatalf("error getting ClusterOperator: %v", err)
+			}
+
+			got := StatusExtension{}
+			if err := json.Unmarshal(co.Status.Extension.Raw, &got); err != nil {
+				t.Fatalf("error decoding status extension %q: %v", co.Status.Extension.Raw, err)
+			}
+
+			if !equality.Semantic.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %+v, got %+v", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestStatusExtensionRoundTrip validates that the status extension, as
+// returned by the API server with sorted keys, is not seen as modified, so
+// that reporting the same status does not update the ClusterOperator again.
+func TestStatusExtensionRoundTrip(t *testing.T) {
+	reporter := &StatusReporter{
+		client:       fakeclient.NewFakeClient(clusterAutoscaler.DeepCopy()),
+		configClient: fakeconfigclient.NewSimpleClientset(newInfrastructure(configv1.AWSPlatformType)),
+		config:       &TestStatusReporterConfig,
+	}
+
+	startConfigInformers(t, reporter)
+
+	required := reporter.statusExtension(&configv1.ClusterOperator{})
+	if len(required.Raw) == 0 {
+		t.Fatal("expected a status extension")
+	}
+
+	// Round-trip the extension through a map, which encodes sorted keys.
+	decoded := map[string]interface{}{}
+	if err := json.Unmarshal(required.Raw, &decoded); err != nil {
+		t.Fatalf("error decoding status extension: %v", err)
+	}
+
+	stored, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatalf("error encoding status extension: %v", err)
+	}
+
+	if string(stored) == string(required.Raw) {
+		t.Fatalf("expected the round-tripped extension to be encoded differently, got %s", stored)
+	}
+
+	existing := configv1.ClusterOperatorStatus{Extension: runtime.RawExtension{Raw: stored}}
+
+	modified := false
+	ensureClusterOperatorStatus(&modified, &existing, configv1.ClusterOperatorStatus{Extension: required})
+
+	if modified {
+		t.Errorf("expected the round-tripped extension not to be modified")
+	}
+
+	// A change of the extension content is still applied.
+	decoded["featureGates"] = map[string]interface{}{"provisioningRequests": true}
+	changed, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatalf("error encoding status extension: %v", err)
+	}
+
+	ensureClusterOperatorStatus(&modified, &existing, configv1.ClusterOperatorStatus{Extension: runtime.RawExtension{Raw: changed}})
+
+	if !modified {
+		t.Errorf("expected the changed extension to be modified")
+	}
+}
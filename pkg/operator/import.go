@@ -0,0 +1,109 @@
+package operator
+
+import (
+	"fmt"
+	"path"
+
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	"github.com/openshift/cluster-autoscaler-operator/pkg/controller/clusterautoscaler"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// autoscalerContainerName is the name of the cluster-autoscaler container
+// looked for in imported Deployments.
+const autoscalerContainerName = "cluster-autoscaler"
+
+// Imported holds the objects imported from a cluster-autoscaler command
+// line.
+type Imported struct {
+	ClusterAutoscaler  *autoscalingv1.ClusterAutoscaler
+	MachineAutoscalers []*autoscalingv1.MachineAutoscaler
+
+	// Unrepresented are the arguments which could not be imported.
+	Unrepresented []clusterautoscaler.UnrepresentedArg
+}
+
+// ImportArgs returns the ClusterAutoscaler corresponding to the given
+// cluster-autoscaler arguments, named after the configured
+// ClusterAutoscaler, and a MachineAutoscaler in the watched namespace for
+// each node group given with --nodes.  The node groups are assumed to be
+// MachineSets named after the last path element of the node group name.
+func ImportArgs(cfg *Config, args []string) (*Imported, error) {
+	importedArgs := clusterautoscaler.ImportAutoscalerArgs(args)
+
+	hub := importedArgs.ClusterAutoscaler
+	hub.Name = cfg.ClusterAutoscalerName
+
+	ca := &autoscalingv1.ClusterAutoscaler{}
+	if err := ca.ConvertFrom(hub); err != nil {
+		return nil, fmt.Errorf("unable to convert ClusterAutoscaler: %v", err)
+	}
+
+	ca.TypeMeta = metav1.TypeMeta{
+		APIVersion: autoscalingv1.SchemeGroupVersion.String(),
+		Kind:       "ClusterAutoscaler",
+	}
+
+	imported := &Imported{
+		ClusterAutoscaler: ca,
+		Unrepresented:     importedArgs.Unrepresented,
+	}
+
+	for _, group := range importedArgs.NodeGroups {
+		name := path.Base(group.Name)
+
+		imported.MachineAutoscalers = append(imported.MachineAutoscalers, &autoscalingv1.MachineAutoscaler{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: autoscalingv1.SchemeGroupVersion.String(),
+				Kind:       "MachineAutoscaler",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: cfg.WatchNamespace,
+			},
+			Spec: autoscalingv1.MachineAutoscalerSpec{
+				MinReplicas: group.MinSize,
+				MaxReplicas: group.MaxSize,
+				ScaleTargetRef: autoscalingv1.ScaleTargetReference{
+					APIVersion: "machine.openshift.io/v1beta1",
+					Kind:       "MachineSet",
+					Name:       name,
+				},
+			},
+		})
+	}
+
+	return imported, nil
+}
+
+// DeploymentArgs returns the arguments of the cluster-autoscaler container
+// of the given Deployment manifest, including those given in its command
+// after the executable.
+func DeploymentArgs(data []byte) ([]string, error) {
+	deployment := &appsv1.Deployment{}
+	if err := yaml.UnmarshalStrict(data, deployment); err != nil {
+		return nil, fmt.Errorf("unable to decode Deployment: %v", err)
+	}
+
+	containers := deployment.Spec.Template.Spec.Containers
+	if len(containers) == 0 {
+		return nil, fmt.Errorf("Deployment %s has no containers", deployment.Name)
+	}
+
+	container := containers[0]
+	for _, c := range containers {
+		if c.Name == autoscalerContainerName {
+			container = c
+			break
+		}
+	}
+
+	var args []string
+	if len(container.Command) > 1 {
+		args = append(args, container.Command[1:]...)
+	}
+
+	return append(args, container.Args...), nil
+}
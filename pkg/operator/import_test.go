@@ -0,0 +1,89 @@
+package operator
+
+import (
+	"testing"
+
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+const testAutoscalerDeploymentManifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cluster-autoscaler
+  namespace: kube-system
+spec:
+  template:
+    spec:
+      containers:
+      - name: sidecar
+        image: sidecar:latest
+      - name: cluster-autoscaler
+        image: cluster-autoscaler:latest
+        command:
+        - ./cluster-autoscaler
+        - --cloud-provider=aws
+        args:
+        - --nodes=1:10:worker-a
+        - --max-nodes-total=24
+        - --scale-down-enabled=false
+`
+
+func TestImportDeployment(t *testing.T) {
+	args, err := DeploymentArgs([]byte(testAutoscalerDeploymentManifest))
+	if err != nil {
+		t.Fatalf("error reading Deployment arguments: %v", err)
+	}
+
+	expectedArgs := []string{
+		"--cloud-provider=aws",
+		"--nodes=1:10:worker-a",
+		"--max-nodes-total=24",
+		"--scale-down-enabled=false",
+	}
+
+	if !equality.Semantic.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected arguments %v, got %v", expectedArgs, args)
+	}
+
+	imported, err := ImportArgs(NewConfig(), args)
+	if err != nil {
+		t.Fatalf("error importing arguments: %v", err)
+	}
+
+	ca := imported.ClusterAutoscaler
+	if ca.Name != DefaultClusterAutoscalerName || ca.Kind != "ClusterAutoscaler" {
+		t.Errorf("unexpected ClusterAutoscaler %s %s", ca.Kind, ca.Name)
+	}
+
+	if ca.Spec.ResourceLimits == nil || ca.Spec.ResourceLimits.MaxNodesTotal == nil || *ca.Spec.ResourceLimits.MaxNodesTotal != 24 {
+		t.Errorf("expected maxNodesTotal of 24, got %+v", ca.Spec.ResourceLimits)
+	}
+
+	if ca.Spec.ScaleDown == nil || ca.Spec.ScaleDown.Enabled {
+		t.Errorf("expected scale down to be disabled, got %+v", ca.Spec.ScaleDown)
+	}
+
+	if len(imported.MachineAutoscalers) != 1 {
+		t.Fatalf("expected a MachineAutoscaler, got %d", len(imported.MachineAutoscalers))
+	}
+
+	ma := imported.MachineAutoscalers[0]
+	expectedSpec := autoscalingv1.MachineAutoscalerSpec{
+		MinReplicas: 1,
+		MaxReplicas: 10,
+		ScaleTargetRef: autoscalingv1.ScaleTargetReference{
+			APIVersion: "machine.openshift.io/v1beta1",
+			Kind:       "MachineSet",
+			Name:       "worker-a",
+		},
+	}
+
+	if ma.Name != "worker-a" || ma.Namespace != DefaultWatchNamespace || !equality.Semantic.DeepEqual(ma.Spec, expectedSpec) {
+		t.Errorf("unexpected MachineAutoscaler %s/%s: %+v", ma.Namespace, ma.Name, ma.Spec)
+	}
+
+	if len(imported.Unrepresented) != 1 || imported.Unrepresented[0].Arg != "--cloud-provider=aws" {
+		t.Errorf("expected the cloud provider to be unrepresented, got %+v", imported.Unrepresented)
+	}
+}
@@ -6,21 +6,27 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	configv1 "github.com/openshift/api/config/v1"
 	configv1client "github.com/openshift/client-go/config/clientset/versioned"
 	configinformers "github.com/openshift/client-go/config/informers/externalversions"
 	"github.com/openshift/cluster-autoscaler-operator/pkg/apis"
-	"github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1beta1"
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
 	"github.com/openshift/cluster-autoscaler-operator/pkg/controller/clusterautoscaler"
 	"github.com/openshift/cluster-autoscaler-operator/pkg/controller/machineautoscaler"
 	"github.com/openshift/cluster-autoscaler-operator/pkg/util"
 	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
 	"github.com/openshift/library-go/pkg/operator/events"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/clock"
@@ -31,6 +37,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
 )
 
 // for use with release version detection
@@ -52,6 +59,10 @@ type Operator struct {
 	FeatureGateAccessor featuregates.FeatureGateAccess
 
 	webhookTLSOpts []func(*tls.Config)
+
+	// standalone indicates the operator runs without the OpenShift
+	// configuration APIs, e.g. on upstream Kubernetes clusters.
+	standalone bool
 }
 
 // New returns a new Operator instance with the given config and a
@@ -65,11 +76,30 @@ func New(stopCh context.Context, cancel context.CancelFunc, cfg *Config) (*Opera
 		return nil, err
 	}
 
-	tlsProfileSpec, tlsOpts, shouldHonorTLSProfile, err := util.FetchClusterTLSProfile(stopCh, clientConfig)
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(clientConfig)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get cluster TLS profile: %w", err)
+		return nil, fmt.Errorf("failed to create discovery client: %w", err)
+	}
+
+	operator.standalone, err = isStandalone(cfg.StandaloneMode, discoveryClient)
+	if err != nil {
+		return nil, err
+	}
+
+	var tlsProfileSpec configv1.TLSProfileSpec
+	var shouldHonorTLSProfile bool
+
+	if operator.standalone {
+		tlsProfileSpec, operator.webhookTLSOpts, err = util.DefaultTLSProfile()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get default TLS profile: %w", err)
+		}
+	} else {
+		tlsProfileSpec, operator.webhookTLSOpts, shouldHonorTLSProfile, err = util.FetchClusterTLSProfile(stopCh, clientConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cluster TLS profile: %w", err)
+		}
 	}
-	operator.webhookTLSOpts = tlsOpts
 
 	// Get defaults for leader election
 	le := util.GetLeaderElectionDefaults(clientConfig, configv1.LeaderElection{
@@ -84,6 +114,15 @@ func New(stopCh context.Context, cancel context.CancelFunc, cfg *Config) (*Opera
 			DefaultNamespaces: map[string]cache.Config{
 				cfg.WatchNamespace: {},
 			},
+			// Only the operator's own webhook configurations are watched.
+			ByObject: map[client.Object]cache.ByObject{
+				&admissionregistrationv1.ValidatingWebhookConfiguration{}: {
+					Field: fields.OneTermEqualSelector("metadata.name", WebhookConfigurationName),
+				},
+				&admissionregistrationv1.MutatingWebhookConfiguration{}: {
+					Field: fields.OneTermEqualSelector("metadata.name", WebhookConfigurationName),
+				},
+			},
 		},
 		LeaderElection:                cfg.LeaderElection,
 		LeaderElectionNamespace:       cfg.LeaderElectionNamespace,
@@ -124,9 +163,15 @@ func New(stopCh context.Context, cancel context.CancelFunc, cfg *Config) (*Opera
 		return nil, fmt.Errorf("failed to register configv1 types: %v", err)
 	}
 
+	if err := apiextensionsv1.AddToScheme(operator.manager.GetScheme()); err != nil {
+		return nil, fmt.Errorf("failed to register apiextensionsv1 types: %v", err)
+	}
+
 	// this needs to happen before the controllers so that we can configure them
 	// with feature gate access.
-	if featureGateAccessor, err := getFeatureGateAccessor(stopCh, operator); err != nil {
+	if operator.standalone {
+		operator.FeatureGateAccessor = staticFeatureGateAccess(cfg.FeatureGates)
+	} else if featureGateAccessor, err := getFeatureGateAccessor(stopCh, operator); err != nil {
 		return nil, fmt.Errorf("failed to get feature gate accessor: %w", err)
 	} else {
 		operator.FeatureGateAccessor = featureGateAccessor
@@ -137,24 +182,55 @@ func New(stopCh context.Context, cancel context.CancelFunc, cfg *Config) (*Opera
 		return nil, fmt.Errorf("failed to add controllers: %v", err)
 	}
 
-	// Setup admission webhooks and add them to the manager.
-	if cfg.WebhooksEnabled {
-		if err := operator.AddWebhooks(); err != nil {
-			return nil, fmt.Errorf("failed to start webhook server: %v", err)
+	// Setup the webhook server and add it to the manager.  This is done
+	// even with the admission webhooks disabled, as the server also serves
+	// the MachineAutoscaler conversion webhook.
+	if err := operator.AddWebhooks(); err != nil {
+		return nil, fmt.Errorf("failed to start webhook server: %v", err)
+	}
+
+	// Watch the operator configuration file, if any, and push changes to
+	// the ClusterAutoscaler controller without restarting.
+	var configWatcher *ConfigWatcher
+
+	if path := os.Getenv(ConfigFileEnvVar); path != "" {
+		configWatcher = NewConfigWatcher(path, cfg, operator.caReconciler)
+		configWatcher.featureGateAccessor = operator.FeatureGateAccessor
+		configWatcher.standalone = operator.standalone
+
+		if err := operator.manager.Add(configWatcher); err != nil {
+			return nil, fmt.Errorf("failed to add configuration watcher to manager: %v", err)
 		}
 	}
 
+	// There is no cluster TLS profile, nor ClusterOperator to report status
+	// to in standalone mode.
+	if operator.standalone {
+		klog.Info("Running in standalone mode, ClusterOperator status reporting is disabled")
+		return operator, nil
+	}
+
 	// Watch the APIServer object for TLS profile changes and trigger a graceful shutdown
 	// if the profile is updated. The new TLS configuration will take effect upon restart
 	if err := util.SetupTLSProfileWatcher(operator.manager, tlsProfileSpec, cancel, shouldHonorTLSProfile); err != nil {
 		return nil, fmt.Errorf("failed to set up TLS profile watcher: %w", err)
 	}
 
+	// Index Machines by their MachineSets, so that the related objects are
+	// built without listing every Machine.
+	if err := operator.manager.GetFieldIndexer().IndexField(stopCh, newMachineMetadata(), machineSetOwnerIndex, machineSetOwners); err != nil {
+		return nil, fmt.Errorf("failed to index machines: %v", err)
+	}
+
 	statusConfig := &StatusReporterConfig{
 		ClusterAutoscalerName:      cfg.ClusterAutoscalerName,
 		ClusterAutoscalerNamespace: cfg.ClusterAutoscalerNamespace,
+		ClusterAutoscalerExtraArgs: cfg.ClusterAutoscalerExtraArgs,
 		ReleaseVersion:             cfg.ReleaseVersion,
 		RelatedObjects:             []configv1.ObjectReference{}, // Will be populated dynamically
+		FeatureGateAccessor:        operator.FeatureGateAccessor,
+
+		MachineAutoscalerDegradedThreshold: cfg.MachineAutoscalerDegradedThreshold,
 	}
 
 	statusReporter, err := NewStatusReporter(operator.manager, statusConfig, operator)
@@ -166,9 +242,54 @@ func New(stopCh context.Context, cancel context.CancelFunc, cfg *Config) (*Opera
 		return nil, fmt.Errorf("failed to add status reporter to manager: %v", err)
 	}
 
+	if configWatcher != nil {
+		configWatcher.statusReporter = statusReporter
+	}
+
 	return operator, nil
 }
 
+// machineSetOwnerIndex is the name of the index of Machines by the names of
+// the MachineSets owning them.
+const machineSetOwnerIndex = "machineSetOwner"
+
+// machineGVK is the kind of the Machines owned by autoscaled MachineSets.
+var machineGVK = schema.GroupVersionKind{
+	Group:   "machine.openshift.io",
+	Version: "v1beta1",
+	Kind:    "Machine",
+}
+
+// newMachineMetadata returns an empty Machine metadata object, as Machines
+// are only watched for their metadata.
+func newMachineMetadata() *metav1.PartialObjectMetadata {
+	machine := &metav1.PartialObjectMetadata{}
+	machine.SetGroupVersionKind(machineGVK)
+
+	return machine
+}
+
+// machineSetOwners returns the names of the MachineSets owning the given
+// Machine, for use in the machineSetOwnerIndex.
+func machineSetOwners(obj client.Object) []string {
+	var owners []string
+
+	for _, ownerRef := range obj.GetOwnerReferences() {
+		if ownerRef.Kind == "MachineSet" {
+			owners = append(owners, ownerRef.Name)
+		}
+	}
+
+	return owners
+}
+
+// scaleTargetResources maps the kinds of MachineAutoscaler targets to their
+// resource names.
+var scaleTargetResources = map[string]string{
+	"MachineSet":        "machinesets",
+	"MachineDeployment": "machinedeployments",
+}
+
 // RelatedObjects returns a list of objects related to the operator and its
 // operands.  These are used in the ClusterOperator status.
 func (o *Operator) RelatedObjects() []configv1.ObjectReference {
@@ -181,13 +302,13 @@ func (o *Operator) RelatedObjects() []configv1.ObjectReference {
 	// Related objects lets openshift/must-gather collect diagnostic content
 	relatedObjects := []configv1.ObjectReference{
 		{
-			Group:     v1beta1.SchemeGroupVersion.Group,
+			Group:     autoscalingv1.SchemeGroupVersion.Group,
 			Resource:  "machineautoscalers",
 			Name:      "",
 			Namespace: o.config.WatchNamespace,
 		},
 		{
-			Group:     v1beta1.SchemeGroupVersion.Group,
+			Group:     autoscalingv1.SchemeGroupVersion.Group,
 			Resource:  "clusterautoscalers",
 			Name:      "",
 			Namespace: o.config.WatchNamespace,
@@ -217,54 +338,80 @@ func (o *Operator) RelatedObjects() []configv1.ObjectReference {
 			Resource: "clusterrolebindings",
 			Name:     "cluster-autoscaler",
 		},
+		{
+			Group:    admissionregistrationv1.GroupName,
+			Resource: "validatingwebhookconfigurations",
+			Name:     WebhookConfigurationName,
+		},
+		{
+			Group:    admissionregistrationv1.GroupName,
+			Resource: "mutatingwebhookconfigurations",
+			Name:     WebhookConfigurationName,
+		},
+		{
+			Resource:  "configmaps",
+			Name:      AutoscalerStatusConfigMapName,
+			Namespace: o.config.ClusterAutoscalerNamespace,
+		},
+	}
+
+	// Add the objects owned by the ClusterAutoscaler controller
+	if o.caReconciler != nil {
+		relatedObjects = append(relatedObjects, o.caReconciler.RelatedObjects()...)
 	}
 
 	// Query MachineAutoscalers to find their targets and add related MachineSets and Machines
 	ctx := context.Background()
-	maList := &v1beta1.MachineAutoscalerList{}
+	maList := &autoscalingv1.MachineAutoscalerList{}
 	if err := o.manager.GetClient().List(ctx, maList, client.InNamespace(o.config.WatchNamespace)); err != nil {
 		klog.Errorf("Failed to list MachineAutoscalers for related objects: %v", err)
 	} else {
 		// Track which MachineSets are autoscaled by the cluster autoscaler
-		autoscaledMachineSets := make(map[string]bool)
+		autoscaledMachineSets := sets.New[string]()
 
-		// Add only MachineSets that have MachineAutoscalers targeting them
+		// Add only MachineSets and MachineDeployments that have
+		// MachineAutoscalers targeting them
 		for _, ma := range maList.Items {
-			if ma.Spec.ScaleTargetRef.Kind == "MachineSet" {
-				relatedObjects = append(relatedObjects, configv1.ObjectReference{
-					Group:     "machine.openshift.io",
-					Resource:  "machinesets",
-					Name:      ma.Spec.ScaleTargetRef.Name,
-					Namespace: o.config.WatchNamespace,
-				})
-				autoscaledMachineSets[ma.Spec.ScaleTargetRef.Name] = true
+			ref := ma.Spec.ScaleTargetRef
+			group, _, _ := strings.Cut(ref.APIVersion, "/")
+
+			resource, ok := scaleTargetResources[ref.Kind]
+			if !ok {
+				continue
+			}
+
+			relatedObjects = append(relatedObjects, configv1.ObjectReference{
+				Group:     group,
+				Resource:  resource,
+				Name:      ref.Name,
+				Namespace: o.config.WatchNamespace,
+			})
+
+			if group == "machine.openshift.io" && ref.Kind == "MachineSet" {
+				autoscaledMachineSets.Insert(ref.Name)
 			}
 		}
 
-		// Add only Machines owned by autoscaled MachineSets
-		machineList := &metav1.PartialObjectMetadataList{}
-		machineList.SetGroupVersionKind(schema.GroupVersionKind{
-			Group:   "machine.openshift.io",
-			Version: "v1beta1",
-			Kind:    "MachineList",
-		})
+		// Add only Machines owned by autoscaled MachineSets, looked up in
+		// the index rather than listing every Machine
+		for _, machineSet := range sets.List(autoscaledMachineSets) {
+			machineList := &metav1.PartialObjectMetadataList{}
+			machineList.SetGroupVersionKind(machineGVK.GroupVersion().WithKind("MachineList"))
+
+			if err := o.manager.GetClient().List(ctx, machineList,
+				client.InNamespace(o.config.WatchNamespace),
+				client.MatchingFields{machineSetOwnerIndex: machineSet}); err != nil {
+				klog.Errorf("Failed to list Machines of MachineSet %s for related objects: %v", machineSet, err)
+				continue
+			}
 
-		if err := o.manager.GetClient().List(ctx, machineList, client.InNamespace(o.config.WatchNamespace)); err != nil {
-			klog.Errorf("Failed to list Machines for related objects: %v", err)
-		} else {
 			for _, machine := range machineList.Items {
-				// Check if this Machine is owned by an autoscaled MachineSet
-				for _, ownerRef := range machine.OwnerReferences {
-					if ownerRef.Kind == "MachineSet" && autoscaledMachineSets[ownerRef.Name] {
-						relatedObjects = append(relatedObjects, configv1.ObjectReference{
-							Group:     "machine.openshift.io",
-							Resource:  "machines",
-							Name:      machine.Name,
-							Namespace: machine.Namespace,
-						})
-						break
-					}
-				}
+				relatedObjects = append(relatedObjects, configv1.ObjectReference{
+					Group:     machineGVK.Group,
+					Resource:  "machines",
+					Name:      machine.Name,
+					Namespace: machine.Namespace,
+				})
 			}
 		}
 	}
@@ -278,31 +425,47 @@ func (o *Operator) RelatedObjects() []configv1.ObjectReference {
 	return relatedObjects
 }
 
+// clusterAutoscalerConfig returns the configuration of the ClusterAutoscaler
+// controller for the given operator configuration.
+func clusterAutoscalerConfig(cfg *Config, featureGateAccessor featuregates.FeatureGateAccess, standalone bool) clusterautoscaler.Config {
+	return clusterautoscaler.Config{
+		ReleaseVersion:      cfg.ReleaseVersion,
+		Name:                cfg.ClusterAutoscalerName,
+		Image:               cfg.ClusterAutoscalerImage,
+		Replicas:            cfg.ClusterAutoscalerReplicas,
+		Namespace:           cfg.ClusterAutoscalerNamespace,
+		CloudProvider:       cfg.ClusterAutoscalerCloudProvider,
+		Verbosity:           cfg.ClusterAutoscalerVerbosity,
+		ExtraArgs:           cfg.ClusterAutoscalerExtraArgs,
+		FeatureGateAccessor: featureGateAccessor,
+		WebhooksPort:        cfg.WebhooksPort,
+		StrictValidation:    cfg.WebhooksStrictValidation,
+		Standalone:          standalone,
+		PlatformType:        configv1.PlatformType(cfg.PlatformType),
+	}
+}
+
 // AddControllers configures the various controllers and adds them to
 // the operator's manager instance.
 func (o *Operator) AddControllers() error {
 	// Setup ClusterAutoscaler controller.
-	ca := clusterautoscaler.NewReconciler(o.manager, clusterautoscaler.Config{
-		ReleaseVersion:      o.config.ReleaseVersion,
-		Name:                o.config.ClusterAutoscalerName,
-		Image:               o.config.ClusterAutoscalerImage,
-		Replicas:            o.config.ClusterAutoscalerReplicas,
-		Namespace:           o.config.ClusterAutoscalerNamespace,
-		CloudProvider:       o.config.ClusterAutoscalerCloudProvider,
-		Verbosity:           o.config.ClusterAutoscalerVerbosity,
-		ExtraArgs:           o.config.ClusterAutoscalerExtraArgs,
-		FeatureGateAccessor: o.FeatureGateAccessor,
-		WebhooksPort:        o.config.WebhooksPort,
-	})
+	ca := clusterautoscaler.NewReconciler(o.manager, clusterAutoscalerConfig(o.config, o.FeatureGateAccessor, o.standalone))
 
 	if err := ca.AddToManager(o.manager); err != nil {
 		return err
 	}
 
-	// Setup MachineAutoscaler controller.
+	// Setup MachineAutoscaler controller.  Upstream Cluster API targets are
+	// only supported in standalone mode.
+	targetGVKs := machineautoscaler.DefaultSupportedTargetGVKs()
+	if o.standalone {
+		targetGVKs = append(targetGVKs, machineautoscaler.ClusterAPITargetGVKs()...)
+	}
+
 	ma := machineautoscaler.NewReconciler(o.manager, machineautoscaler.Config{
 		Namespace:           o.config.ClusterAutoscalerNamespace,
-		SupportedTargetGVKs: machineautoscaler.DefaultSupportedTargetGVKs(),
+		SupportedTargetGVKs: targetGVKs,
+		StrictValidation:    o.config.WebhooksStrictValidation,
 	})
 
 	if err := ma.AddToManager(o.manager); err != nil {
@@ -317,34 +480,76 @@ func (o *Operator) AddControllers() error {
 
 // AddWebhooks sets up the webhook server, registers handlers, and adds the
 // server to operator's manager instance.  This expects the reconcilers to have
-// been configured previously via the AddControllers() method.
+// been configured previously via the AddControllers() method.  The admission
+// webhooks are only registered when enabled, while the conversion webhook is
+// always served.
 func (o *Operator) AddWebhooks() error {
 	namespace := o.config.WatchNamespace
 
-	// Set up the webhook config updater and add it to the manager.  This will
-	// update the webhook configurations when and if this instance becomes the
-	// leader.
-	webhookUpdater, err := NewWebhookConfigUpdater(o.manager, namespace)
-	if err != nil {
-		return err
+	webhookConfig := WebhookConfig{
+		Namespace:         namespace,
+		FailurePolicy:     o.config.WebhooksFailurePolicy,
+		TimeoutSeconds:    o.config.WebhooksTimeoutSeconds,
+		NamespaceSelector: o.config.WebhooksNamespaceSelector,
+		ObjectSelector:    o.config.WebhooksObjectSelector,
+		AdmissionDisabled: !o.config.WebhooksEnabled,
 	}
 
-	if err := o.manager.Add(webhookUpdater); err != nil {
-		return err
+	tlsOpts := append([]func(*tls.Config){}, o.webhookTLSOpts...)
+
+	// With self-managed certificates, the webhook server serves the
+	// certificate loaded from the certificate Secret, which is picked up
+	// when rotated without restarting the server.
+	if o.config.WebhooksSelfManagedCerts {
+		certManager := NewWebhookCertManager(o.manager, namespace, o.config.WebhooksCertSecretName)
+		if err := certManager.AddToManager(o.manager); err != nil {
+			return err
+		}
+
+		webhookConfig.CABundleSecretName = o.config.WebhooksCertSecretName
+		tlsOpts = append(tlsOpts, certManager.TLSOpt())
+	}
+
+	// Set up the webhook config controller and add it to the manager.  This
+	// will reconcile the webhook configurations when and if this instance
+	// becomes the leader.  Without admission webhooks, it is only needed to
+	// manage the conversion webhook CA bundle of self-managed certificates.
+	if o.config.WebhooksEnabled || o.config.WebhooksSelfManagedCerts {
+		webhookUpdater, err := NewWebhookConfigUpdater(o.manager, webhookConfig)
+		if err != nil {
+			return err
+		}
+
+		if err := webhookUpdater.AddToManager(o.manager); err != nil {
+			return err
+		}
 	}
 
 	serverOpts := webhook.Options{
 		Port:    o.config.WebhooksPort,
 		CertDir: o.config.WebhooksCertDir,
-		TLSOpts: o.webhookTLSOpts,
+		TLSOpts: tlsOpts,
 	}
 	server := webhook.NewServer(serverOpts)
 
-	server.Register("/validate-clusterautoscalers",
-		&webhook.Admission{Handler: o.caReconciler.Validator()})
+	// MachineAutoscalers are stored as v1, and converted to and from
+	// v1beta1 through the hub type.
+	server.Register("/convert",
+		conversion.NewWebhookHandler(o.manager.GetScheme(), o.manager.GetConverterRegistry()))
 
-	server.Register("/validate-machineautoscalers",
-		&webhook.Admission{Handler: o.maReconciler.Validator()})
+	if o.config.WebhooksEnabled {
+		server.Register("/validate-clusterautoscalers",
+			&webhook.Admission{Handler: o.caReconciler.Validator()})
+
+		server.Register("/validate-machineautoscalers",
+			&webhook.Admission{Handler: o.maReconciler.Validator()})
+
+		server.Register("/mutate-clusterautoscalers",
+			&webhook.Admission{Handler: o.caReconciler.Defaulter()})
+
+		server.Register("/mutate-machineautoscalers",
+			&webhook.Admission{Handler: o.maReconciler.Defaulter()})
+	}
 
 	return o.manager.Add(server)
 }
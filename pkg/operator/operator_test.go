@@ -5,7 +5,8 @@ import (
 
 	configv1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/cluster-autoscaler-operator/pkg/apis"
-	"github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1beta1"
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -24,6 +25,10 @@ func init() {
 	if err := configv1.AddToScheme(scheme.Scheme); err != nil {
 		panic(err)
 	}
+	// Register CRD types
+	if err := apiextensionsv1.AddToScheme(scheme.Scheme); err != nil {
+		panic(err)
+	}
 	// Register PartialObjectMetadata types
 	metav1.AddMetaToScheme(scheme.Scheme)
 }
@@ -42,13 +47,13 @@ func (f *fakeManager) GetClient() client.Client {
 func TestRelatedObjects(t *testing.T) {
 	expected := []configv1.ObjectReference{
 		{
-			Group:     v1beta1.SchemeGroupVersion.Group,
+			Group:     autoscalingv1.SchemeGroupVersion.Group,
 			Resource:  "machineautoscalers",
 			Name:      "",
 			Namespace: DefaultWatchNamespace,
 		},
 		{
-			Group:     v1beta1.SchemeGroupVersion.Group,
+			Group:     autoscalingv1.SchemeGroupVersion.Group,
 			Resource:  "clusterautoscalers",
 			Name:      "",
 			Namespace: DefaultWatchNamespace,
@@ -78,6 +83,21 @@ func TestRelatedObjects(t *testing.T) {
 			Resource: "clusterrolebindings",
 			Name:     "cluster-autoscaler",
 		},
+		{
+			Group:    "admissionregistration.k8s.io",
+			Resource: "validatingwebhookconfigurations",
+			Name:     "autoscaling.openshift.io",
+		},
+		{
+			Group:    "admissionregistration.k8s.io",
+			Resource: "mutatingwebhookconfigurations",
+			Name:     "autoscaling.openshift.io",
+		},
+		{
+			Resource:  "configmaps",
+			Name:      "cluster-autoscaler-status",
+			Namespace: DefaultClusterAutoscalerNamespace,
+		},
 		{
 			Resource: "namespaces",
 			Name:     DefaultWatchNamespace,
@@ -106,15 +126,15 @@ func TestRelatedObjects(t *testing.T) {
 
 func TestRelatedObjectsWithAutoscaledMachineSetsAndMachines(t *testing.T) {
 	// Create test MachineAutoscaler targeting a MachineSet
-	machineAutoscaler := &v1beta1.MachineAutoscaler{
+	machineAutoscaler := &autoscalingv1.MachineAutoscaler{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "test-ma-1",
 			Namespace: DefaultWatchNamespace,
 		},
-		Spec: v1beta1.MachineAutoscalerSpec{
+		Spec: autoscalingv1.MachineAutoscalerSpec{
 			MinReplicas: 1,
 			MaxReplicas: 10,
-			ScaleTargetRef: v1beta1.CrossVersionObjectReference{
+			ScaleTargetRef: autoscalingv1.ScaleTargetReference{
 				APIVersion: "machine.openshift.io/v1beta1",
 				Kind:       "MachineSet",
 				Name:       "test-machineset-1",
@@ -122,6 +142,23 @@ func TestRelatedObjectsWithAutoscaledMachineSetsAndMachines(t *testing.T) {
 		},
 	}
 
+	// Create test MachineAutoscaler targeting a Cluster API MachineDeployment
+	clusterAPIMachineAutoscaler := &autoscalingv1.MachineAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ma-2",
+			Namespace: DefaultWatchNamespace,
+		},
+		Spec: autoscalingv1.MachineAutoscalerSpec{
+			MinReplicas: 1,
+			MaxReplicas: 10,
+			ScaleTargetRef: autoscalingv1.ScaleTargetReference{
+				APIVersion: "cluster.x-k8s.io/v1beta1",
+				Kind:       "MachineDeployment",
+				Name:       "test-machinedeployment-1",
+			},
+		},
+	}
+
 	// Create test Machines - one owned by autoscaled MachineSet, one not
 	machineOwnedByAutoscaledMS := &metav1.PartialObjectMetadata{
 		TypeMeta: metav1.TypeMeta{
@@ -162,14 +199,17 @@ func TestRelatedObjectsWithAutoscaledMachineSetsAndMachines(t *testing.T) {
 	// Build list of all runtime objects for fake client
 	initObjects := []runtime.Object{
 		machineAutoscaler,
+		clusterAPIMachineAutoscaler,
 		machineOwnedByAutoscaledMS,
 		machineOwnedByNonAutoscaledMS,
 	}
 
-	// Create fake client with scheme that includes all necessary types
+	// Create fake client with scheme that includes all necessary types, and
+	// the index of Machines by MachineSet
 	fakeClient := fakeclient.NewClientBuilder().
 		WithScheme(scheme.Scheme).
 		WithRuntimeObjects(initObjects...).
+		WithIndex(newMachineMetadata(), machineSetOwnerIndex, machineSetOwners).
 		Build()
 
 	// Create fake manager
@@ -202,6 +242,18 @@ func TestRelatedObjectsWithAutoscaledMachineSetsAndMachines(t *testing.T) {
 		t.Errorf("expected 1 MachineSet, got %d: %v", len(foundMachineSets), foundMachineSets)
 	}
 
+	// Verify autoscaled Cluster API MachineDeployment is present
+	foundMachineDeployment := false
+	for _, obj := range relatedObjects {
+		if obj.Group == "cluster.x-k8s.io" && obj.Resource == "machinedeployments" && obj.Name == "test-machinedeployment-1" {
+			foundMachineDeployment = true
+		}
+	}
+
+	if !foundMachineDeployment {
+		t.Error("expected autoscaled MachineDeployment 'test-machinedeployment-1' not found in RelatedObjects")
+	}
+
 	// Verify only the Machine owned by autoscaled MachineSet is present
 	foundMachines := make(map[string]bool)
 	for _, obj := range relatedObjects {
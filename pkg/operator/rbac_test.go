@@ -0,0 +1,129 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/yaml"
+)
+
+// installRBACManifest is the manifest granting the operator its permissions.
+const installRBACManifest = "../../install/03_rbac.yaml"
+
+// errNotAllowed is the reason of requests rejected by newRBACEnforcingClient.
+var errNotAllowed = errors.New("not allowed by the install RBAC manifests")
+
+// crdResource is the group and resource of CustomResourceDefinitions.
+var crdResource = schema.GroupResource{Group: apiextensionsv1.GroupName, Resource: "customresourcedefinitions"}
+
+// installClusterRules returns the rules of the ClusterRoles bound to the
+// operator's service account by the install manifests.  Only these apply to
+// cluster-scoped resources, such as CustomResourceDefinitions.
+func installClusterRules(t *testing.T) []rbacv1.PolicyRule {
+	t.Helper()
+
+	data, err := os.ReadFile(installRBACManifest)
+	if err != nil {
+		t.Fatalf("failed to read RBAC manifest: %v", err)
+	}
+
+	roles := map[string][]rbacv1.PolicyRule{}
+	bound := sets.New[string]()
+
+	for _, doc := range strings.Split(string(data), "\n---") {
+		var kind struct {
+			Kind string `json:"kind"`
+		}
+
+		if err := yaml.Unmarshal([]byte(doc), &kind); err != nil {
+			t.Fatalf("failed to parse RBAC manifest: %v", err)
+		}
+
+		switch kind.Kind {
+		case "ClusterRole":
+			role := &rbacv1.ClusterRole{}
+			if err := yaml.Unmarshal([]byte(doc), role); err != nil {
+				t.Fatalf("failed to parse ClusterRole: %v", err)
+			}
+
+			roles[role.Name] = role.Rules
+
+		case "ClusterRoleBinding":
+			binding := &rbacv1.ClusterRoleBinding{}
+			if err := yaml.Unmarshal([]byte(doc), binding); err != nil {
+				t.Fatalf("failed to parse ClusterRoleBinding: %v", err)
+			}
+
+			for _, subject := range binding.Subjects {
+				if subject.Kind == rbacv1.ServiceAccountKind && subject.Name == OperatorName+"-operator" &&
+					subject.Namespace == DefaultWatchNamespace {
+					bound.Insert(binding.RoleRef.Name)
+				}
+			}
+		}
+	}
+
+	var rules []rbacv1.PolicyRule
+	for _, name := range sets.List(bound) {
+		rules = append(rules, roles[name]...)
+	}
+
+	return rules
+}
+
+// rulesAllow returns true if the given rules allow the verb on the named
+// object of the given group and resource.
+func rulesAllow(rules []rbacv1.PolicyRule, verb string, gr schema.GroupResource, name string) bool {
+	matches := func(values []string, value string) bool {
+		return slices.Contains(values, value) || slices.Contains(values, "*")
+	}
+
+	for _, rule := range rules {
+		if matches(rule.Verbs, verb) && matches(rule.APIGroups, gr.Group) && matches(rule.Resources, gr.Resource) &&
+			(len(rule.ResourceNames) == 0 || slices.Contains(rule.ResourceNames, name)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newRBACEnforcingClient returns a fake client with the given objects, which
+// rejects requests on CustomResourceDefinitions not allowed to the operator
+// by the install manifests, as the API server would.
+func newRBACEnforcingClient(t *testing.T, objs ...client.Object) client.Client {
+	t.Helper()
+
+	rules := installClusterRules(t)
+
+	return fakeclient.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				if _, ok := obj.(*apiextensionsv1.CustomResourceDefinition); ok && !rulesAllow(rules, "get", crdResource, key.Name) {
+					return apierrors.NewForbidden(crdResource, key.Name, errNotAllowed)
+				}
+
+				return c.Get(ctx, key, obj, opts...)
+			},
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				if _, ok := obj.(*apiextensionsv1.CustomResourceDefinition); ok && !rulesAllow(rules, "patch", crdResource, obj.GetName()) {
+					return apierrors.NewForbidden(crdResource, obj.GetName(), errNotAllowed)
+				}
+
+				return c.Patch(ctx, obj, patch, opts...)
+			},
+		}).Build()
+}
@@ -0,0 +1,93 @@
+package operator
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/openshift/cluster-autoscaler-operator/pkg/apis"
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	autoscalingv2 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v2"
+	"github.com/openshift/cluster-autoscaler-operator/pkg/controller/clusterautoscaler"
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// DecodeClusterAutoscaler decodes a ClusterAutoscaler manifest in either
+// served version, converting it to v1 if needed.
+func DecodeClusterAutoscaler(data []byte) (*autoscalingv1.ClusterAutoscaler, error) {
+	scheme := runtime.NewScheme()
+	if err := apis.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+
+	obj, _, err := serializer.NewCodecFactory(scheme).UniversalDeserializer().Decode(data, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode ClusterAutoscaler: %v", err)
+	}
+
+	switch ca := obj.(type) {
+	case *autoscalingv1.ClusterAutoscaler:
+		return ca, nil
+	case *autoscalingv2.ClusterAutoscaler:
+		dst := &autoscalingv1.ClusterAutoscaler{}
+		if err := dst.ConvertFrom(ca); err != nil {
+			return nil, fmt.Errorf("unable to convert ClusterAutoscaler: %v", err)
+		}
+
+		return dst, nil
+	default:
+		return nil, fmt.Errorf("expected a ClusterAutoscaler, got %T", obj)
+	}
+}
+
+// Render returns the operand objects the operator would create for the given
+// ClusterAutoscaler with the given configuration, built without a cluster.
+// The platform type and feature gates are taken from the static standalone
+// mode configuration.
+func Render(cfg *Config, ca *autoscalingv1.ClusterAutoscaler) ([]client.Object, error) {
+	caConfig := clusterAutoscalerConfig(cfg, staticFeatureGateAccess(cfg.FeatureGates), false)
+
+	return clusterautoscaler.Render(ca, caConfig)
+}
+
+// Explain returns the explanation of the cluster-autoscaler arguments the
+// operator would render for the given ClusterAutoscaler with the given
+// configuration, as for Render.
+func Explain(cfg *Config, ca *autoscalingv1.ClusterAutoscaler) (*clusterautoscaler.Explanation, error) {
+	caConfig := clusterAutoscalerConfig(cfg, staticFeatureGateAccess(cfg.FeatureGates), false)
+
+	return clusterautoscaler.Explain(ca, caConfig)
+}
+
+// RenderManifests encodes the given objects as a multi-document YAML stream.
+func RenderManifests(objs []client.Object) ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	for _, obj := range objs {
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode %T %s: %v", obj, obj.GetName(), err)
+		}
+
+		buf.WriteString("---\n")
+		buf.Write(data)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// DiffManifests returns a unified diff between a previous render, read from
+// the file with the given name, and the current one.  The diff is empty if
+// they are identical.
+func DiffManifests(previous []byte, previousName string, current []byte) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(previous)),
+		B:        difflib.SplitLines(string(current)),
+		FromFile: previousName,
+		ToFile:   "rendered",
+		Context:  3,
+	})
+}
@@ -0,0 +1,115 @@
+package operator
+
+import (
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+const testClusterAutoscalerManifest = `apiVersion: autoscaling.openshift.io/%s
+kind: ClusterAutoscaler
+metadata:
+  name: default
+spec:
+  scaleDown:
+    enabled: true
+`
+
+func TestDecodeClusterAutoscaler(t *testing.T) {
+	for _, version := range []string{"v1", "v2"} {
+		t.Run(version, func(t *testing.T) {
+			manifest := strings.Replace(testClusterAutoscalerManifest, "%s", version, 1)
+
+			ca, err := DecodeClusterAutoscaler([]byte(manifest))
+			if err != nil {
+				t.Fatalf("error decoding ClusterAutoscaler: %v", err)
+			}
+
+			if ca.Name != "default" || ca.Spec.ScaleDown == nil || !ca.Spec.ScaleDown.Enabled {
+				t.Errorf("unexpected ClusterAutoscaler: %+v", ca)
+			}
+		})
+	}
+
+	if _, err := DecodeClusterAutoscaler([]byte("apiVersion: v1\nkind: ConfigMap\n")); err == nil {
+		t.Errorf("expected an error decoding a ConfigMap")
+	}
+}
+
+func TestRender(t *testing.T) {
+	ca, err := DecodeClusterAutoscaler([]byte(strings.Replace(testClusterAutoscalerManifest, "%s", "v1", 1)))
+	if err != nil {
+		t.Fatalf("error decoding ClusterAutoscaler: %v", err)
+	}
+
+	cfg := NewConfig()
+	cfg.ClusterAutoscalerImage = "test/cluster-autoscaler:latest"
+	cfg.PlatformType = "AWS"
+	cfg.FeatureGates = map[string]bool{"ProvisioningRequestAvailable": true}
+
+	objs, err := Render(cfg, ca)
+	if err != nil {
+		t.Fatalf("error rendering ClusterAutoscaler: %v", err)
+	}
+
+	kinds := map[string]int{}
+	for _, obj := range objs {
+		kinds[obj.GetObjectKind().GroupVersionKind().Kind]++
+	}
+
+	for _, kind := range []string{"Deployment", "Service", "ServiceMonitor", "PrometheusRule", "NetworkPolicy"} {
+		if kinds[kind] == 0 {
+			t.Errorf("expected a %s to be rendered, got %v", kind, kinds)
+		}
+	}
+
+	deployment, ok := objs[0].(*appsv1.Deployment)
+	if !ok {
+		t.Fatalf("expected a Deployment first, got %T", objs[0])
+	}
+
+	args := strings.Join(deployment.Spec.Template.Spec.Containers[0].Args, " ")
+	if !strings.Contains(args, "--enable-provisioning-requests=true") {
+		t.Errorf("expected the provisioning requests feature gate to be applied, got %q", args)
+	}
+
+	manifests, err := RenderManifests(objs)
+	if err != nil {
+		t.Fatalf("error encoding manifests: %v", err)
+	}
+
+	if n := strings.Count(string(manifests), "---\n"); n != len(objs) {
+		t.Errorf("expected %d documents, got %d", len(objs), n)
+	}
+
+	diff, err := DiffManifests(manifests, "previous.yaml", manifests)
+	if err != nil {
+		t.Fatalf("error diffing manifests: %v", err)
+	}
+
+	if diff != "" {
+		t.Errorf("expected no diff against the same render, got:\n%s", diff)
+	}
+
+	cfg.FeatureGates = nil
+
+	objs, err = Render(cfg, ca)
+	if err != nil {
+		t.Fatalf("error rendering ClusterAutoscaler: %v", err)
+	}
+
+	updated, err := RenderManifests(objs)
+	if err != nil {
+		t.Fatalf("error encoding manifests: %v", err)
+	}
+
+	diff, err = DiffManifests(manifests, "previous.yaml", updated)
+	if err != nil {
+		t.Fatalf("error diffing manifests: %v", err)
+	}
+
+	if !strings.Contains(diff, "-        - --enable-provisioning-requests=true") {
+		t.Errorf("expected the feature gate argument to be removed, got:\n%s", diff)
+	}
+}
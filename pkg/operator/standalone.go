@@ -0,0 +1,95 @@
+package operator
+
+import (
+	"fmt"
+	"sort"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/discovery"
+	"k8s.io/klog/v2"
+)
+
+// requiredConfigResources are the OpenShift configuration resources the
+// operator relies on outside of standalone mode.
+var requiredConfigResources = []string{
+	"apiservers",
+	"clusteroperators",
+	"clusterversions",
+	"featuregates",
+	"infrastructures",
+}
+
+// isStandalone returns whether the operator should run in standalone mode.
+// In the auto mode, this is the case when any of the required OpenShift
+// configuration resources is not served by the cluster.
+func isStandalone(mode StandaloneMode, client discovery.DiscoveryInterface) (bool, error) {
+	switch mode {
+	case StandaloneModeEnabled:
+		return true, nil
+	case StandaloneModeDisabled:
+		return false, nil
+	}
+
+	missing, err := missingConfigResources(client)
+	if err != nil {
+		return false, fmt.Errorf("unable to detect OpenShift configuration APIs: %v", err)
+	}
+
+	if len(missing) > 0 {
+		klog.Infof("OpenShift configuration APIs not available (missing %v), running in standalone mode", missing)
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// missingConfigResources returns the required OpenShift configuration
+// resources which are not served by the cluster.
+func missingConfigResources(client discovery.DiscoveryInterface) ([]string, error) {
+	resources, err := client.ServerResourcesForGroupVersion(configv1.GroupVersion.String())
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return requiredConfigResources, nil
+		}
+
+		return nil, err
+	}
+
+	served := map[string]bool{}
+	for _, r := range resources.APIResources {
+		served[r.Name] = true
+	}
+
+	var missing []string
+	for _, r := range requiredConfigResources {
+		if !served[r] {
+			missing = append(missing, r)
+		}
+	}
+
+	return missing, nil
+}
+
+// staticFeatureGateAccess returns a feature gate accessor for the given
+// static set of feature gates, as used in standalone mode.
+func staticFeatureGateAccess(gates map[string]bool) featuregates.FeatureGateAccess {
+	var enabled, disabled []configv1.FeatureGateName
+
+	for name, on := range gates {
+		if on {
+			enabled = append(enabled, configv1.FeatureGateName(name))
+		} else {
+			disabled = append(disabled, configv1.FeatureGateName(name))
+		}
+	}
+
+	// Keep the order stable for logging.
+	sort.Slice(enabled, func(i, j int) bool { return enabled[i] < enabled[j] })
+	sort.Slice(disabled, func(i, j int) bool { return disabled[i] < disabled[j] })
+
+	klog.Infof("Using static feature gates, enabled: %v, disabled: %v", enabled, disabled)
+
+	return featuregates.NewHardcodedFeatureGateAccess(enabled, disabled)
+}
@@ -0,0 +1,98 @@
+package operator
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func newFakeDiscovery(resources ...string) *fakediscovery.FakeDiscovery {
+	client := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{}}
+
+	if len(resources) == 0 {
+		return client
+	}
+
+	list := &metav1.APIResourceList{GroupVersion: configv1.GroupVersion.String()}
+	for _, r := range resources {
+		list.APIResources = append(list.APIResources, metav1.APIResource{Name: r})
+	}
+
+	client.Resources = []*metav1.APIResourceList{list}
+
+	return client
+}
+
+func TestIsStandalone(t *testing.T) {
+	testCases := []struct {
+		label     string
+		mode      StandaloneMode
+		resources []string
+		expected  bool
+	}{
+		{
+			label:     "auto with all config resources",
+			mode:      StandaloneModeAuto,
+			resources: requiredConfigResources,
+			expected:  false,
+		},
+		{
+			label:     "auto with missing config resources",
+			mode:      StandaloneModeAuto,
+			resources: []string{"apiservers", "infrastructures"},
+			expected:  true,
+		},
+		{
+			label:    "auto without config group",
+			mode:     StandaloneModeAuto,
+			expected: true,
+		},
+		{
+			label:     "enabled",
+			mode:      StandaloneModeEnabled,
+			resources: requiredConfigResources,
+			expected:  true,
+		},
+		{
+			label:    "disabled",
+			mode:     StandaloneModeDisabled,
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			got, err := isStandalone(tc.mode, newFakeDiscovery(tc.resources...))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestStaticFeatureGateAccess(t *testing.T) {
+	accessor := staticFeatureGateAccess(map[string]bool{
+		"Enabled":  true,
+		"Disabled": false,
+	})
+
+	gates, err := accessor.CurrentFeatureGates()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !gates.Enabled("Enabled") {
+		t.Errorf("expected Enabled feature gate to be enabled")
+	}
+
+	if gates.Enabled("Disabled") {
+		t.Errorf("expected Disabled feature gate to be disabled")
+	}
+}
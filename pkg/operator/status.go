@@ -3,7 +3,10 @@ package operator
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/blang/semver/v4"
@@ -13,15 +16,18 @@ import (
 	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
 	"github.com/openshift/cluster-autoscaler-operator/pkg/util"
 	"github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
+	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
 	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/clock"
+	runtimecache "sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
@@ -32,6 +38,9 @@ const (
 	ReasonMissingDependency = "MissingDependency"
 	ReasonSyncing           = "SyncingResources"
 	ReasonCheckAutoscaler   = "UnableToCheckAutoscalers"
+
+	ReasonCheckMachineAutoscalers   = "UnableToCheckMachineAutoscalers"
+	ReasonMachineAutoscalersFailing = "MachineAutoscalersFailing"
 )
 
 const (
@@ -40,18 +49,56 @@ const (
 	// This helps prevent flapping due to transient issues.
 	// At most DegradedCountThreshold * interval seconds will pass before the operator is reported degraded.
 	DegradedCountThreshold = 3
+
+	// maxListedMachineAutoscalers is the maximum number of failing
+	// MachineAutoscalers listed in status messages.
+	maxListedMachineAutoscalers = 5
+
+	// statusRetryInterval is the interval at which the status is reported
+	// again while the operator is not available, so that transient issues
+	// are reported degraded after DegradedCountThreshold attempts.
+	statusRetryInterval = 15 * time.Second
+
+	// machineAutoscalerFailurePeriod is how long a MachineAutoscaler must
+	// fail to reconcile before it counts towards reporting the operator
+	// degraded, as told by the transition time of its Ready condition.
+	machineAutoscalerFailurePeriod = 10 * time.Minute
+
+	// statusResyncInterval is the interval at which the status is reported
+	// regardless of events, as a safety net for missed events and for
+	// dependencies which are not watched.
+	statusResyncInterval = 10 * time.Minute
+
+	// statusQueueKey is the single key of the status reporting queue.
+	statusQueueKey = "cluster"
 )
 
 // StatusReporter reports the status of the operator to the OpenShift
 // cluster-version-operator via ClusterOperator resource status.
 type StatusReporter struct {
 	client                   client.Client
+	apiReader                client.Reader
 	configClient             osconfig.Interface
 	config                   *StatusReporterConfig
 	configInformers          configv1informers.SharedInformerFactory
 	queue                    workqueue.TypedRateLimitingInterface[string]
 	degradedConsecutiveCount int
 	relatedObjectsGetter     RelatedObjectsGetter
+	upgradeableChecks        []UpgradeableCheck
+
+	// informers are the manager's informers, used to report the status on
+	// changes to the operand and its related objects.
+	informers runtimecache.Informers
+
+	// The related objects last returned by the relatedObjectsGetter, and
+	// whether they are still valid.  They are invalidated by events on the
+	// objects they are built from.
+	relatedObjectsCache []configv1.ObjectReference
+	relatedObjectsValid atomic.Bool
+
+	// extraArgsMu guards the extra arguments of the configuration, which
+	// are updated when the operator configuration file is reloaded.
+	extraArgsMu sync.Mutex
 }
 
 // RelatedObjectsGetter is an interface for getting related objects dynamically
@@ -63,8 +110,19 @@ type RelatedObjectsGetter interface {
 type StatusReporterConfig struct {
 	ClusterAutoscalerName      string
 	ClusterAutoscalerNamespace string
+	ClusterAutoscalerExtraArgs string
 	ReleaseVersion             string
 	RelatedObjects             []configv1.ObjectReference
+
+	// FeatureGateAccessor is used to determine whether the
+	// cluster-autoscaler runs with its Cluster API provider.
+	FeatureGateAccessor featuregates.FeatureGateAccess
+
+	// MachineAutoscalerDegradedThreshold is the percentage of
+	// MachineAutoscalers persistently failing to reconcile at which the
+	// operator is reported degraded.  Zero means any persistently failing
+	// MachineAutoscaler.
+	MachineAutoscalerDegradedThreshold int
 }
 
 // NewStatusReporter returns a new StatusReporter instance.
@@ -73,8 +131,11 @@ func NewStatusReporter(mgr manager.Manager, cfg *StatusReporterConfig, relatedOb
 
 	reporter := &StatusReporter{
 		client:               mgr.GetClient(),
+		apiReader:            mgr.GetAPIReader(),
 		config:               cfg,
 		relatedObjectsGetter: relatedObjectsGetter,
+		upgradeableChecks:    DefaultUpgradeableChecks(),
+		informers:            mgr.GetCache(),
 	}
 
 	// Create a client for OpenShift config objects.
@@ -108,9 +169,36 @@ func (r *StatusReporter) AddRelatedObjects(objs []configv1.ObjectReference) {
 	}
 }
 
-// GetClusterOperator fetches the the operator's ClusterOperator object.
+// SetClusterAutoscalerExtraArgs sets the configured extra arguments of the
+// cluster-autoscaler, and queues a status report to reevaluate them.
+func (r *StatusReporter) SetClusterAutoscalerExtraArgs(args string) {
+	r.extraArgsMu.Lock()
+	r.config.ClusterAutoscalerExtraArgs = args
+	r.extraArgsMu.Unlock()
+
+	if r.queue != nil {
+		r.queue.Add(statusQueueKey)
+	}
+}
+
+// clusterAutoscalerExtraArgs returns the configured extra arguments of the
+// cluster-autoscaler.
+func (r *StatusReporter) clusterAutoscalerExtraArgs() string {
+	r.extraArgsMu.Lock()
+	defer r.extraArgsMu.Unlock()
+
+	return r.config.ClusterAutoscalerExtraArgs
+}
+
+// GetClusterOperator fetches the the operator's ClusterOperator object from
+// the informer cache.  The returned object is a copy, which may be modified.
 func (r *StatusReporter) GetClusterOperator() (*configv1.ClusterOperator, error) {
-	return r.configClient.ConfigV1().ClusterOperators().Get(context.Background(), OperatorName, metav1.GetOptions{})
+	co, err := r.configInformers.Config().V1().ClusterOperators().Lister().Get(OperatorName)
+	if err != nil {
+		return nil, err
+	}
+
+	return co.DeepCopy(), nil
 }
 
 // GetOrCreateClusterOperator gets, or if necessary, creates the
@@ -123,18 +211,23 @@ func (r *StatusReporter) GetOrCreateClusterOperator() (*configv1.ClusterOperator
 	}
 
 	existing, err := r.GetClusterOperator()
+	if !errors.IsNotFound(err) {
+		return existing, err
+	}
 
-	if errors.IsNotFound(err) {
-		return r.configClient.ConfigV1().ClusterOperators().Create(context.Background(), clusterOperator, metav1.CreateOptions{})
+	created, err := r.configClient.ConfigV1().ClusterOperators().Create(context.Background(), clusterOperator, metav1.CreateOptions{})
+	if errors.IsAlreadyExists(err) {
+		// The object was created since, but is not in the cache yet.
+		return r.configClient.ConfigV1().ClusterOperators().Get(context.Background(), OperatorName, metav1.GetOptions{})
 	}
 
-	return existing, err
+	return created, err
 }
 
 // ApplyStatus applies the given ClusterOperator status to the operator's
 // ClusterOperator object if necessary.  The currently configured RelatedObjects
-// are automatically set on the status.  If no ClusterOperator objects exists,
-// one is created.
+// and the status extension are automatically set on the status.  If no
+// ClusterOperator objects exists, one is created.
 func (r *StatusReporter) ApplyStatus(status configv1.ClusterOperatorStatus) error {
 	var modified bool
 
@@ -143,22 +236,17 @@ func (r *StatusReporter) ApplyStatus(status configv1.ClusterOperatorStatus) erro
 		return err
 	}
 
-	// There currently is no circumstance that prevents the operator from
-	// upgrading, so we always set OperatorUpgradeable to true here.
-	upgradeable := configv1.ClusterOperatorStatusCondition{
-		Type:   configv1.OperatorUpgradeable,
-		Status: configv1.ConditionTrue,
-	}
+	// Block upgrades while the configuration is at risk of breaking with
+	// the next release.
+	upgradeable := r.upgradeableCondition(co)
 
 	v1helpers.SetStatusCondition(&status.Conditions, upgradeable, &clock.RealClock{})
 
 	// Set the currently configured related objects.
-	// If a relatedObjectsGetter is configured, fetch them dynamically
-	if r.relatedObjectsGetter != nil {
-		status.RelatedObjects = r.relatedObjectsGetter.RelatedObjects()
-	} else {
-		status.RelatedObjects = r.config.RelatedObjects
-	}
+	status.RelatedObjects = r.relatedObjects()
+
+	// Publish a summary of the autoscaling configuration.
+	status.Extension = r.statusExtension(co)
 
 	// If no versions were set explicitly, continue reporting previous versions.
 	if status.Versions == nil {
@@ -221,7 +309,7 @@ func ensureClusterOperatorStatus(modified *bool, existing *configv1.ClusterOpera
 		*modified = true
 		existing.Versions = required.Versions
 	}
-	if !equality.Semantic.DeepEqual(existing.Extension.Raw, required.Extension.Raw) {
+	if !rawExtensionEqual(existing.Extension.Raw, required.Extension.Raw) {
 		*modified = true
 		existing.Extension.Raw = required.Extension.Raw
 	}
@@ -344,7 +432,12 @@ func (r *StatusReporter) progressing(reason, message string, versions []configv1
 // changes are ignored. Returns false when no versions are currently reported
 // (initial run) or when the ClusterOperator cannot be fetched.
 func (r *StatusReporter) shouldSetProgressingForVersionChange() (bool, error) {
-	co, err := r.GetOrCreateClusterOperator()
+	co, err := r.GetClusterOperator()
+	if errors.IsNotFound(err) {
+		// No versions are reported yet, the ClusterOperator is created
+		// when the status is applied.
+		return false, nil
+	}
 	if err != nil {
 		return false, fmt.Errorf("failed to get ClusterOperator: %w", err)
 	}
@@ -376,19 +469,48 @@ func (r *StatusReporter) shouldSetProgressingForVersionChange() (bool, error) {
 	return false, nil
 }
 
+// relatedObjects returns the related objects to set on the ClusterOperator
+// status.  If a relatedObjectsGetter is configured, they are fetched
+// dynamically, and cached until invalidated.
+func (r *StatusReporter) relatedObjects() []configv1.ObjectReference {
+	if r.relatedObjectsGetter == nil {
+		return r.config.RelatedObjects
+	}
+
+	// Mark the cache valid before rebuilding it, so that invalidations
+	// while rebuilding are not lost.
+	if !r.relatedObjectsValid.Swap(true) {
+		r.relatedObjectsCache = r.relatedObjectsGetter.RelatedObjects()
+	}
+
+	return r.relatedObjectsCache
+}
+
+// invalidateRelatedObjects marks the cached related objects as stale, so
+// that they are rebuilt with the next status report.
+func (r *StatusReporter) invalidateRelatedObjects() {
+	r.relatedObjectsValid.Store(false)
+}
+
 func (c *StatusReporter) processNextWorkItem() {
 	key, quit := c.queue.Get()
 	if quit {
 		return
 	}
 	defer c.queue.Done(key)
-	_, err := c.ReportStatus()
+	ok, err := c.ReportStatus()
 	if err != nil {
 		klog.Errorf("status reporting failed: %v", err)
 		c.queue.AddRateLimited(key)
 		return
 	}
 	c.queue.Forget(key)
+
+	// Keep checking while not available, as not all dependencies are
+	// watched, and reporting degraded requires consecutive failures.
+	if !ok {
+		c.queue.AddAfter(key, statusRetryInterval)
+	}
 }
 
 func (c *StatusReporter) runWorker(queueCtx context.Context) {
@@ -408,48 +530,63 @@ func (c *StatusReporter) runWorker(queueCtx context.Context) {
 }
 
 // Start checks the status of dependencies and reports the operator's status. It
-// will poll until stopCh is closed or prerequisites are satisfied, in which
-// case it will report the operator as available the configured version and wait
-// for stopCh to close before returning.
+// reports the status whenever the ClusterOperators, the operand or the related
+// objects change, and periodically as a safety resync, until stopCh is closed.
 func (r *StatusReporter) Start(stop context.Context) error {
 	// run informer to make sure that we report status everytime the cluster operator changes
 	// for example. if some external process override message or reason.
+	// The Infrastructure and the ClusterOperators of dependencies are also
+	// read from these informers.  They must be requested before starting
+	// the factory.
+	operatorInformer := r.configInformers.Config().V1().ClusterOperators().Informer()
+	infrastructureInformer := r.configInformers.Config().V1().Infrastructures().Informer()
+
 	klog.Info("Starting status reporter: cluster operator informers")
-	go r.configInformers.Start(stop.Done())
+	r.configInformers.Start(stop.Done())
 
 	cacheWaitCtx, cacheWaitCancel := context.WithTimeout(stop, 30*time.Second)
 	defer cacheWaitCancel()
 
-	operatorInformer := r.configInformers.Config().V1().ClusterOperators().Informer()
 	if !cache.WaitForCacheSync(cacheWaitCtx.Done(), operatorInformer.HasSynced) {
 		return fmt.Errorf("unable to sync clusteroperators informer")
 	}
 
+	if !cache.WaitForCacheSync(cacheWaitCtx.Done(), infrastructureInformer.HasSynced) {
+		return fmt.Errorf("unable to sync infrastructures informer")
+	}
+
 	klog.Info("Starting status reporter: worker")
 	go r.runWorker(stop)
 
-	operatorInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc: func(obj interface{}) {
-			r.queue.Add("cluster")
-		},
-		UpdateFunc: func(oldObj, newObj interface{}) {
-			r.queue.Add("cluster")
-		},
-		DeleteFunc: func(obj interface{}) {
-			r.queue.Add("cluster")
-		},
-	})
+	for _, informer := range []cache.SharedIndexInformer{operatorInformer, infrastructureInformer} {
+		informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				r.queue.Add(statusQueueKey)
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				r.queue.Add(statusQueueKey)
+			},
+			DeleteFunc: func(obj interface{}) {
+				r.queue.Add(statusQueueKey)
+			},
+		})
+	}
 
-	interval := 15 * time.Second
+	// Report the status on changes to the operand and its related objects.
+	klog.Info("Starting status reporter: operand informers")
+	if err := r.addEventHandlers(stop); err != nil {
+		return fmt.Errorf("unable to watch status dependencies: %v", err)
+	}
 
-	// Poll the status of our prerequisites and set our status accordingly.
-	// Rather than return errors and stop polling, errors here should just be
-	// reported in the status message or logged.
+	// Periodically resync the status of our prerequisites and set our status
+	// accordingly.  Rather than return errors and stop polling, errors here
+	// should just be reported in the status message or logged.
 	pollFunc := func() (bool, error) {
-		r.queue.Add("cluster")
+		r.invalidateRelatedObjects()
+		r.queue.Add(statusQueueKey)
 		return false, nil
 	}
-	err := wait.PollImmediateUntil(interval, pollFunc, stop.Done())
+	err := wait.PollImmediateUntil(statusResyncInterval, pollFunc, stop.Done())
 
 	// Block until the stop channel is closed.
 	<-stop.Done()
@@ -471,6 +608,18 @@ func (r *StatusReporter) ReportStatus() (bool, error) {
 		return false, r.degraded(ReasonMissingDependency, "machine-api not ready")
 	}
 
+	// Check that the cluster-api-operator and its CRDs are ready when the
+	// cluster-autoscaler discovers Cluster API node groups.
+	dependency, err := r.CheckClusterAPI()
+	if err != nil {
+		msg := fmt.Sprintf("error checking cluster-api status: %v", err)
+		return false, r.degraded(ReasonCheckClusterAPI, msg)
+	}
+
+	if dependency != nil {
+		return false, r.degraded(dependency.Reason, dependency.Message)
+	}
+
 	// Check that any CluterAutoscaler deployments are updated and available.
 	ok, err = r.CheckClusterAutoscaler()
 	if err != nil {
@@ -494,6 +643,19 @@ func (r *StatusReporter) ReportStatus() (bool, error) {
 		return false, r.degraded(ReasonCheckAutoscaler, msg)
 	}
 
+	// Check that MachineAutoscalers reconcile.  Failures below the
+	// threshold, recent failures and user errors are only mentioned in the
+	// Available message.
+	maHealth, err := r.CheckMachineAutoscalers()
+	if err != nil {
+		msg := fmt.Sprintf("error checking MachineAutoscaler status: %v", err)
+		return false, r.degraded(ReasonCheckMachineAutoscalers, msg)
+	}
+
+	if maHealth.Degraded(r.config.MachineAutoscalerDegradedThreshold) {
+		return false, r.degraded(ReasonMachineAutoscalersFailing, maHealth.Message())
+	}
+
 	r.degradedConsecutiveCount = 0
 
 	if versionUpgrade {
@@ -504,20 +666,42 @@ func (r *StatusReporter) ReportStatus() (bool, error) {
 	}
 
 	msg := fmt.Sprintf("at version %s", r.config.ReleaseVersion)
-	if err := r.available(ReasonAsExpected, msg); err != nil {
+	if len(maHealth.Failing) > 0 {
+		msg = fmt.Sprintf("%s; %s", msg, maHealth.Message())
+	}
+
+	// Check that the cluster-autoscaler is able to autoscale, as reported
+	// by the autoscaler itself.  Problems such as backed off node groups
+	// are usually caused by the cloud or the cluster rather than the
+	// operator, so they are only reported in the Available condition.
+	reason := ReasonAsExpected
+	healthKnown := true
+
+	problem, err := r.CheckAutoscalerHealth()
+	if err != nil {
+		healthKnown = false
+	}
+
+	if problem != nil {
+		reason = problem.Reason
+		msg = fmt.Sprintf("%s; %s", msg, problem.Message)
+	}
+
+	if err := r.available(reason, msg); err != nil {
 		return false, err
 	}
 
-	return true, nil
+	// Keep checking failing MachineAutoscalers until their failure is old
+	// enough to count towards reporting degraded, and retry checking the
+	// autoscaler's health on errors.
+	return maHealth.Pending == 0 && healthKnown, nil
 }
 
 // CheckMachineAPI checks the status of the machine-api-operator as
 // reported to the CVO.  It returns true if the operator is available
 // and not degraded.
 func (r *StatusReporter) CheckMachineAPI() (bool, error) {
-	mao, err := r.configClient.ConfigV1().ClusterOperators().
-		Get(context.Background(), "machine-api", metav1.GetOptions{})
-
+	mao, err := r.configInformers.Config().V1().ClusterOperators().Lister().Get("machine-api")
 	if err != nil {
 		klog.Errorf("failed to get dependency machine-api status: %v", err)
 		return false, err
@@ -581,3 +765,86 @@ func (r *StatusReporter) CheckClusterAutoscaler() (bool, error) {
 
 	return true, nil
 }
+
+// MachineAutoscalerHealth summarizes the reconcile health of the
+// MachineAutoscalers, as reported by their Ready condition.
+type MachineAutoscalerHealth struct {
+	// Total is the number of MachineAutoscalers.
+	Total int
+
+	// Failing lists the MachineAutoscalers whose most recent reconcile
+	// failed, as "namespace/name (reason)", sorted.
+	Failing []string
+
+	// Persistent is the number of failing MachineAutoscalers which count
+	// towards reporting the operator degraded: those failing for at least
+	// machineAutoscalerFailurePeriod, for other reasons than user errors.
+	Persistent int
+
+	// Pending is the number of failing MachineAutoscalers which will count
+	// towards reporting the operator degraded if their failure persists.
+	Pending int
+}
+
+// userErrorReasons are the reasons of the MachineAutoscaler Ready condition
+// which are due to the configuration of the MachineAutoscaler itself.  They
+// are reported on the MachineAutoscaler, but do not degrade the operator.
+var userErrorReasons = map[string]bool{
+	autoscalingv1.InvalidReason:        true,
+	autoscalingv1.TargetNotFoundReason: true,
+}
+
+// Degraded returns whether the persistently failing MachineAutoscalers reach
+// the given percentage of all MachineAutoscalers.
+func (h MachineAutoscalerHealth) Degraded(thresholdPercent int) bool {
+	return h.Persistent > 0 && h.Persistent*100 >= thresholdPercent*h.Total
+}
+
+// Message returns a status message listing the failing MachineAutoscalers.
+func (h MachineAutoscalerHealth) Message() string {
+	listed, more := h.Failing, ""
+
+	if len(listed) > maxListedMachineAutoscalers {
+		more = fmt.Sprintf(" and %d more", len(listed)-maxListedMachineAutoscalers)
+		listed = listed[:maxListedMachineAutoscalers]
+	}
+
+	return fmt.Sprintf("%d of %d MachineAutoscalers failing to reconcile: %s%s",
+		len(h.Failing), h.Total, strings.Join(listed, ", "), more)
+}
+
+// CheckMachineAutoscalers checks the Ready condition of all
+// MachineAutoscalers and returns a summary of their health.
+// MachineAutoscalers not reconciled yet are not considered failing.
+func (r *StatusReporter) CheckMachineAutoscalers() (MachineAutoscalerHealth, error) {
+	mas := &autoscalingv1.MachineAutoscalerList{}
+
+	if err := r.client.List(context.TODO(), mas); err != nil {
+		klog.Errorf("Error listing MachineAutoscalers: %v", err)
+		return MachineAutoscalerHealth{}, err
+	}
+
+	health := MachineAutoscalerHealth{Total: len(mas.Items)}
+	now := time.Now()
+
+	for _, ma := range mas.Items {
+		cond := meta.FindStatusCondition(ma.Status.Conditions, autoscalingv1.ReadyCondition)
+		if cond == nil || cond.Status != metav1.ConditionFalse {
+			continue
+		}
+
+		health.Failing = append(health.Failing, fmt.Sprintf("%s/%s (%s)", ma.Namespace, ma.Name, cond.Reason))
+
+		switch {
+		case userErrorReasons[cond.Reason]:
+		case now.Sub(cond.LastTransitionTime.Time) >= machineAutoscalerFailurePeriod:
+			health.Persistent++
+		default:
+			health.Pending++
+		}
+	}
+
+	sort.Strings(health.Failing)
+
+	return health, nil
+}
@@ -1,12 +1,14 @@
 package operator
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
 
 	configv1 "github.com/openshift/api/config/v1"
 	fakeconfigclient "github.com/openshift/client-go/config/clientset/versioned/fake"
+	configv1informers "github.com/openshift/client-go/config/informers/externalversions"
 	"github.com/openshift/cluster-autoscaler-operator/pkg/apis"
 	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
 	"github.com/openshift/cluster-autoscaler-operator/pkg/util"
@@ -18,6 +20,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes/scheme"
 	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -26,7 +29,9 @@ func init() {
 	apis.AddToScheme(scheme.Scheme)
 }
 
-var ClusterOperatorGroupResource = schema.ParseGroupResource("clusteroperators.config.openshift.io")
+// ClusterOperatorGroupResource is the resource reported in errors of the
+// ClusterOperator lister.
+var ClusterOperatorGroupResource = schema.ParseGroupResource("clusteroperator.config.openshift.io")
 
 var ErrMachineAPINotFound = errors.NewNotFound(ClusterOperatorGroupResource, "machine-api")
 
@@ -124,6 +129,58 @@ var TestStatusReporterConfig = StatusReporterConfig{
 	ClusterAutoscalerNamespace: ClusterAutoscalerNamespace,
 	ReleaseVersion:             ReleaseVersion,
 	RelatedObjects:             []configv1.ObjectReference{},
+
+	MachineAutoscalerDegradedThreshold: 50,
+}
+
+// getClusterOperator reads the operator's ClusterOperator from the API, as the
+// informer cache of the reporter may not have observed the latest status yet.
+func getClusterOperator(reporter *StatusReporter) (*configv1.ClusterOperator, error) {
+	return reporter.configClient.ConfigV1().ClusterOperators().Get(context.TODO(), OperatorName, metav1.GetOptions{})
+}
+
+// waitForClusterOperatorCache waits for the informer cache of the reporter to
+// observe the latest version of the operator's ClusterOperator, as it has by
+// the time a report is triggered by its update.
+func waitForClusterOperatorCache(t *testing.T, reporter *StatusReporter) {
+	t.Helper()
+
+	err := wait.PollUntilContextTimeout(t.Context(), 10*time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+		latest, err := getClusterOperator(reporter)
+		if err != nil {
+			return false, nil
+		}
+
+		cached, err := reporter.GetClusterOperator()
+		if err != nil {
+			return false, nil
+		}
+
+		// The fake clientset does not set resource versions.
+		return equality.Semantic.DeepEqual(cached, latest), nil
+	})
+	if err != nil {
+		t.Fatalf("ClusterOperator not observed by the informer cache: %v", err)
+	}
+}
+
+// startConfigInformers sets the config informers of the given reporter up
+// from its config client, and waits for them to sync, as done by Start.
+
+func startConfigInformers(t *testing.T, reporter *StatusReporter) {
+	t.Helper()
+
+	reporter.configInformers = configv1informers.NewSharedInformerFactory(reporter.configClient, 0)
+	reporter.configInformers.Config().V1().ClusterOperators().Informer()
+	reporter.configInformers.Config().V1().Infrastructures().Informer()
+
+	reporter.configInformers.Start(t.Context().Done())
+
+	for informer, synced := range reporter.configInformers.WaitForCacheSync(t.Context().Done()) {
+		if !synced {
+			t.Fatalf("unable to sync informer for %v", informer)
+		}
+	}
 }
 
 // clusterAutoscaler is the default ClusterAutoscaler object used in test setup.
@@ -137,6 +194,27 @@ var clusterAutoscaler = &autoscalingv1.ClusterAutoscaler{
 	},
 }
 
+// newMachineAutoscaler returns a MachineAutoscaler with the given Ready
+// condition status and reason.
+func newMachineAutoscaler(name string, ready metav1.ConditionStatus, reason string) *autoscalingv1.MachineAutoscaler {
+	return &autoscalingv1.MachineAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ClusterAutoscalerNamespace,
+		},
+		Status: autoscalingv1.MachineAutoscalerStatus{
+			Conditions: []metav1.Condition{
+				{
+					Type:               autoscalingv1.ReadyCondition,
+					Status:             ready,
+					Reason:             reason,
+					LastTransitionTime: ConditionTransitionTime,
+				},
+			},
+		},
+	}
+}
+
 // Common Kubernetes fixture objects.
 var (
 	machineAPI = helpers.NewTestClusterOperator(&configv1.ClusterOperator{
@@ -179,6 +257,48 @@ var (
 	})
 )
 
+// TestReportStatusClusterOperatorReads validates that ClusterOperators are
+// only read from the informer cache, and that the API server is only used to
+// create the operator's ClusterOperator and update its status.
+func TestReportStatusClusterOperatorReads(t *testing.T) {
+	configClient := fakeconfigclient.NewSimpleClientset(machineAPI.WithConditions(AvailableConditions).Object())
+
+	reporter := &StatusReporter{
+		client:       fakeclient.NewFakeClient(),
+		configClient: configClient,
+		config:       &TestStatusReporterConfig,
+	}
+
+	startConfigInformers(t, reporter)
+
+	verbs := map[string]int{}
+	for range 2 {
+		// Only the actions of the report are counted, not the reads of
+		// the test waiting for the cache.
+		before := len(configClient.Actions())
+
+		if _, err := reporter.ReportStatus(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for _, action := range configClient.Actions()[before:] {
+			if action.GetResource().Resource == "clusteroperators" {
+				verbs[action.GetVerb()]++
+			}
+		}
+
+		waitForClusterOperatorCache(t, reporter)
+	}
+
+	if verbs["get"] != 0 {
+		t.Errorf("expected no ClusterOperator gets, got %v", verbs)
+	}
+
+	if verbs["create"] != 1 {
+		t.Errorf("expected 1 ClusterOperator create, got %v", verbs)
+	}
+}
+
 func TestCheckMachineAPI(t *testing.T) {
 	testCases := []struct {
 		label        string
@@ -218,6 +338,8 @@ func TestCheckMachineAPI(t *testing.T) {
 				config:       &TestStatusReporterConfig,
 			}
 
+			startConfigInformers(t, reporter)
+
 			ok, err := reporter.CheckMachineAPI()
 
 			if ok != tc.expectedBool {
@@ -289,6 +411,8 @@ func TestCheckCheckClusterAutoscaler(t *testing.T) {
 				config:       &TestStatusReporterConfig,
 			}
 
+			startConfigInformers(t, reporter)
+
 			ok, err := reporter.CheckClusterAutoscaler()
 
 			if ok != tc.expectedBool {
@@ -350,6 +474,8 @@ func TestStatusChanges(t *testing.T) {
 				configClient: fakeconfigclient.NewSimpleClientset(),
 				config:       &TestStatusReporterConfig,
 			}
+
+			startConfigInformers(t, reporter)
 			_, err := reporter.GetOrCreateClusterOperator()
 			if err != nil {
 				t.Errorf("creating ClusterOperator: %v", err)
@@ -360,7 +486,7 @@ func TestStatusChanges(t *testing.T) {
 				t.Errorf("error applying status: %v", err)
 			}
 
-			co, err := reporter.GetClusterOperator()
+			co, err := getClusterOperator(reporter)
 			if err != nil {
 				t.Errorf("error getting ClusterOperator: %v", err)
 			}
@@ -469,6 +595,8 @@ func TestShouldSetProgressingForVersionChange(t *testing.T) {
 				},
 			}
 
+			startConfigInformers(t, reporter)
+
 			result, err := reporter.shouldSetProgressingForVersionChange()
 
 			if tc.expectError && err == nil {
@@ -595,6 +723,85 @@ func TestReportStatus(t *testing.T) {
 			},
 			reportStatusCalls: 1,
 		},
+		{
+			label:         "failing MachineAutoscalers: initial failures (below threshold)",
+			versionChange: true,
+			expectedBool:  false,
+			expectedErr:   nil,
+			expectedConds: AvailableConditions,
+			clientObjs: []runtime.Object{
+				newMachineAutoscaler("ready", metav1.ConditionTrue, autoscalingv1.TargetUpdatedReason),
+				newMachineAutoscaler("error", metav1.ConditionFalse, autoscalingv1.TargetErrorReason),
+			},
+			configObjs: []runtime.Object{
+				machineAPI.WithConditions(AvailableConditions).Object(),
+			},
+			reportStatusCalls: 1,
+		},
+		{
+			label:         "failing MachineAutoscalers: persistent failures (met threshold)",
+			versionChange: true,
+			expectedBool:  false,
+			expectedErr:   nil,
+			expectedConds: DegradedConditions,
+			clientObjs: []runtime.Object{
+				newMachineAutoscaler("ready", metav1.ConditionTrue, autoscalingv1.TargetUpdatedReason),
+				newMachineAutoscaler("error", metav1.ConditionFalse, autoscalingv1.TargetErrorReason),
+			},
+			configObjs: []runtime.Object{
+				machineAPI.WithConditions(AvailableConditions).Object(),
+			},
+			reportStatusCalls: DegradedCountThreshold,
+		},
+		{
+			label:         "failing MachineAutoscalers: user errors",
+			versionChange: true,
+			expectedBool:  true,
+			expectedErr:   nil,
+			expectedConds: AvailableConditions,
+			clientObjs: []runtime.Object{
+				newMachineAutoscaler("invalid", metav1.ConditionFalse, autoscalingv1.InvalidReason),
+				newMachineAutoscaler("missing", metav1.ConditionFalse, autoscalingv1.TargetNotFoundReason),
+			},
+			configObjs: []runtime.Object{
+				machineAPI.WithConditions(AvailableConditions).Object(),
+			},
+			reportStatusCalls: DegradedCountThreshold,
+		},
+		{
+			label:         "failing MachineAutoscalers: recent failures",
+			versionChange: true,
+			expectedBool:  false,
+			expectedErr:   nil,
+			expectedConds: AvailableConditions,
+			clientObjs: []runtime.Object{
+				func() runtime.Object {
+					ma := newMachineAutoscaler("error", metav1.ConditionFalse, autoscalingv1.TargetErrorReason)
+					ma.Status.Conditions[0].LastTransitionTime = metav1.Now()
+					return ma
+				}(),
+			},
+			configObjs: []runtime.Object{
+				machineAPI.WithConditions(AvailableConditions).Object(),
+			},
+			reportStatusCalls: DegradedCountThreshold,
+		},
+		{
+			label:         "failing MachineAutoscalers below percentage threshold",
+			versionChange: true,
+			expectedBool:  true,
+			expectedErr:   nil,
+			expectedConds: AvailableConditions,
+			clientObjs: []runtime.Object{
+				newMachineAutoscaler("ready-1", metav1.ConditionTrue, autoscalingv1.TargetUpdatedReason),
+				newMachineAutoscaler("ready-2", metav1.ConditionTrue, autoscalingv1.TargetUpdatedReason),
+				newMachineAutoscaler("conflict", metav1.ConditionFalse, autoscalingv1.TargetConflictReason),
+			},
+			configObjs: []runtime.Object{
+				machineAPI.WithConditions(AvailableConditions).Object(),
+			},
+			reportStatusCalls: DegradedCountThreshold,
+		},
 		{
 			label:         "no version change",
 			versionChange: false,
@@ -669,10 +876,13 @@ func TestReportStatus(t *testing.T) {
 				config:       &TestStatusReporterConfig,
 			}
 
+			startConfigInformers(t, reporter)
+
 			var ok bool
 			var err error
 			for range tc.reportStatusCalls {
 				ok, err = reporter.ReportStatus()
+				waitForClusterOperatorCache(t, reporter)
 			}
 
 			if ok != tc.expectedBool {
@@ -684,7 +894,7 @@ func TestReportStatus(t *testing.T) {
 			}
 
 			// Check that the ClusterOperator status is created.
-			co, err := reporter.GetClusterOperator()
+			co, err := getClusterOperator(reporter)
 			if err != nil {
 				t.Errorf("error getting ClusterOperator: %v", err)
 			}
@@ -736,3 +946,64 @@ func TestReportStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestMachineAutoscalerHealth(t *testing.T) {
+	testCases := []struct {
+		label            string
+		health           MachineAutoscalerHealth
+		thresholdPercent int
+		expectedDegraded bool
+		expectedMessage  string
+	}{
+		{
+			label:            "none failing",
+			health:           MachineAutoscalerHealth{Total: 2},
+			thresholdPercent: 0,
+			expectedDegraded: false,
+			expectedMessage:  "0 of 2 MachineAutoscalers failing to reconcile: ",
+		},
+		{
+			label:            "any failing with zero threshold",
+			health:           MachineAutoscalerHealth{Total: 10, Failing: []string{"ns/a (TargetError)"}, Persistent: 1},
+			thresholdPercent: 0,
+			expectedDegraded: true,
+			expectedMessage:  "1 of 10 MachineAutoscalers failing to reconcile: ns/a (TargetError)",
+		},
+		{
+			label:            "below threshold",
+			health:           MachineAutoscalerHealth{Total: 10, Failing: []string{"ns/a (TargetError)"}, Persistent: 1},
+			thresholdPercent: 50,
+			expectedDegraded: false,
+			expectedMessage:  "1 of 10 MachineAutoscalers failing to reconcile: ns/a (TargetError)",
+		},
+		{
+			label:            "only recent failures or user errors",
+			health:           MachineAutoscalerHealth{Total: 2, Failing: []string{"ns/a (Invalid)", "ns/b (TargetError)"}, Pending: 1},
+			thresholdPercent: 0,
+			expectedDegraded: false,
+			expectedMessage:  "2 of 2 MachineAutoscalers failing to reconcile: ns/a (Invalid), ns/b (TargetError)",
+		},
+		{
+			label: "more failing than listed",
+			health: MachineAutoscalerHealth{Total: 7, Failing: []string{
+				"ns/a (TargetError)", "ns/b (TargetError)", "ns/c (TargetError)", "ns/d (TargetError)",
+				"ns/e (TargetError)", "ns/f (TargetError)", "ns/g (TargetError)",
+			}, Persistent: 7},
+			thresholdPercent: 100,
+			expectedDegraded: true,
+			expectedMessage:  "7 of 7 MachineAutoscalers failing to reconcile: ns/a (TargetError), ns/b (TargetError), ns/c (TargetError), ns/d (TargetError), ns/e (TargetError) and 2 more",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			if got := tc.health.Degraded(tc.thresholdPercent); got != tc.expectedDegraded {
+				t.Errorf("got degraded %t, want %t", got, tc.expectedDegraded)
+			}
+
+			if got := tc.health.Message(); got != tc.expectedMessage {
+				t.Errorf("got message %q, want %q", got, tc.expectedMessage)
+			}
+		})
+	}
+}
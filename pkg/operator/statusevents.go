@@ -0,0 +1,146 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// addEventHandlers registers handlers on the manager's informers which
+// report the status when the operand or the related objects change.
+// Changes to the objects the related objects are built from, i.e. the
+// ClusterAutoscaler, the objects owned by it, MachineAutoscalers and
+// Machines, also invalidate the cached related objects.
+func (r *StatusReporter) addEventHandlers(ctx context.Context) error {
+	deploymentName := fmt.Sprintf("%s-%s", OperatorName, r.config.ClusterAutoscalerName)
+
+	handlers := []struct {
+		obj     client.Object
+		handler cache.ResourceEventHandler
+	}{
+		{
+			obj:     &autoscalingv1.ClusterAutoscaler{},
+			handler: r.eventHandler(r.config.ClusterAutoscalerName, nil, true),
+		},
+		{
+			obj:     &appsv1.Deployment{},
+			handler: r.eventHandler(deploymentName, nil, true),
+		},
+		{
+			obj:     &networkingv1.NetworkPolicy{},
+			handler: r.eventHandler("", ownersChanged, true),
+		},
+		{
+			obj:     &corev1.ConfigMap{},
+			handler: r.eventHandler(AutoscalerStatusConfigMapName, autoscalerHealthChanged, false),
+		},
+		{
+			obj:     &autoscalingv1.MachineAutoscaler{},
+			handler: r.eventHandler("", nil, true),
+		},
+		{
+			obj:     newMachineMetadata(),
+			handler: r.eventHandler("", ownersChanged, true),
+		},
+	}
+
+	for _, h := range handlers {
+		informer, err := r.informers.GetInformer(ctx, h.obj)
+		if err != nil {
+			return fmt.Errorf("unable to get informer for %T: %v", h.obj, err)
+		}
+
+		if _, err := informer.AddEventHandler(h.handler); err != nil {
+			return fmt.Errorf("unable to add event handler for %T: %v", h.obj, err)
+		}
+	}
+
+	return nil
+}
+
+// eventHandler returns a handler queuing a status report for events on the
+// object with the given name, or any object if empty.  Updates are ignored
+// unless the given changed function, if any, returns true.  If related is
+// true, events also invalidate the cached related objects.
+func (r *StatusReporter) eventHandler(name string, changed func(oldObj, newObj interface{}) bool, related bool) cache.ResourceEventHandler {
+	enqueue := func() {
+		if related {
+			r.invalidateRelatedObjects()
+		}
+
+		r.queue.Add(statusQueueKey)
+	}
+
+	return cache.FilteringResourceEventHandler{
+		FilterFunc: func(obj interface{}) bool {
+			if name == "" {
+				return true
+			}
+
+			// Deleted objects may be wrapped in a tombstone, which are
+			// conservatively handled.
+			if o, ok := obj.(metav1.Object); ok {
+				return o.GetName() == name
+			}
+
+			return true
+		},
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				enqueue()
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				if changed != nil && !changed(oldObj, newObj) {
+					return
+				}
+
+				enqueue()
+			},
+			DeleteFunc: func(obj interface{}) {
+				enqueue()
+			},
+		},
+	}
+}
+
+// ownersChanged returns true if the owners of an object, e.g. a Machine or
+// a NetworkPolicy, changed.  Other updates, e.g. of the status, do not
+// affect the related objects.
+func ownersChanged(oldObj, newObj interface{}) bool {
+	oldMeta, ok := oldObj.(metav1.Object)
+	if !ok {
+		return true
+	}
+
+	newMeta, ok := newObj.(metav1.Object)
+	if !ok {
+		return true
+	}
+
+	return !reflect.DeepEqual(oldMeta.GetOwnerReferences(), newMeta.GetOwnerReferences())
+}
+
+// autoscalerHealthChanged returns true if the health reported in the
+// cluster-autoscaler status ConfigMap changed.  The status is rewritten by
+// the autoscaler on every loop, mostly with only a new timestamp.
+func autoscalerHealthChanged(oldObj, newObj interface{}) bool {
+	oldCM, ok := oldObj.(*corev1.ConfigMap)
+	if !ok {
+		return true
+	}
+
+	newCM, ok := newObj.(*corev1.ConfigMap)
+	if !ok {
+		return true
+	}
+
+	return !reflect.DeepEqual(autoscalerHealth(oldCM), autoscalerHealth(newCM))
+}
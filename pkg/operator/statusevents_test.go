@@ -0,0 +1,262 @@
+package operator
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	fakeconfigclient "github.com/openshift/client-go/config/clientset/versioned/fake"
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	runtimecache "sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// countingRelatedObjectsGetter counts the calls to RelatedObjects.
+type countingRelatedObjectsGetter struct {
+	calls int
+}
+
+func (g *countingRelatedObjectsGetter) RelatedObjects() []configv1.ObjectReference {
+	g.calls++
+
+	return []configv1.ObjectReference{{Resource: "namespaces", Name: "test"}}
+}
+
+func TestRelatedObjectsCache(t *testing.T) {
+	getter := &countingRelatedObjectsGetter{}
+	reporter := &StatusReporter{
+		config:               &TestStatusReporterConfig,
+		relatedObjectsGetter: getter,
+	}
+
+	expectCalls := func(expected int) {
+		t.Helper()
+
+		if objs := reporter.relatedObjects(); len(objs) != 1 {
+			t.Errorf("unexpected related objects: %+v", objs)
+		}
+
+		if getter.calls != expected {
+			t.Errorf("got %d calls to RelatedObjects, want %d", getter.calls, expected)
+		}
+	}
+
+	expectCalls(1)
+	expectCalls(1)
+
+	reporter.invalidateRelatedObjects()
+	expectCalls(2)
+	expectCalls(2)
+}
+
+// clientRelatedObjectsGetter returns the Deployment of the ClusterAutoscaler
+// as related object if the ClusterAutoscaler exists, as the
+// ClusterAutoscaler reconciler does.
+type clientRelatedObjectsGetter struct {
+	client client.Client
+}
+
+func (g *clientRelatedObjectsGetter) RelatedObjects() []configv1.ObjectReference {
+	ca := &autoscalingv1.ClusterAutoscaler{}
+	if err := g.client.Get(context.TODO(), client.ObjectKey{Name: ClusterAutoscalerName}, ca); err != nil {
+		return nil
+	}
+
+	return []configv1.ObjectReference{
+		{Group: "apps", Resource: "deployments", Name: "cluster-autoscaler-test", Namespace: ClusterAutoscalerNamespace},
+	}
+}
+
+// fakeInformers records the event handlers registered on its informers, by
+// type of object.
+type fakeInformers struct {
+	runtimecache.Informers
+	handlers map[reflect.Type][]cache.ResourceEventHandler
+}
+
+func (f *fakeInformers) GetInformer(_ context.Context, obj client.Object, _ ...runtimecache.InformerGetOption) (runtimecache.Informer, error) {
+	return &fakeInformer{informers: f, objType: reflect.TypeOf(obj)}, nil
+}
+
+// add sends an add event for the given object to the registered handlers.
+func (f *fakeInformers) add(obj client.Object) {
+	for _, h := range f.handlers[reflect.TypeOf(obj)] {
+		h.OnAdd(obj, false)
+	}
+}
+
+type fakeInformer struct {
+	runtimecache.Informer
+	informers *fakeInformers
+	objType   reflect.Type
+}
+
+func (i *fakeInformer) AddEventHandler(handler cache.ResourceEventHandler) (cache.ResourceEventHandlerRegistration, error) {
+	i.informers.handlers[i.objType] = append(i.informers.handlers[i.objType], handler)
+	return nil, nil
+}
+
+func TestRelatedObjectsRefresh(t *testing.T) {
+	c := fakeclient.NewFakeClient()
+	informers := &fakeInformers{handlers: map[reflect.Type][]cache.ResourceEventHandler{}}
+
+	reporter := &StatusReporter{
+		client:               c,
+		configClient:         fakeconfigclient.NewSimpleClientset(),
+		config:               &TestStatusReporterConfig,
+		relatedObjectsGetter: &clientRelatedObjectsGetter{client: c},
+		informers:            informers,
+		queue:                workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[string]()),
+	}
+
+	startConfigInformers(t, reporter)
+
+	if err := reporter.addEventHandlers(t.Context()); err != nil {
+		t.Fatalf("error adding event handlers: %v", err)
+	}
+
+	expectRelatedObjects := func(expected []configv1.ObjectReference) {
+		t.Helper()
+
+		if err := reporter.available(ReasonAsExpected, "available"); err != nil {
+			t.Fatalf("error applying status: %v", err)
+		}
+
+		co, err := getClusterOperator(reporter)
+		if err != nil {
+			t.Fatalf("error getting ClusterOperator: %v", err)
+		}
+
+		if !equality.Semantic.DeepEqual(co.Status.RelatedObjects, expected) {
+			t.Errorf("got related objects %+v, want %+v", co.Status.RelatedObjects, expected)
+		}
+	}
+
+	expectRelatedObjects(nil)
+
+	ca := clusterAutoscaler.DeepCopy()
+	ca.ResourceVersion = ""
+	if err := c.Create(context.TODO(), ca); err != nil {
+		t.Fatalf("error creating ClusterAutoscaler: %v", err)
+	}
+
+	informers.add(ca)
+
+	if reporter.queue.Len() != 1 {
+		t.Errorf("expected a report")
+	}
+
+	expectRelatedObjects((&clientRelatedObjectsGetter{client: c}).RelatedObjects())
+}
+
+func TestEventHandler(t *testing.T) {
+	deployment := func(name string, replicas int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     appsv1.DeploymentStatus{Replicas: replicas},
+		}
+	}
+
+	newReporter := func() *StatusReporter {
+		reporter := &StatusReporter{
+			config:               &TestStatusReporterConfig,
+			relatedObjectsGetter: &countingRelatedObjectsGetter{},
+			queue:                workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[string]()),
+		}
+
+		// Populate the related objects cache.
+		reporter.relatedObjects()
+
+		return reporter
+	}
+
+	t.Run("name filter", func(t *testing.T) {
+		reporter := newReporter()
+		handler := reporter.eventHandler("test", nil, false)
+
+		handler.OnAdd(deployment("other", 1), false)
+		if reporter.queue.Len() != 0 {
+			t.Errorf("expected no report for another object")
+		}
+
+		handler.OnAdd(deployment("test", 1), false)
+		if reporter.queue.Len() != 1 {
+			t.Errorf("expected a report")
+		}
+
+		if !reporter.relatedObjectsValid.Load() {
+			t.Errorf("expected related objects to stay valid")
+		}
+	})
+
+	t.Run("changed filter", func(t *testing.T) {
+		reporter := newReporter()
+		replicasChanged := func(oldObj, newObj interface{}) bool {
+			return oldObj.(*appsv1.Deployment).Status.Replicas != newObj.(*appsv1.Deployment).Status.Replicas
+		}
+		handler := reporter.eventHandler("", replicasChanged, true)
+
+		handler.OnUpdate(deployment("test", 1), deployment("test", 1))
+		if reporter.queue.Len() != 0 || !reporter.relatedObjectsValid.Load() {
+			t.Errorf("expected unchanged object to be ignored")
+		}
+
+		handler.OnUpdate(deployment("test", 1), deployment("test", 2))
+		if reporter.queue.Len() != 1 {
+			t.Errorf("expected a report")
+		}
+
+		if reporter.relatedObjectsValid.Load() {
+			t.Errorf("expected related objects to be invalidated")
+		}
+	})
+}
+
+func TestOwnersChanged(t *testing.T) {
+	machine := func(owner, resourceVersion string) *metav1.PartialObjectMetadata {
+		m := newMachineMetadata()
+		m.Name = "machine"
+		m.ResourceVersion = resourceVersion
+		if owner != "" {
+			m.OwnerReferences = []metav1.OwnerReference{{Kind: "MachineSet", Name: owner}}
+		}
+
+		return m
+	}
+
+	if ownersChanged(machine("worker-a", "1"), machine("worker-a", "2")) {
+		t.Errorf("expected status update to be ignored")
+	}
+
+	if !ownersChanged(machine("", "1"), machine("worker-a", "2")) {
+		t.Errorf("expected adopted Machine to be handled")
+	}
+
+	if !ownersChanged(machine("worker-a", "1"), machine("worker-b", "2")) {
+		t.Errorf("expected new owner to be handled")
+	}
+}
+
+func TestAutoscalerHealthChanged(t *testing.T) {
+	healthy := newAutoscalerStatusConfigMap(healthyAutoscalerStatus)
+
+	// The autoscaler rewrites its status with a new timestamp on every loop.
+	rewritten := healthy.DeepCopy()
+	rewritten.Data[autoscalerStatusKey] = strings.Replace(healthyAutoscalerStatus, "00:00:00", "00:00:10", 1)
+
+	if autoscalerHealthChanged(healthy, rewritten) {
+		t.Errorf("expected rewritten status to be ignored")
+	}
+
+	if !autoscalerHealthChanged(healthy, newAutoscalerStatusConfigMap(unhealthyAutoscalerStatus)) {
+		t.Errorf("expected unhealthy status to be handled")
+	}
+}
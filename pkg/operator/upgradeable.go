@@ -0,0 +1,181 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	"github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+)
+
+// Reasons used in the Upgradeable condition.
+const (
+	ReasonDeprecatedScaleTarget = "DeprecatedScaleTargetKind"
+	ReasonRemovedAutoscalerArgs = "RemovedAutoscalerArgs"
+	ReasonMultipleUpgradeRisks  = "MultipleUpgradeRisks"
+	ReasonUpgradeableCheckError = "UpgradeableCheckError"
+)
+
+// UpgradeRisk describes why upgrading to the next release is unsafe.
+type UpgradeRisk struct {
+	// Reason is a CamelCase reason for the Upgradeable condition.
+	Reason string
+
+	// Message is a human readable explanation of the risk, including how
+	// to address it.
+	Message string
+}
+
+// UpgradeableCheck evaluates a risk of upgrading the cluster to the next
+// release.  It returns nil if there is none.
+type UpgradeableCheck func(ctx context.Context, r *StatusReporter) (*UpgradeRisk, error)
+
+// DefaultUpgradeableChecks returns the checks evaluated by a StatusReporter
+// to report the Upgradeable condition.
+func DefaultUpgradeableChecks() []UpgradeableCheck {
+	return []UpgradeableCheck{
+		checkDeprecatedScaleTargets,
+		checkRemovedAutoscalerArgs,
+	}
+}
+
+// deprecatedScaleTargetKinds maps the kinds of MachineAutoscaler targets
+// which are not supported by the next release to their replacements.
+var deprecatedScaleTargetKinds = map[schema.GroupKind]schema.GroupKind{
+	{Group: "cluster.k8s.io", Kind: "MachineSet"}:        {Group: "machine.openshift.io", Kind: "MachineSet"},
+	{Group: "cluster.k8s.io", Kind: "MachineDeployment"}: {Group: "cluster.x-k8s.io", Kind: "MachineDeployment"},
+}
+
+// removedAutoscalerArgs maps the cluster-autoscaler arguments which are
+// removed in the next release to their replacements.  Both are deprecated
+// upstream, see the parameters listed in the cluster-autoscaler FAQ:
+// https://github.com/kubernetes/autoscaler/blob/master/cluster-autoscaler/FAQ.md#what-are-the-parameters-to-ca
+// --ignore-taint is superseded by --startup-taint, and --max-empty-bulk-delete
+// by --max-scale-down-parallelism since empty and drained nodes are scaled
+// down in parallel.
+var removedAutoscalerArgs = map[string]string{
+	"--ignore-taint":          "--startup-taint",
+	"--max-empty-bulk-delete": "--max-scale-down-parallelism",
+}
+
+// checkDeprecatedScaleTargets reports MachineAutoscalers targeting a kind
+// which is not supported by the next release.
+func checkDeprecatedScaleTargets(ctx context.Context, r *StatusReporter) (*UpgradeRisk, error) {
+	mas := &autoscalingv1.MachineAutoscalerList{}
+	if err := r.client.List(ctx, mas); err != nil {
+		return nil, fmt.Errorf("unable to list MachineAutoscalers: %v", err)
+	}
+
+	var deprecated []string
+
+	for _, ma := range mas.Items {
+		ref := ma.Spec.ScaleTargetRef
+
+		// The API version may be only a group, which the mutating webhook
+		// completes for supported targets.
+		group, _, _ := strings.Cut(ref.APIVersion, "/")
+		gk := schema.GroupKind{Group: group, Kind: ref.Kind}
+
+		if replacement, ok := deprecatedScaleTargetKinds[gk]; ok {
+			deprecated = append(deprecated, fmt.Sprintf("%s/%s targets %s, use %s instead",
+				ma.Namespace, ma.Name, gk, replacement))
+		}
+	}
+
+	if len(deprecated) == 0 {
+		return nil, nil
+	}
+
+	sort.Strings(deprecated)
+
+	return &UpgradeRisk{
+		Reason:  ReasonDeprecatedScaleTarget,
+		Message: fmt.Sprintf("MachineAutoscalers target kinds unsupported by the next release: %s", strings.Join(deprecated, "; ")),
+	}, nil
+}
+
+// checkRemovedAutoscalerArgs reports extra cluster-autoscaler arguments
+// which are removed in the next release.
+func checkRemovedAutoscalerArgs(ctx context.Context, r *StatusReporter) (*UpgradeRisk, error) {
+	var removed []string
+
+	for _, arg := range strings.Fields(r.clusterAutoscalerExtraArgs()) {
+		name, _, _ := strings.Cut(arg, "=")
+
+		if replacement, ok := removedAutoscalerArgs[name]; ok {
+			removed = append(removed, fmt.Sprintf("%s, use %s instead", name, replacement))
+		}
+	}
+
+	if len(removed) == 0 {
+		return nil, nil
+	}
+
+	return &UpgradeRisk{
+		Reason:  ReasonRemovedAutoscalerArgs,
+		Message: fmt.Sprintf("extra cluster-autoscaler arguments removed in the next release: %s", strings.Join(removed, "; ")),
+	}, nil
+}
+
+// upgradeableCondition evaluates the configured upgradeable checks and
+// returns the resulting Upgradeable condition.  If a check fails, the
+// condition currently reported on the given ClusterOperator is kept, so that
+// transient errors neither block nor unblock upgrades, or Unknown is reported
+// if there is none yet.
+func (r *StatusReporter) upgradeableCondition(co *configv1.ClusterOperator) configv1.ClusterOperatorStatusCondition {
+	var risks []*UpgradeRisk
+
+	for _, check := range r.upgradeableChecks {
+		risk, err := check(context.TODO(), r)
+		if err != nil {
+			klog.Errorf("Error checking upgradeability: %v", err)
+
+			if c := v1helpers.FindStatusCondition(co.Status.Conditions, configv1.OperatorUpgradeable); c != nil {
+				return *c
+			}
+
+			return configv1.ClusterOperatorStatusCondition{
+				Type:    configv1.OperatorUpgradeable,
+				Status:  configv1.ConditionUnknown,
+				Reason:  ReasonUpgradeableCheckError,
+				Message: fmt.Sprintf("unable to check upgradeability: %v", err),
+			}
+		}
+
+		if risk != nil {
+			risks = append(risks, risk)
+		}
+	}
+
+	switch len(risks) {
+	case 0:
+		return configv1.ClusterOperatorStatusCondition{
+			Type:   configv1.OperatorUpgradeable,
+			Status: configv1.ConditionTrue,
+		}
+	case 1:
+		return configv1.ClusterOperatorStatusCondition{
+			Type:    configv1.OperatorUpgradeable,
+			Status:  configv1.ConditionFalse,
+			Reason:  risks[0].Reason,
+			Message: risks[0].Message,
+		}
+	}
+
+	messages := make([]string, len(risks))
+	for i, risk := range risks {
+		messages[i] = risk.Message
+	}
+
+	return configv1.ClusterOperatorStatusCondition{
+		Type:    configv1.OperatorUpgradeable,
+		Status:  configv1.ConditionFalse,
+		Reason:  ReasonMultipleUpgradeRisks,
+		Message: strings.Join(messages, "\n"),
+	}
+}
@@ -0,0 +1,213 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	fakeconfigclient "github.com/openshift/client-go/config/clientset/versioned/fake"
+	autoscalingv1 "github.com/openshift/cluster-autoscaler-operator/pkg/apis/autoscaling/v1"
+	"github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTargetMachineAutoscaler(name, apiVersion, kind string) *autoscalingv1.MachineAutoscaler {
+	return &autoscalingv1.MachineAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ClusterAutoscalerNamespace,
+		},
+		Spec: autoscalingv1.MachineAutoscalerSpec{
+			MinReplicas: 1,
+			MaxReplicas: 2,
+			ScaleTargetRef: autoscalingv1.ScaleTargetReference{
+				APIVersion: apiVersion,
+				Kind:       kind,
+				Name:       name,
+			},
+		},
+	}
+}
+
+func TestUpgradeableChecks(t *testing.T) {
+	testCases := []struct {
+		label           string
+		objects         []runtime.Object
+		extraArgs       string
+		expectedStatus  configv1.ConditionStatus
+		expectedReason  string
+		expectedMessage []string
+	}{
+		{
+			label: "no risks",
+			objects: []runtime.Object{
+				newTargetMachineAutoscaler("worker", "machine.openshift.io/v1beta1", "MachineSet"),
+			},
+			extraArgs:      "--scale-down-simulation-timeout=30s",
+			expectedStatus: configv1.ConditionTrue,
+		},
+		{
+			label: "deprecated target kind",
+			objects: []runtime.Object{
+				newTargetMachineAutoscaler("worker", "machine.openshift.io/v1beta1", "MachineSet"),
+				newTargetMachineAutoscaler("legacy", "cluster.k8s.io/v1alpha1", "MachineSet"),
+			},
+			expectedStatus:  configv1.ConditionFalse,
+			expectedReason:  ReasonDeprecatedScaleTarget,
+			expectedMessage: []string{"test-namespace/legacy targets MachineSet.cluster.k8s.io"},
+		},
+		{
+			label: "deprecated target group only",
+			objects: []runtime.Object{
+				newTargetMachineAutoscaler("legacy", "cluster.k8s.io", "MachineDeployment"),
+			},
+			expectedStatus:  configv1.ConditionFalse,
+			expectedReason:  ReasonDeprecatedScaleTarget,
+			expectedMessage: []string{"use MachineDeployment.cluster.x-k8s.io instead"},
+		},
+		{
+			label:           "removed extra args",
+			extraArgs:       "--ignore-taint=example.com/taint --v=4",
+			expectedStatus:  configv1.ConditionFalse,
+			expectedReason:  ReasonRemovedAutoscalerArgs,
+			expectedMessage: []string{"--ignore-taint, use --startup-taint instead"},
+		},
+		{
+			label: "multiple risks",
+			objects: []runtime.Object{
+				newTargetMachineAutoscaler("legacy", "cluster.k8s.io/v1alpha1", "MachineSet"),
+			},
+			extraArgs:      "--max-empty-bulk-delete=5",
+			expectedStatus: configv1.ConditionFalse,
+			expectedReason: ReasonMultipleUpgradeRisks,
+			expectedMessage: []string{
+				"test-namespace/legacy targets MachineSet.cluster.k8s.io",
+				"--max-empty-bulk-delete, use --max-scale-down-parallelism instead",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			cfg := TestStatusReporterConfig
+			cfg.ClusterAutoscalerExtraArgs = tc.extraArgs
+
+			reporter := &StatusReporter{
+				client:            fakeclient.NewFakeClient(tc.objects...),
+				configClient:      fakeconfigclient.NewSimpleClientset(),
+				config:            &cfg,
+				upgradeableChecks: DefaultUpgradeableChecks(),
+			}
+
+			startConfigInformers(t, reporter)
+
+			if err := reporter.available(ReasonAsExpected, "available"); err != nil {
+				t.Fatalf("error applying status: %v", err)
+			}
+
+			co, err := getClusterOperator(reporter)
+			if err != nil {
+				t.Fatalf("error getting ClusterOperator: %v", err)
+			}
+
+			cond := v1helpers.FindStatusCondition(co.Status.Conditions, configv1.OperatorUpgradeable)
+			if cond == nil {
+				t.Fatal("missing Upgradeable condition")
+			}
+
+			if cond.Status != tc.expectedStatus || cond.Reason != tc.expectedReason {
+				t.Errorf("got %s/%s, want %s/%s", cond.Status, cond.Reason, tc.expectedStatus, tc.expectedReason)
+			}
+
+			for _, m := range tc.expectedMessage {
+				if !strings.Contains(cond.Message, m) {
+					t.Errorf("message %q does not contain %q", cond.Message, m)
+				}
+			}
+		})
+	}
+}
+
+func TestUpgradeableClearedOnceFixed(t *testing.T) {
+	cfg := TestStatusReporterConfig
+	cfg.ClusterAutoscalerExtraArgs = "--ignore-taint=example.com/taint"
+
+	reporter := &StatusReporter{
+		client:            fakeclient.NewFakeClient(),
+		configClient:      fakeconfigclient.NewSimpleClientset(),
+		config:            &cfg,
+		upgradeableChecks: DefaultUpgradeableChecks(),
+	}
+
+	startConfigInformers(t, reporter)
+
+	expectUpgradeable := func(status configv1.ConditionStatus) {
+		t.Helper()
+
+		if err := reporter.available(ReasonAsExpected, "available"); err != nil {
+			t.Fatalf("error applying status: %v", err)
+		}
+
+		co, err := getClusterOperator(reporter)
+		if err != nil {
+			t.Fatalf("error getting ClusterOperator: %v", err)
+		}
+
+		if !v1helpers.IsStatusConditionPresentAndEqual(co.Status.Conditions, configv1.OperatorUpgradeable, status) {
+			t.Errorf("expected Upgradeable=%s, got %+v", status, co.Status.Conditions)
+		}
+	}
+
+	expectUpgradeable(configv1.ConditionFalse)
+
+	cfg.ClusterAutoscalerExtraArgs = "--startup-taint=example.com/taint"
+	expectUpgradeable(configv1.ConditionTrue)
+
+	// A failing check keeps the previously reported condition.
+	cfg.ClusterAutoscalerExtraArgs = "--ignore-taint=example.com/taint"
+	expectUpgradeable(configv1.ConditionFalse)
+
+	reporter.upgradeableChecks = append(reporter.upgradeableChecks,
+		func(context.Context, *StatusReporter) (*UpgradeRisk, error) {
+			return nil, fmt.Errorf("test error")
+		})
+	cfg.ClusterAutoscalerExtraArgs = ""
+	expectUpgradeable(configv1.ConditionFalse)
+}
+
+func TestUpgradeableUnknownOnCheckError(t *testing.T) {
+	cfg := TestStatusReporterConfig
+
+	reporter := &StatusReporter{
+		client:       fakeclient.NewFakeClient(),
+		configClient: fakeconfigclient.NewSimpleClientset(),
+		config:       &cfg,
+		upgradeableChecks: []UpgradeableCheck{
+			func(context.Context, *StatusReporter) (*UpgradeRisk, error) {
+				return nil, fmt.Errorf("test error")
+			},
+		},
+	}
+
+	startConfigInformers(t, reporter)
+
+	if err := reporter.available(ReasonAsExpected, "available"); err != nil {
+		t.Fatalf("error applying status: %v", err)
+	}
+
+	co, err := getClusterOperator(reporter)
+	if err != nil {
+		t.Fatalf("error getting ClusterOperator: %v", err)
+	}
+
+	// Without a previous condition to keep, upgrades are neither allowed
+	// nor blocked.
+	cond := v1helpers.FindStatusCondition(co.Status.Conditions, configv1.OperatorUpgradeable)
+	if cond == nil || cond.Status != configv1.ConditionUnknown || cond.Reason != ReasonUpgradeableCheckError {
+		t.Errorf("expected Upgradeable=Unknown with reason %s, got %+v", ReasonUpgradeableCheckError, cond)
+	}
+}
@@ -0,0 +1,381 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/openshift/library-go/pkg/crypto"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// webhookCertControllerName is the name of the controller managing the
+	// self-managed webhook certificates.
+	webhookCertControllerName = "webhook_cert_controller"
+
+	// WebhookCACertKey and WebhookCAKeyKey are the keys of the webhook
+	// certificate Secret holding the current CA certificate and key.
+	WebhookCACertKey = "ca.crt"
+	WebhookCAKeyKey  = "ca.key"
+
+	// WebhookCABundleKey is the key of the webhook certificate Secret holding
+	// the CA bundle injected into the webhook configurations.  It contains the
+	// current CA and, after a rotation, the previous one until it expires.
+	WebhookCABundleKey = "ca-bundle.crt"
+
+	// webhookCALifetime is the validity period of generated CA certificates.
+	webhookCALifetime = 2 * 365 * 24 * time.Hour
+
+	// webhookServingCertLifetime is the validity period of generated serving
+	// certificates.
+	webhookServingCertLifetime = 365 * 24 * time.Hour
+
+	// webhookCertRefresh is how long before expiry certificates are rotated.
+	webhookCertRefresh = 30 * 24 * time.Hour
+
+	// minWebhookCertRequeue is the minimum delay before the certificates are
+	// checked again.
+	minWebhookCertRequeue = time.Minute
+)
+
+// WebhookCertManager generates a CA and a serving certificate for the
+// operator's webhook server into a Secret, rotates them before they expire,
+// and serves the current certificate to the webhook server.  It is used in
+// place of the service-ca-operator on clusters where it is not available.
+//
+// The controller runs on every replica, as each of them serves webhook
+// requests.  Concurrent writes to the Secret are resolved by the API server's
+// optimistic concurrency, the losing replica loading the winner's certificate
+// on its next reconcile.
+type WebhookCertManager struct {
+	client      client.Client
+	namespace   string
+	secretName  string
+	serviceName string
+
+	now  func() time.Time
+	cert atomic.Pointer[tls.Certificate]
+}
+
+// NewWebhookCertManager returns a new WebhookCertManager managing the given
+// Secret for the operator's webhook service in the given namespace.
+func NewWebhookCertManager(mgr manager.Manager, namespace, secretName string) *WebhookCertManager {
+	return newWebhookCertManager(mgr.GetClient(), namespace, secretName)
+}
+
+func newWebhookCertManager(c client.Client, namespace, secretName string) *WebhookCertManager {
+	return &WebhookCertManager{
+		client:      c,
+		namespace:   namespace,
+		secretName:  secretName,
+		serviceName: fmt.Sprintf("%s-operator", OperatorName),
+		now:         time.Now,
+	}
+}
+
+// AddToManager adds a new controller to the given manager which reconciles
+// the webhook certificate Secret.
+func (m *WebhookCertManager) AddToManager(mgr manager.Manager) error {
+	c, err := controller.New(webhookCertControllerName, mgr, controller.Options{
+		Reconciler:         m,
+		NeedLeaderElection: ptr.To(false),
+	})
+	if err != nil {
+		return err
+	}
+
+	// The Secret may not exist yet, so queue an initial request to have it
+	// created when the controller starts.
+	initial := make(chan event.GenericEvent, 1)
+	initial <- event.GenericEvent{Object: &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: m.secretName, Namespace: m.namespace},
+	}}
+
+	if err := c.Watch(source.Channel(initial, &handler.EnqueueRequestForObject{})); err != nil {
+		return err
+	}
+
+	return c.Watch(source.Kind(mgr.GetCache(), &corev1.Secret{},
+		&handler.TypedEnqueueRequestForObject[*corev1.Secret]{},
+		predicate.NewTypedPredicateFuncs(func(secret *corev1.Secret) bool {
+			return secret.GetNamespace() == m.namespace && secret.GetName() == m.secretName
+		})))
+}
+
+// GetCertificate returns the current serving certificate.  It is meant to be
+// used as the GetCertificate function of the webhook server's TLS config.
+func (m *WebhookCertManager) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cert := m.cert.Load()
+	if cert == nil {
+		return nil, errors.New("webhook serving certificate is not available yet")
+	}
+
+	return cert, nil
+}
+
+// TLSOpt returns an option setting the webhook server to serve the current
+// certificate, rather than loading it from disk.
+func (m *WebhookCertManager) TLSOpt() func(*tls.Config) {
+	return func(cfg *tls.Config) {
+		cfg.GetCertificate = m.GetCertificate
+	}
+}
+
+// Reconcile ensures the webhook certificate Secret holds a valid CA and
+// serving certificate, and loads the serving certificate.
+func (m *WebhookCertManager) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	secret := &corev1.Secret{}
+	key := client.ObjectKey{Namespace: m.namespace, Name: m.secretName}
+
+	if err := m.client.Get(ctx, key, secret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+
+		secret = nil
+	}
+
+	desired, expiry, err := m.ensureCertificates(secret)
+	if err != nil {
+		klog.Errorf("Error generating webhook certificates: %v", err)
+		return reconcile.Result{}, err
+	}
+
+	switch {
+	case secret == nil:
+		if err := m.client.Create(ctx, desired); err != nil {
+			return reconcile.Result{}, err
+		}
+
+		klog.Infof("Created webhook certificate secret %s/%s", m.namespace, m.secretName)
+
+	case !equalSecretData(secret.Data, desired.Data):
+		if err := m.client.Update(ctx, desired); err != nil {
+			return reconcile.Result{}, err
+		}
+
+		klog.Infof("Rotated webhook certificates in secret %s/%s", m.namespace, m.secretName)
+	}
+
+	cert, err := tls.X509KeyPair(desired.Data[corev1.TLSCertKey], desired.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("error loading webhook serving certificate: %v", err)
+	}
+
+	m.cert.Store(&cert)
+
+	requeue := expiry.Add(-webhookCertRefresh).Sub(m.now())
+	if requeue < minWebhookCertRequeue {
+		requeue = minWebhookCertRequeue
+	}
+
+	return reconcile.Result{RequeueAfter: requeue}, nil
+}
+
+// ensureCertificates returns the Secret holding valid certificates, based on
+// the given existing Secret, which may be nil.  Certificates which are
+// invalid or due for rotation are regenerated.  The earliest expiry of the
+// returned certificates is also returned.
+func (m *WebhookCertManager) ensureCertificates(existing *corev1.Secret) (*corev1.Secret, time.Time, error) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      m.secretName,
+			Namespace: m.namespace,
+			Labels: map[string]string{
+				"k8s-app": fmt.Sprintf("%s-operator", OperatorName),
+			},
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{},
+	}
+
+	if existing != nil {
+		secret = existing.DeepCopy()
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+	}
+
+	now := m.now()
+
+	ca, err := crypto.GetCAFromBytes(secret.Data[WebhookCACertKey], secret.Data[WebhookCAKeyKey])
+	if err != nil || len(ca.Config.Certs) == 0 || !m.fresh(ca.Config.Certs[0], now) {
+		ca, err = m.newCA(now)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+
+		caCert, caKey, err := ca.Config.GetPEMBytes()
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+
+		// Keep trusting the previous CA while its serving certificate may
+		// still be in use by other replicas.
+		bundle, err := m.caBundle(caCert, secret.Data[WebhookCACertKey], now)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+
+		secret.Data[WebhookCACertKey] = caCert
+		secret.Data[WebhookCAKeyKey] = caKey
+		secret.Data[WebhookCABundleKey] = bundle
+
+		// Force a new serving certificate signed by the new CA.
+		delete(secret.Data, corev1.TLSCertKey)
+		delete(secret.Data, corev1.TLSPrivateKeyKey)
+	}
+
+	if len(secret.Data[WebhookCABundleKey]) == 0 {
+		secret.Data[WebhookCABundleKey] = secret.Data[WebhookCACertKey]
+	}
+
+	expiry := ca.Config.Certs[0].NotAfter
+
+	serving, err := m.validServingCert(secret, ca, now)
+	if err != nil {
+		klog.V(2).Infof("Generating new webhook serving certificate: %v", err)
+
+		config, err := ca.MakeServerCertForDuration(sets.New(m.hostnames()...), webhookServingCertLifetime)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+
+		certPEM, keyPEM, err := config.GetPEMBytes()
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+
+		secret.Data[corev1.TLSCertKey] = certPEM
+		secret.Data[corev1.TLSPrivateKeyKey] = keyPEM
+		serving = config.Certs[0]
+	}
+
+	if serving.NotAfter.Before(expiry) {
+		expiry = serving.NotAfter
+	}
+
+	return secret, expiry, nil
+}
+
+// newCA returns a new self-signed CA.
+func (m *WebhookCertManager) newCA(now time.Time) (*crypto.CA, error) {
+	name := fmt.Sprintf("%s.%s.svc@%d", m.serviceName, m.namespace, now.Unix())
+
+	config, err := crypto.MakeSelfSignedCAConfigForDuration(name, webhookCALifetime)
+	if err != nil {
+		return nil, err
+	}
+
+	return &crypto.CA{
+		SerialGenerator: &crypto.RandomSerialGenerator{},
+		Config:          config,
+	}, nil
+}
+
+// caBundle returns a CA bundle containing the given current CA certificate,
+// followed by the unexpired certificates of the previous CA, if any.
+func (m *WebhookCertManager) caBundle(current, previous []byte, now time.Time) ([]byte, error) {
+	certs, err := crypto.CertsFromPEM(current)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(previous) > 0 {
+		// An unparseable previous CA is simply dropped.
+		if prev, err := crypto.CertsFromPEM(previous); err == nil {
+			for _, cert := range prev {
+				if now.Before(cert.NotAfter) {
+					certs = append(certs, cert)
+				}
+			}
+		}
+	}
+
+	return crypto.EncodeCertificates(certs...)
+}
+
+// validServingCert returns the serving certificate from the given Secret, or
+// an error describing why it can not be used.
+func (m *WebhookCertManager) validServingCert(secret *corev1.Secret, ca *crypto.CA, now time.Time) (*x509.Certificate, error) {
+	pair, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Config.Certs[0])
+
+	for _, host := range m.hostnames() {
+		opts := x509.VerifyOptions{
+			DNSName:     host,
+			Roots:       roots,
+			CurrentTime: now,
+		}
+
+		if _, err := cert.Verify(opts); err != nil {
+			return nil, err
+		}
+	}
+
+	if !m.fresh(cert, now) {
+		return nil, fmt.Errorf("certificate expires at %s", cert.NotAfter)
+	}
+
+	return cert, nil
+}
+
+// fresh returns true if the given certificate is valid for longer than the
+// refresh period.
+func (m *WebhookCertManager) fresh(cert *x509.Certificate, now time.Time) bool {
+	return now.Add(webhookCertRefresh).Before(cert.NotAfter)
+}
+
+// hostnames returns the DNS names of the operator's webhook service.
+func (m *WebhookCertManager) hostnames() []string {
+	return []string{
+		m.serviceName,
+		fmt.Sprintf("%s.%s", m.serviceName, m.namespace),
+		fmt.Sprintf("%s.%s.svc", m.serviceName, m.namespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", m.serviceName, m.namespace),
+	}
+}
+
+// equalSecretData returns true if the given Secret data is the same.
+func equalSecretData(a, b map[string][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for k, v := range a {
+		if w, ok := b[k]; !ok || !bytes.Equal(v, w) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,251 @@
+package operator
+
+import (
+	"context"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/openshift/library-go/pkg/crypto"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const testCertSecretName = "test-webhook-cert"
+
+func reconcileWebhookCerts(t *testing.T, m *WebhookCertManager) *corev1.Secret {
+	t.Helper()
+
+	res, err := m.Reconcile(context.TODO(), reconcile.Request{})
+	if err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+
+	if res.RequeueAfter < minWebhookCertRequeue {
+		t.Errorf("expected requeue after at least %s, got %s", minWebhookCertRequeue, res.RequeueAfter)
+	}
+
+	secret := &corev1.Secret{}
+	key := client.ObjectKey{Namespace: "test", Name: testCertSecretName}
+
+	if err := m.client.Get(context.TODO(), key, secret); err != nil {
+		t.Fatalf("failed to get certificate secret: %v", err)
+	}
+
+	return secret
+}
+
+// verifyServingCert checks the serving certificate loaded by the manager is
+// trusted by the CA bundle in the given Secret.
+func verifyServingCert(t *testing.T, m *WebhookCertManager, secret *corev1.Secret) *x509.Certificate {
+	t.Helper()
+
+	tlsCert, err := m.GetCertificate(nil)
+	if err != nil {
+		t.Fatalf("failed to get serving certificate: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(tlsCert.Certificate[0])
+	if err != nil {
+		t.Fatalf("failed to parse serving certificate: %v", err)
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(secret.Data[WebhookCABundleKey]) {
+		t.Fatalf("failed to parse CA bundle")
+	}
+
+	opts := x509.VerifyOptions{
+		DNSName: "cluster-autoscaler-operator.test.svc",
+		Roots:   roots,
+	}
+
+	if _, err := cert.Verify(opts); err != nil {
+		t.Errorf("serving certificate not trusted by CA bundle: %v", err)
+	}
+
+	return cert
+}
+
+func TestWebhookCertManagerReconcile(t *testing.T) {
+	c := fakeclient.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	m := newWebhookCertManager(c, "test", testCertSecretName)
+
+	if _, err := m.GetCertificate(nil); err == nil {
+		t.Errorf("expected an error before certificates are loaded")
+	}
+
+	secret := reconcileWebhookCerts(t, m)
+
+	for _, key := range []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey, WebhookCACertKey, WebhookCAKeyKey, WebhookCABundleKey} {
+		if len(secret.Data[key]) == 0 {
+			t.Errorf("expected %s in certificate secret", key)
+		}
+	}
+
+	initial := verifyServingCert(t, m, secret)
+
+	// Certificates are left alone while they are valid.
+	updated := reconcileWebhookCerts(t, m)
+	if updated.ResourceVersion != secret.ResourceVersion {
+		t.Errorf("expected valid certificates to not be updated")
+	}
+
+	// The serving certificate is rotated before it expires, keeping the CA.
+	m.now = func() time.Time { return time.Now().Add(webhookServingCertLifetime - webhookCertRefresh/2) }
+
+	rotated := reconcileWebhookCerts(t, m)
+	if string(rotated.Data[corev1.TLSCertKey]) == string(secret.Data[corev1.TLSCertKey]) {
+		t.Errorf("expected serving certificate to be rotated")
+	}
+
+	if string(rotated.Data[WebhookCACertKey]) != string(secret.Data[WebhookCACertKey]) {
+		t.Errorf("expected CA to be kept")
+	}
+
+	if cert := verifyServingCert(t, m, rotated); cert.SerialNumber.Cmp(initial.SerialNumber) == 0 {
+		t.Errorf("expected the rotated serving certificate to be loaded")
+	}
+
+	// The CA is rotated before it expires, and the previous one kept in the
+	// bundle.
+	m.now = func() time.Time { return time.Now().Add(webhookCALifetime - webhookCertRefresh/2) }
+
+	rotated = reconcileWebhookCerts(t, m)
+	if string(rotated.Data[WebhookCACertKey]) == string(secret.Data[WebhookCACertKey]) {
+		t.Errorf("expected CA to be rotated")
+	}
+
+	bundle, err := crypto.CertsFromPEM(rotated.Data[WebhookCABundleKey])
+	if err != nil {
+		t.Fatalf("failed to parse CA bundle: %v", err)
+	}
+
+	if len(bundle) != 2 {
+		t.Errorf("expected the CA bundle to contain 2 certificates, got %d", len(bundle))
+	}
+
+	verifyServingCert(t, m, rotated)
+}
+
+func TestWebhookCertManagerReconcileInvalid(t *testing.T) {
+	secret := &corev1.Secret{}
+	secret.Name = testCertSecretName
+	secret.Namespace = "test"
+	secret.Data = map[string][]byte{
+		corev1.TLSCertKey:       []byte("invalid"),
+		corev1.TLSPrivateKeyKey: []byte("invalid"),
+	}
+
+	c := fakeclient.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build()
+	m := newWebhookCertManager(c, "test", testCertSecretName)
+
+	verifyServingCert(t, m, reconcileWebhookCerts(t, m))
+}
+
+// newConversionCRD returns a CRD with the given name using webhook conversion.
+func newConversionCRD(name string) *apiextensionsv1.CustomResourceDefinition {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	crd.Name = name
+	crd.Spec.Conversion = &apiextensionsv1.CustomResourceConversion{
+		Strategy: apiextensionsv1.WebhookConverter,
+		Webhook: &apiextensionsv1.WebhookConversion{
+			ConversionReviewVersions: []string{"v1"},
+		},
+	}
+
+	return crd
+}
+
+// verifyConversionCABundle verifies the CA bundle of the conversion webhook
+// of the CRD with the given name.
+func verifyConversionCABundle(t *testing.T, c client.Client, name, expected string) {
+	t.Helper()
+
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Name: name}, crd); err != nil {
+		t.Fatalf("failed to get CRD %s: %v", name, err)
+	}
+
+	if caBundle := crd.Spec.Conversion.Webhook.ClientConfig.CABundle; string(caBundle) != expected {
+		t.Errorf("CRD %s: unexpected conversion webhook CA bundle %q", name, caBundle)
+	}
+}
+
+func TestWebhookConfigReconcileSelfManagedCABundle(t *testing.T) {
+	secret := &corev1.Secret{}
+	secret.Name = testCertSecretName
+	secret.Namespace = "test"
+	secret.Data = map[string][]byte{
+		WebhookCABundleKey: []byte("ca-bundle"),
+	}
+
+	cacrd := newConversionCRD(conversionCRDNames[0])
+	macrd := newConversionCRD(conversionCRDNames[1])
+
+	w := newTestWebhookConfigUpdater(t, WebhookConfig{
+		Namespace:          "test",
+		CABundleSecretName: testCertSecretName,
+	}, secret, cacrd, macrd)
+
+	if _, err := w.Reconcile(context.TODO(), webhookConfigRequest); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+
+	vc, mc := getWebhookConfigs(t, w.client)
+
+	for _, wh := range vc.Webhooks {
+		if string(wh.ClientConfig.CABundle) != "ca-bundle" {
+			t.Errorf("webhook %s: unexpected CA bundle %q", wh.Name, wh.ClientConfig.CABundle)
+		}
+	}
+
+	for _, wh := range mc.Webhooks {
+		if string(wh.ClientConfig.CABundle) != "ca-bundle" {
+			t.Errorf("webhook %s: unexpected CA bundle %q", wh.Name, wh.ClientConfig.CABundle)
+		}
+	}
+
+	if _, ok := vc.Annotations[InjectCABundleAnnotationName]; ok {
+		t.Errorf("unexpected %s annotation with self-managed certificates", InjectCABundleAnnotationName)
+	}
+
+	for _, name := range conversionCRDNames {
+		verifyConversionCABundle(t, w.client, name, "ca-bundle")
+	}
+}
+
+func TestWebhookConfigReconcileAdmissionDisabled(t *testing.T) {
+	secret := &corev1.Secret{}
+	secret.Name = testCertSecretName
+	secret.Namespace = "test"
+	secret.Data = map[string][]byte{
+		WebhookCABundleKey: []byte("ca-bundle"),
+	}
+
+	// Missing CRDs are skipped.
+	crd := newConversionCRD(conversionCRDNames[1])
+
+	w := newTestWebhookConfigUpdater(t, WebhookConfig{
+		Namespace:          "test",
+		CABundleSecretName: testCertSecretName,
+		AdmissionDisabled:  true,
+	}, secret, crd)
+
+	if _, err := w.Reconcile(context.TODO(), webhookConfigRequest); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+
+	key := client.ObjectKey{Name: WebhookConfigurationName}
+	if err := w.client.Get(context.TODO(), key, &admissionregistrationv1.ValidatingWebhookConfiguration{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected no validating webhook configuration, got: %v", err)
+	}
+
+	verifyConversionCABundle(t, w.client, crd.Name, "ca-bundle")
+}
@@ -2,15 +2,26 @@ package operator
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 // WebhookConfigurationName is the name of the webhook configuration.
@@ -20,67 +31,390 @@ const WebhookConfigurationName = "autoscaling.openshift.io"
 // service-ca-operator to indicate which resources it should inject the CA into.
 const InjectCABundleAnnotationName = "service.beta.openshift.io/inject-cabundle"
 
+// webhookConfigControllerName is the name of the controller reconciling the
+// webhook configurations.
+const webhookConfigControllerName = "webhook_config_controller"
+
+// conversionCRDNames are the names of the CRDs whose versions are converted
+// by the operator's conversion webhook.
+var conversionCRDNames = []string{
+	"clusterautoscalers.autoscaling.openshift.io",
+	"machineautoscalers.autoscaling.openshift.io",
+}
+
+// WebhookConfig represents the configuration of the admission webhooks
+// registered with the API server.
+type WebhookConfig struct {
+	// Namespace is the namespace of the operator's webhook service.
+	Namespace string
+
+	// FailurePolicy is the failure policy of the webhooks, either "Ignore"
+	// or "Fail".  Defaults to "Ignore" when empty.
+	FailurePolicy string
+
+	// TimeoutSeconds is the number of seconds the API server waits for the
+	// webhooks to respond.  Defaults to 10 when zero.
+	TimeoutSeconds int32
+
+	// NamespaceSelector and ObjectSelector are label selectors in the format
+	// accepted by kubectl, limiting the requests sent to the webhooks.
+	NamespaceSelector string
+	ObjectSelector    string
+
+	// CABundleSecretName is the name of a Secret in Namespace holding the
+	// CA bundle of self-managed webhook certificates.  When set, the CA
+	// bundle is taken from it rather than injected by the
+	// service-ca-operator.
+	CABundleSecretName string
+
+	// AdmissionDisabled disables the registration of the admission
+	// webhooks, leaving only the CA bundle of the conversion webhook to be
+	// managed.
+	AdmissionDisabled bool
+}
+
 // WebhookConfigUpdater updates webhook configurations to point the Kubernetes
 // API server at the operator's validating or mutating webhook server.  It would
 // be nice if the CVO could apply the configuration as it is mostly static.
 // Unfortunately, the service-ca-operator needs to be able to inject the CA
 // certificate bundle, which the CVO would overwrite.
+//
+// The webhook configurations are watched, and re-applied whenever they are
+// deleted or drift from the expected configuration, while preserving the
+// injected CA bundle.  With self-managed certificates, the CA bundle is set
+// from the certificate Secret instead, including that of the conversion
+// webhook of the MachineAutoscaler CRD.
 type WebhookConfigUpdater struct {
-	namespace string
-	client    client.Client
+	namespace         string
+	client            client.Client
+	failurePolicy     admissionregistrationv1.FailurePolicyType
+	timeoutSeconds    int32
+	namespaceSelector *metav1.LabelSelector
+	objectSelector    *metav1.LabelSelector
+	caBundleSecret    string
+	admissionDisabled bool
 }
 
 // NewWebhookConfigUpdater returns a new WebhookConfigUpdater instance.
-func NewWebhookConfigUpdater(mgr manager.Manager, namespace string) (*WebhookConfigUpdater, error) {
+func NewWebhookConfigUpdater(mgr manager.Manager, cfg WebhookConfig) (*WebhookConfigUpdater, error) {
+	return newWebhookConfigUpdater(mgr.GetClient(), cfg)
+}
+
+func newWebhookConfigUpdater(c client.Client, cfg WebhookConfig) (*WebhookConfigUpdater, error) {
 	w := &WebhookConfigUpdater{
-		namespace: namespace,
-		client:    mgr.GetClient(),
+		namespace:         cfg.Namespace,
+		client:            c,
+		failurePolicy:     admissionregistrationv1.Ignore,
+		timeoutSeconds:    DefaultWebhooksTimeoutSeconds,
+		caBundleSecret:    cfg.CABundleSecretName,
+		admissionDisabled: cfg.AdmissionDisabled,
+	}
+
+	if cfg.FailurePolicy != "" {
+		w.failurePolicy = admissionregistrationv1.FailurePolicyType(cfg.FailurePolicy)
+	}
+
+	if cfg.TimeoutSeconds != 0 {
+		w.timeoutSeconds = cfg.TimeoutSeconds
+	}
+
+	var err error
+
+	if w.namespaceSelector, err = parseWebhookSelector(cfg.NamespaceSelector); err != nil {
+		return nil, fmt.Errorf("invalid webhook namespace selector: %v", err)
+	}
+
+	if w.objectSelector, err = parseWebhookSelector(cfg.ObjectSelector); err != nil {
+		return nil, fmt.Errorf("invalid webhook object selector: %v", err)
 	}
 
 	return w, nil
 }
 
-// Start creates or updates the webhook configurations then waits for the stop
-// channel to be closed.
-func (w *WebhookConfigUpdater) Start(stop context.Context) error {
+// parseWebhookSelector parses a label selector string.  An empty string
+// results in an empty selector matching everything, which is also what the
+// API server defaults an unset selector to.
+func parseWebhookSelector(selector string) (*metav1.LabelSelector, error) {
+	if selector == "" {
+		return &metav1.LabelSelector{}, nil
+	}
+
+	return metav1.ParseToLabelSelector(selector)
+}
+
+// AddToManager adds a new controller to the given manager which reconciles
+// the webhook configurations.  As with other controllers, it only runs once
+// this instance becomes the leader.
+func (w *WebhookConfigUpdater) AddToManager(mgr manager.Manager) error {
+	c, err := controller.New(webhookConfigControllerName, mgr, controller.Options{Reconciler: w})
+	if err != nil {
+		return err
+	}
+
+	// Neither configuration may exist yet, so queue an initial request to
+	// have them created when the controller starts.
+	initial := make(chan event.GenericEvent, 1)
+	initial <- event.GenericEvent{Object: &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: WebhookConfigurationName},
+	}}
+
+	if err := c.Watch(source.Channel(initial, &handler.EnqueueRequestForObject{})); err != nil {
+		return err
+	}
+
+	if err := c.Watch(source.Kind(mgr.GetCache(), &admissionregistrationv1.ValidatingWebhookConfiguration{},
+		&handler.TypedEnqueueRequestForObject[*admissionregistrationv1.ValidatingWebhookConfiguration]{},
+		webhookConfigNamePredicate[*admissionregistrationv1.ValidatingWebhookConfiguration]())); err != nil {
+		return err
+	}
+
+	if err := c.Watch(source.Kind(mgr.GetCache(), &admissionregistrationv1.MutatingWebhookConfiguration{},
+		&handler.TypedEnqueueRequestForObject[*admissionregistrationv1.MutatingWebhookConfiguration]{},
+		webhookConfigNamePredicate[*admissionregistrationv1.MutatingWebhookConfiguration]())); err != nil {
+		return err
+	}
+
+	if w.caBundleSecret == "" {
+		return nil
+	}
+
+	// Update the CA bundle when the self-managed certificates are rotated.
+	return c.Watch(source.Kind(mgr.GetCache(), &corev1.Secret{},
+		handler.TypedEnqueueRequestsFromMapFunc[*corev1.Secret](w.caBundleSecretRequest)))
+}
+
+// caBundleSecretRequest maps the CA bundle Secret to a request for the
+// webhook configurations.
+func (w *WebhookConfigUpdater) caBundleSecretRequest(ctx context.Context, secret *corev1.Secret) []reconcile.Request {
+	if secret.GetNamespace() != w.namespace || secret.GetName() != w.caBundleSecret {
+		return nil
+	}
+
+	return []reconcile.Request{{
+		NamespacedName: types.NamespacedName{Name: WebhookConfigurationName},
+	}}
+}
+
+// getCABundle returns the CA bundle of the self-managed certificates, or nil
+// if they are not used or have not been generated yet.
+func (w *WebhookConfigUpdater) getCABundle(ctx context.Context) ([]byte, error) {
+	if w.caBundleSecret == "" {
+		return nil, nil
+	}
+
+	secret := &corev1.Secret{}
+	key := client.ObjectKey{Namespace: w.namespace, Name: w.caBundleSecret}
+
+	if err := w.client.Get(ctx, key, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return secret.Data[WebhookCABundleKey], nil
+}
+
+// webhookConfigNamePredicate returns a predicate filtering events for
+// webhook configurations not managed by the operator.
+func webhookConfigNamePredicate[T client.Object]() predicate.TypedPredicate[T] {
+	return predicate.NewTypedPredicateFuncs(func(obj T) bool {
+		return obj.GetName() == WebhookConfigurationName
+	})
+}
+
+// Reconcile creates or updates the webhook configurations.  Both the
+// validating and mutating configurations share the same name, so every
+// request reconciles both of them.
+func (w *WebhookConfigUpdater) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	caBundle, err := w.getCABundle(ctx)
+	if err != nil {
+		klog.Errorf("Error getting webhook CA bundle: %v", err)
+		return reconcile.Result{}, err
+	}
+
+	if !w.admissionDisabled {
+		if err := w.reconcileAdmissionWebhooks(ctx, caBundle); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	if caBundle != nil {
+		if err := w.updateConversionCABundle(ctx, caBundle); err != nil {
+			klog.Errorf("Error updating conversion webhook CA bundle: %v", err)
+			return reconcile.Result{}, err
+		}
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// reconcileAdmissionWebhooks creates or updates the validating and mutating
+// webhook configurations, with the given CA bundle if not nil.
+func (w *WebhookConfigUpdater) reconcileAdmissionWebhooks(ctx context.Context, caBundle []byte) error {
 	vc := &admissionregistrationv1.ValidatingWebhookConfiguration{
-		TypeMeta: metav1.TypeMeta{
-			APIVersion: "admissionregistration.k8s.io/v1",
-			Kind:       "ValidatingWebhookConfiguration",
-		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name: WebhookConfigurationName,
-			Labels: map[string]string{
-				"k8s-app": fmt.Sprintf("%s-operator", OperatorName),
-			},
-			Annotations: map[string]string{
-				InjectCABundleAnnotationName: "true",
-			},
 		},
 	}
 
-	op, err := controllerutil.CreateOrUpdate(context.TODO(), w.client, vc, func() error {
-		var err error
-		vc.Webhooks, err = w.ValidatingWebhooks()
+	op, err := controllerutil.CreateOrUpdate(ctx, w.client, vc, func() error {
+		w.setMetadata(&vc.ObjectMeta)
+
+		webhooks, err := w.ValidatingWebhooks()
+		if err != nil {
+			return err
+		}
+
+		setWebhookCABundles(vc.Webhooks, webhooks, validatingWebhookClientConfig, caBundle)
+		vc.Webhooks = webhooks
+
+		return nil
+	})
+
+	if err != nil {
+		klog.Errorf("Error reconciling validating webhook configuration: %v", err)
 		return err
+	}
+
+	if op != controllerutil.OperationResultNone {
+		klog.Infof("Webhook configuration status: %s", op)
+	}
+
+	mc := &admissionregistrationv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: WebhookConfigurationName,
+		},
+	}
+
+	op, err = controllerutil.CreateOrUpdate(ctx, w.client, mc, func() error {
+		w.setMetadata(&mc.ObjectMeta)
+
+		webhooks, err := w.MutatingWebhooks()
+		if err != nil {
+			return err
+		}
+
+		setWebhookCABundles(mc.Webhooks, webhooks, mutatingWebhookClientConfig, caBundle)
+		mc.Webhooks = webhooks
+
+		return nil
 	})
 
 	if err != nil {
+		klog.Errorf("Error reconciling mutating webhook configuration: %v", err)
 		return err
 	}
 
-	klog.Infof("Webhook configuration status: %s", op)
+	if op != controllerutil.OperationResultNone {
+		klog.Infof("Mutating webhook configuration status: %s", op)
+	}
+
+	return nil
+}
+
+// setWebhookCABundles sets the CA bundle of the desired webhooks to the given
+// CA bundle, or if nil, keeps the CA bundle injected by the service-ca-operator
+// into the current webhook of the same name.  The name and client
+// configuration of a webhook are returned by the given accessor.
+func setWebhookCABundles[T any](current, desired []T, clientConfig func(*T) (string, *admissionregistrationv1.WebhookClientConfig), caBundle []byte) {
+	caBundles := map[string][]byte{}
+	for i := range current {
+		name, config := clientConfig(&current[i])
+		caBundles[name] = config.CABundle
+	}
+
+	for i := range desired {
+		name, config := clientConfig(&desired[i])
+		config.CABundle = caBundles[name]
+		if caBundle != nil {
+			config.CABundle = caBundle
+		}
+	}
+}
+
+// validatingWebhookClientConfig returns the name and client configuration of
+// the given validating webhook.
+func validatingWebhookClientConfig(wh *admissionregistrationv1.ValidatingWebhook) (string, *admissionregistrationv1.WebhookClientConfig) {
+	return wh.Name, &wh.ClientConfig
+}
+
+// mutatingWebhookClientConfig returns the name and client configuration of
+// the given mutating webhook.
+func mutatingWebhookClientConfig(wh *admissionregistrationv1.MutatingWebhook) (string, *admissionregistrationv1.WebhookClientConfig) {
+	return wh.Name, &wh.ClientConfig
+}
+
+// updateConversionCABundle sets the CA bundle of the conversion webhook of
+// the ClusterAutoscaler and MachineAutoscaler CRDs, which is otherwise
+// injected by the service-ca-operator.  The CRDs are patched rather than
+// fetched, so as not to cache all CRDs in the cluster.
+func (w *WebhookConfigUpdater) updateConversionCABundle(ctx context.Context, caBundle []byte) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"conversion": map[string]interface{}{
+				"webhook": map[string]interface{}{
+					"clientConfig": map[string]interface{}{
+						"caBundle": caBundle,
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
 
-	// Block until the stop channel is closed.
-	<-stop.Done()
+	for _, name := range conversionCRDNames {
+		crd := &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+		}
+
+		if err := w.client.Patch(ctx, crd, client.RawPatch(types.MergePatchType, patch)); err != nil {
+			if apierrors.IsNotFound(err) {
+				klog.Warningf("CRD %s not found, not updating conversion webhook CA bundle", name)
+				continue
+			}
+
+			return err
+		}
+	}
 
 	return nil
 }
 
+// setMetadata sets the labels and annotations expected on the webhook
+// configurations, leaving any others in place.
+func (w *WebhookConfigUpdater) setMetadata(meta *metav1.ObjectMeta) {
+	if meta.Labels == nil {
+		meta.Labels = map[string]string{}
+	}
+
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+
+	meta.Labels["k8s-app"] = fmt.Sprintf("%s-operator", OperatorName)
+
+	// The service-ca-operator would overwrite a self-managed CA bundle.
+	if w.caBundleSecret != "" {
+		delete(meta.Annotations, InjectCABundleAnnotationName)
+	} else {
+		meta.Annotations[InjectCABundleAnnotationName] = "true"
+	}
+}
+
 // ValidatingWebhooks returns the validating webhook configurations.
 func (w *WebhookConfigUpdater) ValidatingWebhooks() ([]admissionregistrationv1.ValidatingWebhook, error) {
-	failurePolicy := admissionregistrationv1.Ignore
+	// Fields the API server would otherwise default are set explicitly, so
+	// the configuration does not appear to drift after being applied.
+	failurePolicy := w.failurePolicy
+	matchPolicy := admissionregistrationv1.Equivalent
 	sideEffects := admissionregistrationv1.SideEffectClassNone
+	scope := admissionregistrationv1.AllScopes
 
 	webhooks := []admissionregistrationv1.ValidatingWebhook{
 		{
@@ -90,17 +424,23 @@ func (w *WebhookConfigUpdater) ValidatingWebhooks() ([]admissionregistrationv1.V
 				Service: &admissionregistrationv1.ServiceReference{
 					Name:      fmt.Sprintf("%s-operator", OperatorName),
 					Namespace: w.namespace,
+					Port:      pointer.Int32(443),
 					Path:      pointer.StringPtr("/validate-clusterautoscalers"),
 				},
 			},
-			FailurePolicy: &failurePolicy,
-			SideEffects:   &sideEffects,
+			FailurePolicy:     &failurePolicy,
+			MatchPolicy:       &matchPolicy,
+			SideEffects:       &sideEffects,
+			TimeoutSeconds:    pointer.Int32(w.timeoutSeconds),
+			NamespaceSelector: w.namespaceSelector.DeepCopy(),
+			ObjectSelector:    w.objectSelector.DeepCopy(),
 			Rules: []admissionregistrationv1.RuleWithOperations{
 				{
 					Rule: admissionregistrationv1.Rule{
 						APIGroups:   []string{"autoscaling.openshift.io"},
 						APIVersions: []string{"v1"},
 						Resources:   []string{"clusterautoscalers"},
+						Scope:       &scope,
 					},
 					Operations: []admissionregistrationv1.OperationType{
 						admissionregistrationv1.Create,
@@ -116,17 +456,105 @@ func (w *WebhookConfigUpdater) ValidatingWebhooks() ([]admissionregistrationv1.V
 				Service: &admissionregistrationv1.ServiceReference{
 					Name:      fmt.Sprintf("%s-operator", OperatorName),
 					Namespace: w.namespace,
+					Port:      pointer.Int32(443),
 					Path:      pointer.StringPtr("/validate-machineautoscalers"),
 				},
 			},
-			FailurePolicy: &failurePolicy,
-			SideEffects:   &sideEffects,
+			FailurePolicy:     &failurePolicy,
+			MatchPolicy:       &matchPolicy,
+			SideEffects:       &sideEffects,
+			TimeoutSeconds:    pointer.Int32(w.timeoutSeconds),
+			NamespaceSelector: w.namespaceSelector.DeepCopy(),
+			ObjectSelector:    w.objectSelector.DeepCopy(),
+			Rules: []admissionregistrationv1.RuleWithOperations{
+				{
+					Rule: admissionregistrationv1.Rule{
+						APIGroups:   []string{"autoscaling.openshift.io"},
+						APIVersions: []string{"v1"},
+						Resources:   []string{"machineautoscalers"},
+						Scope:       &scope,
+					},
+					Operations: []admissionregistrationv1.OperationType{
+						admissionregistrationv1.Create,
+						admissionregistrationv1.Update,
+					},
+				},
+			},
+		},
+	}
+
+	return webhooks, nil
+}
+
+// MutatingWebhooks returns the mutating webhook configurations.
+func (w *WebhookConfigUpdater) MutatingWebhooks() ([]admissionregistrationv1.MutatingWebhook, error) {
+	// Fields the API server would otherwise default are set explicitly, so
+	// the configuration does not appear to drift after being applied.
+	failurePolicy := w.failurePolicy
+	matchPolicy := admissionregistrationv1.Equivalent
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	scope := admissionregistrationv1.AllScopes
+	reinvocationPolicy := admissionregistrationv1.NeverReinvocationPolicy
+
+	webhooks := []admissionregistrationv1.MutatingWebhook{
+		{
+			Name:                    "clusterautoscalers.autoscaling.openshift.io",
+			AdmissionReviewVersions: []string{"v1"},
+			ClientConfig: admissionregistrationv1.WebhookClientConfig{
+				Service: &admissionregistrationv1.ServiceReference{
+					Name:      fmt.Sprintf("%s-operator", OperatorName),
+					Namespace: w.namespace,
+					Port:      pointer.Int32(443),
+					Path:      pointer.StringPtr("/mutate-clusterautoscalers"),
+				},
+			},
+			FailurePolicy:      &failurePolicy,
+			MatchPolicy:        &matchPolicy,
+			SideEffects:        &sideEffects,
+			TimeoutSeconds:     pointer.Int32(w.timeoutSeconds),
+			NamespaceSelector:  w.namespaceSelector.DeepCopy(),
+			ObjectSelector:     w.objectSelector.DeepCopy(),
+			ReinvocationPolicy: &reinvocationPolicy,
+			Rules: []admissionregistrationv1.RuleWithOperations{
+				{
+					Rule: admissionregistrationv1.Rule{
+						APIGroups:   []string{"autoscaling.openshift.io"},
+						APIVersions: []string{"v1"},
+						Resources:   []string{"clusterautoscalers"},
+						Scope:       &scope,
+					},
+					Operations: []admissionregistrationv1.OperationType{
+						admissionregistrationv1.Create,
+						admissionregistrationv1.Update,
+					},
+				},
+			},
+		},
+		{
+			Name:                    "machineautoscalers.autoscaling.openshift.io",
+			AdmissionReviewVersions: []string{"v1"},
+			ClientConfig: admissionregistrationv1.WebhookClientConfig{
+				Service: &admissionregistrationv1.ServiceReference{
+					Name:      fmt.Sprintf("%s-operator", OperatorName),
+					Namespace: w.namespace,
+					Port:      pointer.Int32(443),
+					Path:      pointer.StringPtr("/mutate-machineautoscalers"),
+				},
+			},
+			FailurePolicy:      &failurePolicy,
+			MatchPolicy:        &matchPolicy,
+			SideEffects:        &sideEffects,
+			TimeoutSeconds:     pointer.Int32(w.timeoutSeconds),
+			NamespaceSelector:  w.namespaceSelector.DeepCopy(),
+			ObjectSelector:     w.objectSelector.DeepCopy(),
+			ReinvocationPolicy: &reinvocationPolicy,
 			Rules: []admissionregistrationv1.RuleWithOperations{
 				{
 					Rule: admissionregistrationv1.Rule{
 						APIGroups:   []string{"autoscaling.openshift.io"},
-						APIVersions: []string{"v1beta1"},
+						APIVersions: []string{"v1"},
 						Resources:   []string{"machineautoscalers"},
+						Scope:       &scope,
 					},
 					Operations: []admissionregistrationv1.OperationType{
 						admissionregistrationv1.Create,
@@ -0,0 +1,197 @@
+package operator
+
+import (
+	"context"
+	"testing"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var webhookConfigRequest = reconcile.Request{
+	NamespacedName: types.NamespacedName{Name: WebhookConfigurationName},
+}
+
+func newTestWebhookConfigUpdater(t *testing.T, cfg WebhookConfig, objs ...client.Object) *WebhookConfigUpdater {
+	t.Helper()
+
+	// The CRDs of the conversion webhook are only patched if the install
+	// manifests allow it.
+	c := newRBACEnforcingClient(t, objs...)
+
+	w, err := newWebhookConfigUpdater(c, cfg)
+	if err != nil {
+		t.Fatalf("failed to create webhook config updater: %v", err)
+	}
+
+	return w
+}
+
+func getWebhookConfigs(t *testing.T, c client.Client) (*admissionregistrationv1.ValidatingWebhookConfiguration, *admissionregistrationv1.MutatingWebhookConfiguration) {
+	t.Helper()
+
+	key := client.ObjectKey{Name: WebhookConfigurationName}
+
+	vc := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+	if err := c.Get(context.TODO(), key, vc); err != nil {
+		t.Fatalf("failed to get validating webhook configuration: %v", err)
+	}
+
+	mc := &admissionregistrationv1.MutatingWebhookConfiguration{}
+	if err := c.Get(context.TODO(), key, mc); err != nil {
+		t.Fatalf("failed to get mutating webhook configuration: %v", err)
+	}
+
+	return vc, mc
+}
+
+func TestNewWebhookConfigUpdater(t *testing.T) {
+	testCases := []struct {
+		label         string
+		cfg           WebhookConfig
+		expectedError bool
+	}{
+		{
+			label: "defaults",
+			cfg:   WebhookConfig{Namespace: "test"},
+		},
+		{
+			label: "valid selectors",
+			cfg: WebhookConfig{
+				Namespace:         "test",
+				NamespaceSelector: "kubernetes.io/metadata.name in (a, b)",
+				ObjectSelector:    "app=test",
+			},
+		},
+		{
+			label:         "invalid namespace selector",
+			cfg:           WebhookConfig{NamespaceSelector: "a in b"},
+			expectedError: true,
+		},
+		{
+			label:         "invalid object selector",
+			cfg:           WebhookConfig{ObjectSelector: "!!"},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			_, err := newWebhookConfigUpdater(nil, tc.cfg)
+			if (err != nil) != tc.expectedError {
+				t.Errorf("expected error: %v, got: %v", tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestWebhookConfigReconcile(t *testing.T) {
+	w := newTestWebhookConfigUpdater(t, WebhookConfig{
+		Namespace:      "test",
+		FailurePolicy:  "Fail",
+		TimeoutSeconds: 5,
+		ObjectSelector: "app=test",
+	})
+
+	if _, err := w.Reconcile(context.TODO(), webhookConfigRequest); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+
+	vc, mc := getWebhookConfigs(t, w.client)
+
+	if len(vc.Webhooks) != 2 || len(mc.Webhooks) != 2 {
+		t.Fatalf("expected 2 webhooks of each type, got %d validating and %d mutating",
+			len(vc.Webhooks), len(mc.Webhooks))
+	}
+
+	for _, wh := range vc.Webhooks {
+		if *wh.FailurePolicy != admissionregistrationv1.Fail {
+			t.Errorf("webhook %s: expected failure policy Fail, got %s", wh.Name, *wh.FailurePolicy)
+		}
+
+		if *wh.TimeoutSeconds != 5 {
+			t.Errorf("webhook %s: expected timeout 5, got %d", wh.Name, *wh.TimeoutSeconds)
+		}
+
+		if wh.ObjectSelector.MatchLabels["app"] != "test" {
+			t.Errorf("webhook %s: unexpected object selector: %v", wh.Name, wh.ObjectSelector)
+		}
+
+		if wh.ClientConfig.Service.Namespace != "test" {
+			t.Errorf("webhook %s: unexpected service namespace: %s", wh.Name, wh.ClientConfig.Service.Namespace)
+		}
+	}
+
+	if vc.Annotations[InjectCABundleAnnotationName] != "true" {
+		t.Errorf("missing %s annotation on validating webhook configuration", InjectCABundleAnnotationName)
+	}
+
+	// Reconciling again without any changes should not update the objects.
+	resourceVersion := vc.ResourceVersion
+
+	if _, err := w.Reconcile(context.TODO(), webhookConfigRequest); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+
+	vc, _ = getWebhookConfigs(t, w.client)
+
+	if vc.ResourceVersion != resourceVersion {
+		t.Errorf("expected unchanged configuration to not be updated")
+	}
+}
+
+func TestWebhookConfigReconcileDrift(t *testing.T) {
+	caBundle := []byte("ca-bundle")
+
+	w := newTestWebhookConfigUpdater(t, WebhookConfig{Namespace: "test"})
+
+	webhooks, err := w.ValidatingWebhooks()
+	if err != nil {
+		t.Fatalf("failed to get validating webhooks: %v", err)
+	}
+
+	// Drop one of the webhooks, loosen the other, and remove the annotation
+	// used by the service-ca-operator, keeping an injected CA bundle.
+	drifted := webhooks[0]
+	drifted.ClientConfig.CABundle = caBundle
+	drifted.ClientConfig.Service.Path = nil
+
+	vc := &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   WebhookConfigurationName,
+			Labels: map[string]string{"other": "label"},
+		},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{drifted},
+	}
+
+	w = newTestWebhookConfigUpdater(t, WebhookConfig{Namespace: "test"}, vc)
+
+	if _, err := w.Reconcile(context.TODO(), webhookConfigRequest); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+
+	vc, _ = getWebhookConfigs(t, w.client)
+
+	if len(vc.Webhooks) != len(webhooks) {
+		t.Fatalf("expected %d webhooks, got %d", len(webhooks), len(vc.Webhooks))
+	}
+
+	if vc.Webhooks[0].ClientConfig.Service.Path == nil {
+		t.Errorf("expected webhook service path to be restored")
+	}
+
+	if string(vc.Webhooks[0].ClientConfig.CABundle) != string(caBundle) {
+		t.Errorf("expected CA bundle to be preserved, got %q", vc.Webhooks[0].ClientConfig.CABundle)
+	}
+
+	if vc.Annotations[InjectCABundleAnnotationName] != "true" {
+		t.Errorf("expected %s annotation to be restored", InjectCABundleAnnotationName)
+	}
+
+	if vc.Labels["other"] != "label" {
+		t.Errorf("expected unrelated labels to be preserved")
+	}
+}
@@ -56,6 +56,24 @@ func FetchClusterTLSProfile(ctx context.Context, clientConfig *rest.Config) (con
 	return profileSpec, []func(*tls.Config){tlsConfigFn}, shouldHonorTLSProfile, nil
 }
 
+// DefaultTLSProfile returns the default TLS profile and a TLS profile function,
+// for use when the cluster TLS profile is not available, e.g. outside of
+// OpenShift.
+func DefaultTLSProfile() (configv1.TLSProfileSpec, []func(*tls.Config), error) {
+	profileSpec, err := tlspkg.GetTLSProfileSpec(nil)
+	if err != nil {
+		return configv1.TLSProfileSpec{}, nil, fmt.Errorf("Unable to create TLS profile. Failed to resolve default TLS profile spec: %w", err)
+	}
+
+	tlsConfigFn, unsupported := tlspkg.NewTLSConfigFromProfile(profileSpec)
+	if len(unsupported) > 0 {
+		klog.Warningf("Ignoring unsupported ciphersuites from TLS profile: %v", unsupported)
+	}
+
+	klog.Infof("Using default TLS profile (min version: %s) for TLS", profileSpec.MinTLSVersion)
+	return profileSpec, []func(*tls.Config){tlsConfigFn}, nil
+}
+
 // SetupTLSProfileWatcher registers a controller with mgr to watch the APIServer object's TLS security profile for changes.
 // If the profile changes, the cancel function will be called so that the operator can gracefully shutdown and restart to
 // pick up the changes
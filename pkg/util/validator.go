@@ -16,7 +16,9 @@ limitations under the License.
 package util
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
@@ -41,6 +43,10 @@ const (
 type ValidatorResponse struct {
 	Warnings []string
 	Errors   utilerrors.Aggregate
+
+	// Reasons are short machine-readable reasons for the errors, used to
+	// label the validation failure metrics when a request is denied.
+	Reasons []string
 }
 
 // IsValid tests a ValidationResponse to determine if the response was free from errors.
@@ -48,6 +54,78 @@ func (vr ValidatorResponse) IsValid() bool {
 	return vr.Errors == nil || len(vr.Errors.Errors()) == 0
 }
 
+// MergeValidatorResponses combines the warnings and errors of the given
+// responses into a single response.
+func MergeValidatorResponses(responses ...ValidatorResponse) ValidatorResponse {
+	merged := ValidatorResponse{}
+	errs := []error{}
+
+	for _, vr := range responses {
+		merged.Warnings = append(merged.Warnings, vr.Warnings...)
+		merged.Reasons = append(merged.Reasons, vr.Reasons...)
+
+		if vr.Errors != nil {
+			errs = append(errs, vr.Errors.Errors()...)
+		}
+	}
+
+	merged.Errors = utilerrors.NewAggregate(errs)
+
+	return merged
+}
+
+// CrossResourceResponse returns a ValidatorResponse for problems found when
+// validating a resource against other live resources.  The problems are
+// returned as errors, together with the given reasons, in strict mode, and as
+// warnings otherwise.
+func CrossResourceResponse(strict bool, problems, reasons []string) ValidatorResponse {
+	if len(problems) == 0 {
+		return ValidatorResponse{}
+	}
+
+	if !strict {
+		return ValidatorResponse{Warnings: problems}
+	}
+
+	errs := make([]error, 0, len(problems))
+	for _, p := range problems {
+		errs = append(errs, errors.New(p))
+	}
+
+	return ValidatorResponse{Errors: utilerrors.NewAggregate(errs), Reasons: reasons}
+}
+
+// MinReplicasExceedMaxNodesTotal returns a message naming the given
+// MachineAutoscalers if the sum of their minimum replicas exceeds the given
+// maximum number of nodes.  Otherwise an empty string is returned.  The sum
+// is only a lower bound of the nodes counted against the maximum, which also
+// include control plane nodes and other nodes without a MachineAutoscaler.
+func MinReplicasExceedMaxNodesTotal(maxNodesTotal int32, minReplicas map[string]int32) string {
+	var total int32
+	names := make([]string, 0, len(minReplicas))
+
+	for name, min := range minReplicas {
+		total += min
+		names = append(names, name)
+	}
+
+	if total <= maxNodesTotal {
+		return ""
+	}
+
+	sort.Strings(names)
+
+	involved := make([]string, 0, len(names))
+	for _, name := range names {
+		if minReplicas[name] > 0 {
+			involved = append(involved, fmt.Sprintf("%s (%d)", name, minReplicas[name]))
+		}
+	}
+
+	return fmt.Sprintf("the sum of MachineAutoscaler minReplicas (%d) exceeds ResourceLimits.MaxNodesTotal (%d): %s",
+		total, maxNodesTotal, strings.Join(involved, ", "))
+}
+
 // IsValidGPUAcceleratorLabel tests whether the value passed is a
 // valid for the GPU accelerator label on nodes. If the value is
 // invalid, or empty, it returns a string with a relevant warning about the
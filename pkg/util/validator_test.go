@@ -103,3 +103,52 @@ func TestValidatorResponseIsValid(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeValidatorResponses(t *testing.T) {
+	merged := MergeValidatorResponses(
+		ValidatorResponse{Warnings: []string{"foo"}},
+		ValidatorResponse{Errors: utilerrors.NewAggregate([]error{fmt.Errorf("bar")})},
+		ValidatorResponse{Warnings: []string{"baz"}, Errors: utilerrors.NewAggregate([]error{fmt.Errorf("qux")})},
+	)
+
+	if len(merged.Warnings) != 2 {
+		t.Errorf("Expected 2 warnings, got %v", merged.Warnings)
+	}
+
+	if merged.IsValid() || len(merged.Errors.Errors()) != 2 {
+		t.Errorf("Expected 2 errors, got %v", merged.Errors)
+	}
+
+	if !MergeValidatorResponses(ValidatorResponse{}, ValidatorResponse{}).IsValid() {
+		t.Errorf("Expected empty responses to be valid")
+	}
+}
+
+func TestCrossResourceResponse(t *testing.T) {
+	problems := []string{"foo", "bar"}
+
+	if vr := CrossResourceResponse(false, problems, nil); !vr.IsValid() || len(vr.Warnings) != 2 {
+		t.Errorf("Expected 2 warnings and no errors, got %v", vr)
+	}
+
+	if vr := CrossResourceResponse(true, problems, nil); vr.IsValid() || len(vr.Warnings) != 0 {
+		t.Errorf("Expected errors and no warnings, got %v", vr)
+	}
+
+	if vr := CrossResourceResponse(true, nil, nil); !vr.IsValid() {
+		t.Errorf("Expected no problems to be valid, got %v", vr)
+	}
+}
+
+func TestMinReplicasExceedMaxNodesTotal(t *testing.T) {
+	minReplicas := map[string]int32{"b": 3, "a": 2, "c": 0}
+
+	if msg := MinReplicasExceedMaxNodesTotal(5, minReplicas); msg != "" {
+		t.Errorf("Expected no message, got %q", msg)
+	}
+
+	expected := "the sum of MachineAutoscaler minReplicas (5) exceeds ResourceLimits.MaxNodesTotal (4): a (2), b (3)"
+	if msg := MinReplicasExceedMaxNodesTotal(4, minReplicas); msg != expected {
+		t.Errorf("Expected %q, got %q", expected, msg)
+	}
+}